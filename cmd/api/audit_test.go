@@ -0,0 +1,162 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mule-ai/mule/internal/api"
+	"github.com/mule-ai/mule/internal/primitive"
+	"github.com/mule-ai/mule/internal/validation"
+	"github.com/mule-ai/mule/pkg/audit"
+)
+
+// MockAuditStore is an in-memory implementation of audit.Store
+type MockAuditStore struct {
+	mu      sync.Mutex
+	entries []*audit.Entry
+}
+
+func (m *MockAuditStore) Record(entry *audit.Entry) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	entry.ID = fmt.Sprintf("audit-%d", len(m.entries)+1)
+	entry.CreatedAt = time.Now()
+	m.entries = append(m.entries, entry)
+	return nil
+}
+
+func (m *MockAuditStore) List(opts audit.ListOptions) ([]*audit.Entry, int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var matched []*audit.Entry
+	// Newest first, matching the PostgreSQL store
+	for i := len(m.entries) - 1; i >= 0; i-- {
+		e := m.entries[i]
+		if opts.Actor != "" && e.Actor != opts.Actor {
+			continue
+		}
+		if opts.Action != "" && !strings.Contains(e.Action, opts.Action) {
+			continue
+		}
+		if opts.Target != "" && !strings.Contains(e.Target, opts.Target) {
+			continue
+		}
+		if opts.Outcome != "" && e.Outcome != opts.Outcome {
+			continue
+		}
+		matched = append(matched, e)
+	}
+
+	start := (opts.Page - 1) * opts.PageSize
+	if start > len(matched) {
+		start = len(matched)
+	}
+	end := start + opts.PageSize
+	if end > len(matched) {
+		end = len(matched)
+	}
+	return matched[start:end], len(matched), nil
+}
+
+type auditListResponse struct {
+	Entries    []*audit.Entry `json:"entries"`
+	TotalCount int            `json:"total_count"`
+	TotalPages int            `json:"total_pages"`
+}
+
+func TestAuditLog(t *testing.T) {
+	auditStore := &MockAuditStore{}
+	handler := &apiHandler{
+		store:      &MockPrimitiveStore{},
+		jobStore:   &MockJobStore{},
+		validator:  validation.NewValidator(),
+		auditStore: auditStore,
+	}
+
+	router := mux.NewRouter()
+	router.Use(api.AuditMiddleware(auditStore))
+	router.HandleFunc("/api/v1/workflows", handler.createWorkflowHandler).Methods("POST")
+	router.HandleFunc("/api/v1/workflows/{id}", handler.deleteWorkflowHandler).Methods("DELETE")
+	router.HandleFunc("/api/v1/audit", handler.listAuditLogHandler).Methods("GET")
+
+	query := func(t *testing.T, params string) auditListResponse {
+		req := httptest.NewRequest("GET", "/api/v1/audit"+params, nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var resp auditListResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		return resp
+	}
+
+	body, _ := json.Marshal(primitive.Workflow{Name: "audited-workflow"})
+	req := httptest.NewRequest("POST", "/api/v1/workflows", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(api.AuditActorHeader, "alice")
+	req.RemoteAddr = "10.0.0.1:5000"
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusCreated, w.Code)
+
+	req = httptest.NewRequest("DELETE", "/api/v1/workflows/workflow-1", nil)
+	req.Header.Set(api.AuditActorHeader, "bob")
+	req.RemoteAddr = "10.0.0.2:5000"
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusNoContent, w.Code)
+
+	t.Run("records create and delete", func(t *testing.T) {
+		resp := query(t, "")
+		require.Len(t, resp.Entries, 2)
+		assert.Equal(t, 2, resp.TotalCount)
+
+		assert.Equal(t, "10.0.0.2", resp.Entries[0].Actor)
+		assert.Equal(t, "bob", resp.Entries[0].ClaimedActor)
+		assert.Equal(t, "DELETE /api/v1/workflows/{id}", resp.Entries[0].Action)
+		assert.Equal(t, "/api/v1/workflows/workflow-1", resp.Entries[0].Target)
+		assert.Equal(t, audit.OutcomeSuccess, resp.Entries[0].Outcome)
+
+		assert.Equal(t, "10.0.0.1", resp.Entries[1].Actor)
+		assert.Equal(t, "alice", resp.Entries[1].ClaimedActor)
+		assert.Equal(t, "POST /api/v1/workflows", resp.Entries[1].Action)
+		assert.Equal(t, http.StatusCreated, resp.Entries[1].StatusCode)
+	})
+
+	t.Run("does not audit reads", func(t *testing.T) {
+		// Earlier GETs against the audit endpoint must not have been recorded
+		assert.Equal(t, 2, query(t, "").TotalCount)
+	})
+
+	t.Run("filters by actor", func(t *testing.T) {
+		resp := query(t, "?actor=10.0.0.1")
+		require.Len(t, resp.Entries, 1)
+		assert.Equal(t, "POST /api/v1/workflows", resp.Entries[0].Action)
+	})
+
+	t.Run("paginates", func(t *testing.T) {
+		resp := query(t, "?page=2&page_size=1")
+		require.Len(t, resp.Entries, 1)
+		assert.Equal(t, "10.0.0.1", resp.Entries[0].Actor)
+		assert.Equal(t, 2, resp.TotalPages)
+	})
+
+	t.Run("rejects invalid time filter", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/api/v1/audit?since=yesterday", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
@@ -364,6 +364,11 @@ func (m *MockJobStore) ListJobs(opts job.ListJobsOptions) ([]*job.Job, int, erro
 			continue
 		}
 
+		// Apply tag filter if provided
+		if !j.MatchesTags(opts.Tags) {
+			continue
+		}
+
 		// Apply search filter if provided
 		if opts.Search != "" {
 			// Simple search in workflow_id and working_directory
@@ -3,6 +3,7 @@ package main
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log"
@@ -21,6 +22,7 @@ import (
 	"github.com/mule-ai/mule/internal/manager"
 	"github.com/mule-ai/mule/internal/primitive"
 	"github.com/mule-ai/mule/internal/validation"
+	"github.com/mule-ai/mule/pkg/audit"
 	dbmodels "github.com/mule-ai/mule/pkg/database"
 	"github.com/mule-ai/mule/pkg/job"
 )
@@ -36,6 +38,7 @@ type apiHandler struct {
 	workflowEngine *engine.Engine
 	workflowMgr    *manager.WorkflowManager
 	skillMgr       *manager.SkillManager
+	auditStore     audit.Store
 }
 
 func NewAPIHandler(db *internaldb.DB) *apiHandler {
@@ -82,6 +85,7 @@ func NewAPIHandler(db *internaldb.DB) *apiHandler {
 		workflowEngine: workflowEngine,
 		workflowMgr:    workflowMgr,
 		skillMgr:       skillMgr,
+		auditStore:     audit.NewPGStore(db.DB),
 	}
 }
 
@@ -133,17 +137,61 @@ func (h *apiHandler) modelsHandler(w http.ResponseWriter, r *http.Request) {
 	_ = json.NewEncoder(w).Encode(resp)
 }
 
+// workflowTimeout returns the timeout_workflow_seconds setting, defaulting to
+// five minutes
+func (h *apiHandler) workflowTimeout(ctx context.Context) time.Duration {
+	workflowTimeout := 5 * time.Minute // Default timeout
+	if setting, err := h.store.GetSetting(ctx, "timeout_workflow_seconds"); err == nil {
+		if timeoutSeconds, err := strconv.Atoi(setting.Value); err == nil && timeoutSeconds > 0 {
+			workflowTimeout = time.Duration(timeoutSeconds) * time.Second
+		}
+	}
+	return workflowTimeout
+}
+
+// executeWorkflowAcrossRepos runs a workflow request once per repository in
+// req.Repositories and responds with the per-repository results once every
+// run has finished or the workflow timeout has passed. Runs still going at the
+// timeout are cancelled. For async/workflow/ models it responds with the job
+// IDs as soon as the jobs are queued.
+func (h *apiHandler) executeWorkflowAcrossRepos(ctx context.Context, w http.ResponseWriter, req *agent.ChatCompletionRequest) {
+	if !strings.HasPrefix(req.Model, "workflow/") && !strings.HasPrefix(req.Model, "async/workflow/") {
+		api.HandleError(w, fmt.Errorf("repositories is only supported for workflow models"), http.StatusBadRequest)
+		return
+	}
+	if req.MaxConcurrency < 0 {
+		api.HandleError(w, fmt.Errorf("max_concurrency must not be negative"), http.StatusBadRequest)
+		return
+	}
+
+	resp := &agent.RepoWorkflowResponse{
+		Object: "workflow.repositories",
+		Model:  req.Model,
+	}
+	if strings.HasPrefix(req.Model, "async/workflow/") {
+		resp.Results = h.runtime.SubmitWorkflowAcrossRepos(ctx, req, req.Repositories)
+	} else {
+		ctx, cancel := context.WithTimeout(ctx, h.workflowTimeout(ctx))
+		defer cancel()
+		resp.Results = h.runtime.ExecuteWorkflowAcrossRepos(ctx, req, req.Repositories, req.MaxConcurrency)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(resp)
+}
+
 // chatCompletionsHandler handles OpenAI-compatible chat completions API requests.
 // Supports agent execution (model starting with "agent/") and workflow execution
 // (model starting with "workflow/" or "async/workflow/").
 //
 // Request body: ChatCompletionRequest with model and messages
+// Query parameters: verbose (optional, "true" adds per-step duration and token usage)
 // Response: ChatCompletionResponse for sync execution, AsyncJobResponse for async
 // Error responses: 400 Bad Request for invalid input, 404 Not Found for unknown workflows,
 //
 //	500 Internal Server Error for execution failures
 func (h *apiHandler) chatCompletionsHandler(w http.ResponseWriter, r *http.Request) {
-	ctx := r.Context()
+	ctx := job.WithTrigger(r.Context(), job.TriggerAPI)
 
 	// Parse request
 	var req agent.ChatCompletionRequest
@@ -163,15 +211,34 @@ func (h *apiHandler) chatCompletionsHandler(w http.ResponseWriter, r *http.Reque
 		return
 	}
 
+	verbose, _ := strconv.ParseBool(r.URL.Query().Get("verbose"))
+
+	if len(req.Repositories) > 0 {
+		h.executeWorkflowAcrossRepos(ctx, w, &req)
+		return
+	}
+
 	// Determine if this is an agent or workflow execution
 	if strings.HasPrefix(req.Model, "agent/") {
 		// Execute agent
+		startedAt := time.Now()
 		resp, err := h.runtime.ExecuteAgentWithWorkingDir(ctx, &req, req.WorkingDirectory)
 		if err != nil {
 			api.HandleError(w, fmt.Errorf("failed to execute agent: %w", err), http.StatusInternalServerError)
 			return
 		}
 
+		if verbose {
+			usage := resp.Usage
+			resp.Steps = []agent.StepMetrics{{
+				StepOrder:  1,
+				Status:     string(job.StatusCompleted),
+				DurationMs: time.Since(startedAt).Milliseconds(),
+				Usage:      &usage,
+			}}
+			resp.DurationMs = agent.TotalDurationMs(resp.Steps)
+		}
+
 		w.Header().Set("Content-Type", "application/json")
 		_ = json.NewEncoder(w).Encode(resp)
 	} else if strings.HasPrefix(req.Model, "async/workflow/") {
@@ -244,16 +311,8 @@ func (h *apiHandler) chatCompletionsHandler(w http.ResponseWriter, r *http.Reque
 			return
 		}
 
-		// Get workflow timeout from database
-		workflowTimeout := 5 * time.Minute // Default timeout
-		if setting, err := h.store.GetSetting(r.Context(), "timeout_workflow_seconds"); err == nil {
-			if timeoutSeconds, err := strconv.Atoi(setting.Value); err == nil && timeoutSeconds > 0 {
-				workflowTimeout = time.Duration(timeoutSeconds) * time.Second
-			}
-		}
-
 		// Wait for job completion with timeout
-		ctx, cancel := context.WithTimeout(ctx, workflowTimeout)
+		ctx, cancel := context.WithTimeout(ctx, h.workflowTimeout(r.Context()))
 		defer cancel()
 
 		for {
@@ -278,25 +337,7 @@ func (h *apiHandler) chatCompletionsHandler(w http.ResponseWriter, r *http.Reque
 					}
 
 					// Extract usage if available
-					usage := agent.ChatCompletionUsage{
-						PromptTokens:     0,
-						CompletionTokens: 0,
-						TotalTokens:      0,
-					}
-
-					if usageData, exists := updatedJob.OutputData["usage"]; exists {
-						if usageMap, ok := usageData.(map[string]interface{}); ok {
-							if promptTokens, ok := usageMap["prompt_tokens"].(float64); ok {
-								usage.PromptTokens = int(promptTokens)
-							}
-							if completionTokens, ok := usageMap["completion_tokens"].(float64); ok {
-								usage.CompletionTokens = int(completionTokens)
-							}
-							if totalTokens, ok := usageMap["total_tokens"].(float64); ok {
-								usage.TotalTokens = int(totalTokens)
-							}
-						}
-					}
+					usage, _ := agent.UsageFromMap(updatedJob.OutputData["usage"])
 
 					// Return OpenAI-compatible completion response
 					resp := &agent.ChatCompletionResponse{
@@ -317,6 +358,16 @@ func (h *apiHandler) chatCompletionsHandler(w http.ResponseWriter, r *http.Reque
 						Usage: usage,
 					}
 
+					if verbose {
+						jobSteps, err := h.jobStore.ListJobSteps(updatedJob.ID)
+						if err != nil {
+							api.HandleError(w, fmt.Errorf("failed to get job steps: %w", err), http.StatusInternalServerError)
+							return
+						}
+						resp.Steps = stepMetrics(jobSteps)
+						resp.DurationMs = agent.TotalDurationMs(resp.Steps)
+					}
+
 					w.Header().Set("Content-Type", "application/json")
 					_ = json.NewEncoder(w).Encode(resp)
 					return
@@ -340,6 +391,26 @@ func (h *apiHandler) chatCompletionsHandler(w http.ResponseWriter, r *http.Reque
 	}
 }
 
+// stepMetrics builds the per-step duration and token usage reported in verbose responses
+func stepMetrics(jobSteps []*job.JobStep) []agent.StepMetrics {
+	metrics := make([]agent.StepMetrics, 0, len(jobSteps))
+	for _, jobStep := range jobSteps {
+		m := agent.StepMetrics{
+			StepOrder:      jobStep.StepOrder,
+			WorkflowStepID: jobStep.WorkflowStepID,
+			Status:         string(jobStep.Status),
+		}
+		if jobStep.StartedAt != nil && jobStep.CompletedAt != nil {
+			m.DurationMs = jobStep.CompletedAt.Sub(*jobStep.StartedAt).Milliseconds()
+		}
+		if usage, ok := agent.UsageFromMap(jobStep.OutputData["usage"]); ok {
+			m.Usage = &usage
+		}
+		metrics = append(metrics, m)
+	}
+	return metrics
+}
+
 // Provider handlers
 
 // listProvidersHandler returns all configured AI providers.
@@ -1152,7 +1223,7 @@ func (h *apiHandler) listWorkflowsHandler(w http.ResponseWriter, r *http.Request
 
 // createWorkflowHandler creates a new workflow.
 // POST /api/v1/workflows
-// Request body: Workflow object with name, description, is_async flag
+// Request body: Workflow object with name, description, is_async flag and optional config
 // Response: Created Workflow object with generated ID
 func (h *apiHandler) createWorkflowHandler(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
@@ -1473,6 +1544,14 @@ func (h *apiHandler) listJobsHandler(w http.ResponseWriter, r *http.Request) {
 	searchStr := r.URL.Query().Get("search")
 	workflowNameStr := r.URL.Query().Get("workflow_name")
 
+	// Tag filters: exact matches on the workflow, repository and trigger tags
+	tags := make(map[string]string)
+	for _, key := range []string{job.TagWorkflow, job.TagRepository, job.TagTrigger} {
+		if value := r.URL.Query().Get(key); value != "" {
+			tags[key] = value
+		}
+	}
+
 	// Parse page
 	page := 1
 	if pageStr != "" {
@@ -1503,6 +1582,7 @@ func (h *apiHandler) listJobsHandler(w http.ResponseWriter, r *http.Request) {
 		Status:       status,
 		Search:       searchStr,
 		WorkflowName: workflowNameStr,
+		Tags:         tags,
 	}
 
 	jobs, totalCount, err := h.jobStore.ListJobs(opts)
@@ -1560,7 +1640,9 @@ func (h *apiHandler) listJobsHandler(w http.ResponseWriter, r *http.Request) {
 
 // createJobHandler creates a new job for workflow or WASM execution.
 // POST /api/v1/jobs
-// Request body: {workflow_id, input_data, working_directory?}
+// Request body: {workflow_id, input_data, working_directory?, tags?}
+// Tags may include repository and trigger (webhook, schedule, manual or api,
+// default api); the workflow tag is always set from the workflow or module name.
 // Response: Job object with status "queued" for workflows or "running" for direct WASM execution
 // Error responses: 400 Bad Request for invalid input or unknown workflow/WASM module IDs,
 //
@@ -1570,6 +1652,7 @@ func (h *apiHandler) createJobHandler(w http.ResponseWriter, r *http.Request) {
 		WorkflowID       string                 `json:"workflow_id"`
 		InputData        map[string]interface{} `json:"input_data"`
 		WorkingDirectory string                 `json:"working_directory,omitempty"`
+		Tags             map[string]string      `json:"tags,omitempty"`
 	}
 
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
@@ -1583,6 +1666,18 @@ func (h *apiHandler) createJobHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Validate the trigger source, defaulting to api
+	tags := make(map[string]string, len(req.Tags)+2)
+	for k, v := range req.Tags {
+		tags[k] = v
+	}
+	if tags[job.TagTrigger] == "" {
+		tags[job.TagTrigger] = job.TriggerAPI
+	} else if !job.IsValidTrigger(tags[job.TagTrigger]) {
+		api.HandleError(w, fmt.Errorf("invalid trigger: %s", tags[job.TagTrigger]), http.StatusBadRequest)
+		return
+	}
+
 	ctx := r.Context()
 
 	// Check if this is a WASM module execution (workflow_id is a WASM module ID)
@@ -1593,12 +1688,14 @@ func (h *apiHandler) createJobHandler(w http.ResponseWriter, r *http.Request) {
 
 	if err == nil && workflow != nil {
 		// This is a valid workflow ID, create a queued job for workflow execution
+		tags[job.TagWorkflow] = workflow.Name
 		newJob = &job.Job{
 			ID:               uuid.New().String(),
 			WorkflowID:       req.WorkflowID,
 			Status:           job.StatusQueued,
 			InputData:        req.InputData,
 			WorkingDirectory: req.WorkingDirectory,
+			Tags:             tags,
 			CreatedAt:        time.Now(),
 		}
 
@@ -1608,13 +1705,14 @@ func (h *apiHandler) createJobHandler(w http.ResponseWriter, r *http.Request) {
 		}
 	} else {
 		// Not a valid workflow ID, check if it's a WASM module ID
-		_, err := h.wasmModuleMgr.GetWasmModule(ctx, req.WorkflowID)
+		wasmModule, err := h.wasmModuleMgr.GetWasmModule(ctx, req.WorkflowID)
 		if err != nil {
 			api.HandleError(w, fmt.Errorf("invalid workflow_id or wasm_module_id: %s", req.WorkflowID), http.StatusBadRequest)
 			return
 		}
 
 		// This is a WASM module, execute it directly
+		tags[job.TagWorkflow] = wasmModule.Name
 		wasmModuleID := req.WorkflowID // The frontend sends WASM module ID in workflow_id field
 		newJob = &job.Job{
 			ID:               uuid.New().String(),
@@ -1623,6 +1721,7 @@ func (h *apiHandler) createJobHandler(w http.ResponseWriter, r *http.Request) {
 			Status:           job.StatusRunning, // Start as running since we're executing immediately
 			InputData:        req.InputData,
 			WorkingDirectory: req.WorkingDirectory,
+			Tags:             tags,
 			CreatedAt:        time.Now(),
 		}
 
@@ -1770,15 +1869,31 @@ func (h *apiHandler) listJobStepsHandler(w http.ResponseWriter, r *http.Request)
 	_ = json.NewEncoder(w).Encode(enrichedSteps)
 }
 
-// cancelJobHandler attempts to cancel a running or queued job.
+// cancelJobHandler attempts to cancel a running or queued job. A running job is
+// stopped during its current step, including in-flight model and HTTP calls.
 // DELETE /api/v1/jobs/{id}
+// POST /api/v1/jobs/{id}/cancel
 // Response: Object with message and job id on success
-// Error responses: 404 Not Found if job does not exist or cannot be cancelled, 500 Internal Server Error for cancellation failures
+// Error responses: 404 Not Found if job does not exist or cannot be cancelled, 409 Conflict if the job has already finished,
+//
+//	500 Internal Server Error for cancellation failures
 func (h *apiHandler) cancelJobHandler(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	jobID := vars["id"]
 
-	if err := h.jobStore.CancelJob(jobID); err != nil {
+	if h.workflowEngine != nil {
+		if err := h.workflowEngine.CancelJob(jobID); err != nil {
+			switch {
+			case errors.Is(err, job.ErrJobNotFound):
+				api.HandleError(w, fmt.Errorf("job not found: %s", jobID), http.StatusNotFound)
+			case errors.Is(err, engine.ErrJobFinished):
+				api.HandleError(w, fmt.Errorf("job %s has already finished", jobID), http.StatusConflict)
+			default:
+				api.HandleError(w, fmt.Errorf("failed to cancel job: %w", err), http.StatusInternalServerError)
+			}
+			return
+		}
+	} else if err := h.jobStore.CancelJob(jobID); err != nil {
 		if err.Error() == "job not found or cannot be cancelled" {
 			api.HandleError(w, fmt.Errorf("job not found or cannot be cancelled: %s", jobID), http.StatusNotFound)
 		} else {
@@ -1797,6 +1912,73 @@ func (h *apiHandler) cancelJobHandler(w http.ResponseWriter, r *http.Request) {
 	_ = json.NewEncoder(w).Encode(response)
 }
 
+// listAuditLogHandler returns audit log entries for mutating operations, newest first.
+// GET /api/v1/audit
+// Query parameters: page, page_size, actor, action, target, outcome, since, until (RFC3339)
+// Response: Object with entries array and pagination info
+// Error responses: 400 Bad Request for invalid time filters, 500 Internal Server Error if the query fails
+func (h *apiHandler) listAuditLogHandler(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	page := 1
+	if p, err := strconv.Atoi(query.Get("page")); err == nil && p > 0 {
+		page = p
+	}
+
+	pageSize := 20
+	if ps, err := strconv.Atoi(query.Get("page_size")); err == nil && ps > 0 && ps <= 100 {
+		pageSize = ps
+	}
+
+	opts := audit.ListOptions{
+		Page:     page,
+		PageSize: pageSize,
+		Actor:    query.Get("actor"),
+		Action:   query.Get("action"),
+		Target:   query.Get("target"),
+		Outcome:  query.Get("outcome"),
+	}
+
+	for param, dest := range map[string]**time.Time{"since": &opts.Since, "until": &opts.Until} {
+		value := query.Get(param)
+		if value == "" {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			api.HandleError(w, fmt.Errorf("invalid %s: must be RFC3339", param), http.StatusBadRequest)
+			return
+		}
+		*dest = &t
+	}
+
+	entries, totalCount, err := h.auditStore.List(opts)
+	if err != nil {
+		api.HandleError(w, fmt.Errorf("failed to list audit log: %w", err), http.StatusInternalServerError)
+		return
+	}
+	if entries == nil {
+		entries = []*audit.Entry{}
+	}
+
+	response := struct {
+		Entries    []*audit.Entry `json:"entries"`
+		Page       int            `json:"page"`
+		PageSize   int            `json:"page_size"`
+		TotalCount int            `json:"total_count"`
+		TotalPages int            `json:"total_pages"`
+	}{
+		Entries:    entries,
+		Page:       page,
+		PageSize:   pageSize,
+		TotalCount: totalCount,
+		TotalPages: (totalCount + pageSize - 1) / pageSize,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(response)
+}
+
 // WASM Module handlers
 
 // listWasmModulesHandler returns all uploaded WASM modules.
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"net/http"
+	"time"
+)
+
+// readinessTimeout bounds the checks made by the readiness endpoint
+const readinessTimeout = 2 * time.Second
+
+// healthzHandler reports that the process is up. It does not check any
+// dependencies, so orchestrators can use it as a liveness probe.
+func (h *apiHandler) healthzHandler(w http.ResponseWriter, r *http.Request) {
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write([]byte("OK"))
+}
+
+// readyzHandler reports whether the server can serve requests: the database
+// must answer a ping and the workflow engine must be running. It responds
+// with 503 and the status of each component when it is not ready.
+func (h *apiHandler) readyzHandler(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), readinessTimeout)
+	defer cancel()
+
+	ready := true
+	components := map[string]string{}
+
+	if h.db == nil || h.db.DB == nil {
+		components["database"] = "not configured"
+		ready = false
+	} else if err := h.db.PingContext(ctx); err != nil {
+		components["database"] = "error: " + err.Error()
+		ready = false
+	} else {
+		components["database"] = "ok"
+	}
+
+	if h.workflowEngine != nil && h.workflowEngine.Running() {
+		components["workflow_engine"] = "running"
+	} else {
+		components["workflow_engine"] = "stopped"
+		ready = false
+	}
+
+	status := "ready"
+	code := http.StatusOK
+	if !ready {
+		status = "not_ready"
+		code = http.StatusServiceUnavailable
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	if err := json.NewEncoder(w).Encode(map[string]interface{}{
+		"status":     status,
+		"components": components,
+	}); err != nil {
+		log.Printf("Failed to encode readiness status: %v", err)
+	}
+}
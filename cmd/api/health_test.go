@@ -0,0 +1,81 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	internaldb "github.com/mule-ai/mule/internal/database"
+	"github.com/mule-ai/mule/internal/engine"
+)
+
+func TestHealthzHandler(t *testing.T) {
+	handler := &apiHandler{}
+	w := httptest.NewRecorder()
+	handler.healthzHandler(w, httptest.NewRequest("GET", "/healthz", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestReadyzHandler(t *testing.T) {
+	readyz := func(t *testing.T, handler *apiHandler) (int, map[string]interface{}) {
+		t.Helper()
+		w := httptest.NewRecorder()
+		handler.readyzHandler(w, httptest.NewRequest("GET", "/readyz", nil))
+		var body map[string]interface{}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+		return w.Code, body
+	}
+
+	newHandler := func(t *testing.T) (*apiHandler, sqlmock.Sqlmock) {
+		t.Helper()
+		db, mock, err := sqlmock.New(sqlmock.MonitorPingsOption(true))
+		require.NoError(t, err)
+		t.Cleanup(func() { _ = db.Close() })
+
+		mockStore := &MockPrimitiveStore{}
+		return &apiHandler{
+			db:             &internaldb.DB{DB: db},
+			workflowEngine: engine.NewEngine(mockStore, &MockJobStore{}, nil, nil, engine.Config{Workers: 1}),
+		}, mock
+	}
+
+	t.Run("ready", func(t *testing.T) {
+		handler, mock := newHandler(t)
+		mock.ExpectPing()
+		require.NoError(t, handler.workflowEngine.Start(context.Background()))
+		defer handler.workflowEngine.Stop()
+
+		code, body := readyz(t, handler)
+		assert.Equal(t, http.StatusOK, code)
+		assert.Equal(t, "ready", body["status"])
+		assert.Equal(t, map[string]interface{}{"database": "ok", "workflow_engine": "running"}, body["components"])
+	})
+
+	t.Run("engine not started", func(t *testing.T) {
+		handler, mock := newHandler(t)
+		mock.ExpectPing()
+
+		code, body := readyz(t, handler)
+		assert.Equal(t, http.StatusServiceUnavailable, code)
+		assert.Equal(t, "not_ready", body["status"])
+		assert.Equal(t, "stopped", body["components"].(map[string]interface{})["workflow_engine"])
+	})
+
+	t.Run("database unreachable", func(t *testing.T) {
+		handler, mock := newHandler(t)
+		mock.ExpectPing().WillReturnError(errors.New("connection refused"))
+		require.NoError(t, handler.workflowEngine.Start(context.Background()))
+		defer handler.workflowEngine.Stop()
+
+		code, body := readyz(t, handler)
+		assert.Equal(t, http.StatusServiceUnavailable, code)
+		assert.Equal(t, "error: connection refused", body["components"].(map[string]interface{})["database"])
+	})
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mule-ai/mule/internal/engine"
+	"github.com/mule-ai/mule/internal/validation"
+	"github.com/mule-ai/mule/pkg/job"
+)
+
+func TestCancelJobEndpoint(t *testing.T) {
+	mockStore := &MockPrimitiveStore{}
+	mockJobStore := &MockJobStore{
+		Jobs: map[string]*job.Job{
+			"job-queued":  {ID: "job-queued", Status: job.StatusQueued},
+			"job-running": {ID: "job-running", Status: job.StatusRunning},
+			"job-done":    {ID: "job-done", Status: job.StatusCompleted},
+		},
+	}
+	handler := &apiHandler{
+		store:          mockStore,
+		jobStore:       mockJobStore,
+		validator:      validation.NewValidator(),
+		workflowEngine: engine.NewEngine(mockStore, mockJobStore, nil, nil, engine.Config{Workers: 1}),
+	}
+
+	router := mux.NewRouter()
+	router.HandleFunc("/api/v1/jobs/{id}", handler.cancelJobHandler).Methods("DELETE")
+	router.HandleFunc("/api/v1/jobs/{id}/cancel", handler.cancelJobHandler).Methods("POST")
+
+	cancel := func(method, path string) int {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(method, path, nil))
+		return w.Code
+	}
+
+	t.Run("cancels a queued job", func(t *testing.T) {
+		assert.Equal(t, http.StatusOK, cancel("POST", "/api/v1/jobs/job-queued/cancel"))
+		assert.Equal(t, job.StatusCancelled, mockJobStore.Jobs["job-queued"].Status)
+	})
+
+	t.Run("cancels a running job with DELETE", func(t *testing.T) {
+		assert.Equal(t, http.StatusOK, cancel("DELETE", "/api/v1/jobs/job-running"))
+		assert.Equal(t, job.StatusCancelled, mockJobStore.Jobs["job-running"].Status)
+	})
+
+	t.Run("finished job", func(t *testing.T) {
+		assert.Equal(t, http.StatusConflict, cancel("POST", "/api/v1/jobs/job-done/cancel"))
+		assert.Equal(t, job.StatusCompleted, mockJobStore.Jobs["job-done"].Status)
+	})
+
+	t.Run("unknown job", func(t *testing.T) {
+		assert.Equal(t, http.StatusNotFound, cancel("POST", "/api/v1/jobs/missing/cancel"))
+	})
+}
@@ -0,0 +1,121 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mule-ai/mule/internal/primitive"
+	"github.com/mule-ai/mule/internal/validation"
+	"github.com/mule-ai/mule/pkg/job"
+)
+
+func TestJobTagsFiltering(t *testing.T) {
+	mockStore := &MockPrimitiveStore{
+		Workflows: []*primitive.Workflow{
+			{ID: "workflow-review", Name: "review"},
+			{ID: "workflow-triage", Name: "triage"},
+		},
+	}
+	mockJobStore := &MockJobStore{Jobs: make(map[string]*job.Job)}
+	handler := &apiHandler{
+		store:     mockStore,
+		jobStore:  mockJobStore,
+		validator: validation.NewValidator(),
+	}
+
+	router := mux.NewRouter()
+	router.HandleFunc("/api/v1/jobs", handler.listJobsHandler).Methods("GET")
+	router.HandleFunc("/api/v1/jobs", handler.createJobHandler).Methods("POST")
+
+	createJob := func(workflowID string, tags map[string]string) (*job.Job, int) {
+		body, _ := json.Marshal(map[string]interface{}{"workflow_id": workflowID, "tags": tags})
+		req := httptest.NewRequest("POST", "/api/v1/jobs", bytes.NewBuffer(body))
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		var response struct {
+			Data job.Job `json:"data"`
+		}
+		if w.Code == http.StatusCreated {
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		}
+		return &response.Data, w.Code
+	}
+
+	webhookRepoA, code := createJob("workflow-review", map[string]string{"repository": "org/a", "trigger": "webhook"})
+	require.Equal(t, http.StatusCreated, code)
+	webhookRepoB, code := createJob("workflow-review", map[string]string{"repository": "org/b", "trigger": "webhook"})
+	require.Equal(t, http.StatusCreated, code)
+	scheduleRepoA, code := createJob("workflow-triage", map[string]string{"repository": "org/a", "trigger": "schedule"})
+	require.Equal(t, http.StatusCreated, code)
+	apiJob, code := createJob("workflow-triage", nil)
+	require.Equal(t, http.StatusCreated, code)
+
+	assert.Equal(t, map[string]string{"repository": "org/a", "trigger": "webhook", "workflow": "review"}, webhookRepoA.Tags)
+	assert.Equal(t, map[string]string{"trigger": "api", "workflow": "triage"}, apiJob.Tags)
+
+	// Mark one webhook job as failed to combine tag and status filters
+	mockJobStore.Jobs[webhookRepoA.ID].Status = job.StatusFailed
+
+	listJobIDs := func(query string) []string {
+		req := httptest.NewRequest("GET", "/api/v1/jobs?"+query, nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var response struct {
+			Jobs []*job.EnhancedJob `json:"jobs"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+
+		ids := make([]string, 0, len(response.Jobs))
+		for _, j := range response.Jobs {
+			ids = append(ids, j.ID)
+		}
+		sort.Strings(ids)
+		return ids
+	}
+
+	ids := func(jobs ...*job.Job) []string {
+		out := make([]string, 0, len(jobs))
+		for _, j := range jobs {
+			out = append(out, j.ID)
+		}
+		sort.Strings(out)
+		return out
+	}
+
+	tests := []struct {
+		name  string
+		query string
+		want  []string
+	}{
+		{"trigger", "trigger=webhook", ids(webhookRepoA, webhookRepoB)},
+		{"repository", "repository=org/a", ids(webhookRepoA, scheduleRepoA)},
+		{"workflow", "workflow=triage", ids(scheduleRepoA, apiJob)},
+		{"repository and trigger", "repository=org/a&trigger=webhook", ids(webhookRepoA)},
+		{"tags and status", "trigger=webhook&status=failed", ids(webhookRepoA)},
+		{"default trigger", "trigger=api", ids(apiJob)},
+		{"no match", "repository=org/a&workflow=review&trigger=schedule", ids()},
+		{"no filter", "", ids(webhookRepoA, webhookRepoB, scheduleRepoA, apiJob)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, listJobIDs(tt.query))
+		})
+	}
+
+	t.Run("rejects unknown trigger", func(t *testing.T) {
+		_, code := createJob("workflow-review", map[string]string{"trigger": "carrier-pigeon"})
+		assert.Equal(t, http.StatusBadRequest, code)
+	})
+}
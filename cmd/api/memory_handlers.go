@@ -48,3 +48,20 @@ func (h *apiHandler) updateMemoryConfigHandler(w http.ResponseWriter, r *http.Re
 		log.Printf("Failed to encode memory config: %v", err)
 	}
 }
+
+// getMemoryStatusHandler reports whether the memory store is available. When it
+// is not, memory operations are skipped rather than failing agent runs.
+// GET /api/v1/memory-config/status
+// Response: {"available": bool, "error": string}
+func (h *apiHandler) getMemoryStatusHandler(w http.ResponseWriter, r *http.Request) {
+	status := map[string]interface{}{"available": true}
+	if err := h.runtime.MemoryHealth(); err != nil {
+		status["available"] = false
+		status["error"] = err.Error()
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(status); err != nil {
+		log.Printf("Failed to encode memory status: %v", err)
+	}
+}
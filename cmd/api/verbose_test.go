@@ -0,0 +1,138 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mule-ai/mule/internal/agent"
+	"github.com/mule-ai/mule/internal/primitive"
+	"github.com/mule-ai/mule/internal/validation"
+	"github.com/mule-ai/mule/pkg/job"
+)
+
+// stepJobStore extends MockJobStore with job step listing
+type stepJobStore struct {
+	*MockJobStore
+	steps []*job.JobStep
+}
+
+func (m *stepJobStore) ListJobSteps(jobID string) ([]*job.JobStep, error) {
+	var steps []*job.JobStep
+	for _, s := range m.steps {
+		if s.JobID == jobID {
+			steps = append(steps, s)
+		}
+	}
+	return steps, nil
+}
+
+// completedWorkflowEngine stands in for the engine and a provider: each
+// submitted job completes immediately with one step whose usage and timing
+// are recorded the way the engine stores them
+type completedWorkflowEngine struct {
+	jobStore *stepJobStore
+}
+
+func (e *completedWorkflowEngine) SubmitJob(ctx context.Context, workflowID string, inputData map[string]interface{}) (*job.Job, error) {
+	startedAt := time.Now().Add(-1500 * time.Millisecond)
+	completedAt := startedAt.Add(1200 * time.Millisecond)
+
+	// Values are float64 as they would be after a round trip through the database
+	usage := map[string]interface{}{"prompt_tokens": float64(12), "completion_tokens": float64(30), "total_tokens": float64(42)}
+
+	newJob := &job.Job{
+		ID:         "verbose-job",
+		WorkflowID: workflowID,
+		Status:     job.StatusCompleted,
+		InputData:  inputData,
+		OutputData: map[string]interface{}{"prompt": "stub answer", "usage": usage},
+		CreatedAt:  startedAt,
+	}
+	if err := e.jobStore.CreateJob(newJob); err != nil {
+		return nil, err
+	}
+
+	e.jobStore.steps = append(e.jobStore.steps, &job.JobStep{
+		ID:             "verbose-step",
+		JobID:          newJob.ID,
+		WorkflowStepID: "workflow-step-1",
+		StepOrder:      1,
+		Status:         job.StatusCompleted,
+		OutputData:     map[string]interface{}{"prompt": "stub answer", "usage": usage},
+		StartedAt:      &startedAt,
+		CompletedAt:    &completedAt,
+	})
+
+	return newJob, nil
+}
+
+func TestChatCompletionsVerbose(t *testing.T) {
+	mockStore := &MockPrimitiveStore{
+		Workflows: []*primitive.Workflow{{ID: "workflow-1", Name: "stub-workflow"}},
+	}
+	jobStore := &stepJobStore{MockJobStore: &MockJobStore{Jobs: make(map[string]*job.Job)}}
+
+	runtime := agent.NewRuntime(mockStore, jobStore)
+	runtime.SetWorkflowEngine(&completedWorkflowEngine{jobStore: jobStore})
+
+	handler := &apiHandler{
+		store:     mockStore,
+		runtime:   runtime,
+		jobStore:  jobStore,
+		validator: validation.NewValidator(),
+	}
+
+	router := mux.NewRouter()
+	router.HandleFunc("/v1/chat/completions", handler.chatCompletionsHandler).Methods("POST")
+
+	post := func(t *testing.T, url string) agent.ChatCompletionResponse {
+		body, _ := json.Marshal(map[string]interface{}{
+			"model":    "workflow/stub-workflow",
+			"messages": []map[string]string{{"role": "user", "content": "Hello"}},
+		})
+		req := httptest.NewRequest("POST", url, bytes.NewBuffer(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+
+		router.ServeHTTP(w, req)
+
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		var resp agent.ChatCompletionResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		return resp
+	}
+
+	t.Run("verbose includes step timing and usage", func(t *testing.T) {
+		resp := post(t, "/v1/chat/completions?verbose=true")
+
+		assert.Equal(t, "stub answer", resp.Choices[0].Message.Content)
+		assert.Equal(t, 42, resp.Usage.TotalTokens)
+
+		require.Len(t, resp.Steps, 1)
+		step := resp.Steps[0]
+		assert.Equal(t, 1, step.StepOrder)
+		assert.Equal(t, "workflow-step-1", step.WorkflowStepID)
+		assert.Equal(t, "completed", step.Status)
+		assert.Equal(t, int64(1200), step.DurationMs)
+		require.NotNil(t, step.Usage)
+		assert.Equal(t, agent.ChatCompletionUsage{PromptTokens: 12, CompletionTokens: 30, TotalTokens: 42}, *step.Usage)
+		assert.Equal(t, int64(1200), resp.DurationMs)
+	})
+
+	t.Run("steps omitted by default", func(t *testing.T) {
+		resp := post(t, "/v1/chat/completions")
+
+		assert.Equal(t, 42, resp.Usage.TotalTokens)
+		assert.Empty(t, resp.Steps)
+		assert.Zero(t, resp.DurationMs)
+	})
+}
@@ -0,0 +1,167 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mule-ai/mule/internal/engine"
+	"github.com/mule-ai/mule/internal/primitive"
+	"github.com/mule-ai/mule/internal/validation"
+)
+
+// wasmModuleStore extends MockPrimitiveStore with WASM module lookup
+type wasmModuleStore struct {
+	*MockPrimitiveStore
+	modules map[string]*primitive.WasmModule
+}
+
+func (m *wasmModuleStore) GetWasmModule(ctx context.Context, id string) (*primitive.WasmModule, error) {
+	if module, ok := m.modules[id]; ok {
+		return module, nil
+	}
+	return nil, primitive.ErrNotFound
+}
+
+// uleb128 encodes n as an unsigned LEB128 integer
+func uleb128(n int) []byte {
+	var out []byte
+	for {
+		b := byte(n & 0x7f)
+		n >>= 7
+		if n != 0 {
+			out = append(out, b|0x80)
+			continue
+		}
+		return append(out, b)
+	}
+}
+
+// wasmSection encodes a module section with its id and size
+func wasmSection(id byte, content ...byte) []byte {
+	return append(append([]byte{id}, uleb128(len(content))...), content...)
+}
+
+// wasmString encodes a length-prefixed name
+func wasmString(s string) []byte {
+	return append(uleb128(len(s)), s...)
+}
+
+// stdoutModule builds a minimal WASM module whose _start writes output to
+// stdout with WASI fd_write
+func stdoutModule(output string) []byte {
+	// Memory layout: iovec {buf: 16, len} at 0, nwritten at 8, output at 16
+	data := make([]byte, 16, 16+len(output))
+	data[0] = 16
+	n := len(output)
+	data[4], data[5], data[6], data[7] = byte(n), byte(n>>8), byte(n>>16), byte(n>>24)
+	data = append(data, output...)
+
+	var module []byte
+	module = append(module, 0x00, 0x61, 0x73, 0x6d, 0x01, 0x00, 0x00, 0x00)
+	// Types: (i32 i32 i32 i32) -> i32 for fd_write, () -> () for _start
+	module = append(module, wasmSection(1, 0x02, 0x60, 0x04, 0x7f, 0x7f, 0x7f, 0x7f, 0x01, 0x7f, 0x60, 0x00, 0x00)...)
+	imports := append([]byte{0x01}, wasmString("wasi_snapshot_preview1")...)
+	imports = append(imports, wasmString("fd_write")...)
+	imports = append(imports, 0x00, 0x00)
+	module = append(module, wasmSection(2, imports...)...)
+	module = append(module, wasmSection(3, 0x01, 0x01)...)
+	module = append(module, wasmSection(5, 0x01, 0x00, 0x01)...)
+	exports := append([]byte{0x02}, wasmString("memory")...)
+	exports = append(exports, 0x02, 0x00)
+	exports = append(exports, wasmString("_start")...)
+	exports = append(exports, 0x00, 0x01)
+	module = append(module, wasmSection(7, exports...)...)
+	// _start: drop(fd_write(1, 0, 1, 8))
+	body := []byte{0x00, 0x41, 0x01, 0x41, 0x00, 0x41, 0x01, 0x41, 0x08, 0x10, 0x00, 0x1a, 0x0b}
+	module = append(module, wasmSection(10, append([]byte{0x01}, append(uleb128(len(body)), body...)...)...)...)
+	segment := append([]byte{0x01, 0x00, 0x41, 0x00, 0x0b}, uleb128(len(data))...)
+	module = append(module, wasmSection(11, append(segment, data...)...)...)
+	return module
+}
+
+func TestWasmModuleExecutions(t *testing.T) {
+	store := &wasmModuleStore{
+		MockPrimitiveStore: &MockPrimitiveStore{},
+		modules: map[string]*primitive.WasmModule{
+			"module-1": {
+				ID:         "module-1",
+				Name:       "echo",
+				ModuleData: stdoutModule(`{"message":"used token ghp_supersecret","success":true}`),
+				Config:     map[string]interface{}{"api_token": "config-token"},
+			},
+		},
+	}
+
+	executor := engine.NewWASMExecutor(nil, store, nil, nil)
+	secrets := engine.NewSecretStore()
+	secrets.Set("GITHUB_TOKEN", "ghp_supersecret")
+	executor.SetSecretStore(secrets)
+
+	handler := &apiHandler{
+		store:        store,
+		wasmExecutor: executor,
+		validator:    validation.NewValidator(),
+	}
+
+	router := mux.NewRouter()
+	router.HandleFunc("/api/v1/wasm-modules/test", handler.testWasmModuleHandler).Methods("POST")
+	router.HandleFunc("/api/v1/wasm-modules/{id}/executions", handler.getWasmModuleExecutionsHandler).Methods("GET")
+
+	for _, prompt := range []string{"first run", "second run with ghp_supersecret"} {
+		body, _ := json.Marshal(map[string]interface{}{
+			"module_id": "module-1",
+			"input":     map[string]interface{}{"prompt": prompt},
+		})
+		req := httptest.NewRequest("POST", "/api/v1/wasm-modules/test", bytes.NewBuffer(body))
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	}
+
+	t.Run("returns recent executions newest first", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/api/v1/wasm-modules/module-1/executions", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var executions []engine.ExecutionRecord
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &executions))
+		require.Len(t, executions, 2)
+
+		latest := executions[0]
+		assert.Equal(t, "module-1", latest.ModuleID)
+		assert.Equal(t, "success", latest.Outcome)
+		assert.GreaterOrEqual(t, latest.DurationMs, int64(0))
+		assert.Contains(t, latest.Stdin, "second run with [REDACTED]")
+		assert.Contains(t, latest.Stdin, `"api_token":"[REDACTED]"`)
+		assert.NotContains(t, latest.Stdin, "config-token")
+		assert.Equal(t, `{"message":"used token [REDACTED]","success":true}`, latest.Stdout)
+		assert.Contains(t, executions[1].Stdin, "first run")
+	})
+
+	t.Run("limit restricts the number of executions", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/api/v1/wasm-modules/module-1/executions?limit=1", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var executions []engine.ExecutionRecord
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &executions))
+		assert.Len(t, executions, 1)
+	})
+
+	t.Run("invalid limit", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/api/v1/wasm-modules/module-1/executions?limit=abc", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
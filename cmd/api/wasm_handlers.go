@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"strconv"
 	"strings"
 	"time"
 
@@ -330,6 +331,32 @@ func (h *apiHandler) testWasmModuleHandler(w http.ResponseWriter, r *http.Reques
 	_ = json.NewEncoder(w).Encode(response)
 }
 
+// getWasmModuleExecutionsHandler returns the most recent executions of a WASM
+// module with their stdin, stdout, stderr, duration and outcome. Secret values
+// are redacted. History is kept in memory and cleared on restart.
+// GET /api/v1/wasm-modules/{id}/executions
+// Query parameters: limit (optional, defaults to all recorded executions)
+// Response: Array of ExecutionRecord objects, newest first
+// Error responses: 400 Bad Request for an invalid limit
+func (h *apiHandler) getWasmModuleExecutionsHandler(w http.ResponseWriter, r *http.Request) {
+	moduleID := mux.Vars(r)["id"]
+
+	limit := 0
+	if limitStr := r.URL.Query().Get("limit"); limitStr != "" {
+		parsed, err := strconv.Atoi(limitStr)
+		if err != nil || parsed < 0 {
+			api.HandleError(w, fmt.Errorf("invalid limit: %s", limitStr), http.StatusBadRequest)
+			return
+		}
+		limit = parsed
+	}
+
+	executions := h.wasmExecutor.RecentExecutions(moduleID, limit)
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(executions)
+}
+
 func (h *apiHandler) getWasmModuleExampleHandler(w http.ResponseWriter, r *http.Request) {
 	language := r.URL.Query().Get("language")
 	if language == "" {
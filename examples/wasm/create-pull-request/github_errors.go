@@ -0,0 +1,90 @@
+//go:build wasm || ignore
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// GitHubFieldError is one entry of the errors array in a GitHub API error
+// response, such as a validation failure on a single field
+type GitHubFieldError struct {
+	Resource string `json:"resource"`
+	Field    string `json:"field"`
+	Code     string `json:"code"`
+	Message  string `json:"message"`
+}
+
+// UnmarshalJSON accepts both object entries and the plain strings GitHub
+// sometimes returns in the errors array
+func (e *GitHubFieldError) UnmarshalJSON(data []byte) error {
+	var message string
+	if err := json.Unmarshal(data, &message); err == nil {
+		e.Message = message
+		return nil
+	}
+	type fieldError GitHubFieldError
+	return json.Unmarshal(data, (*fieldError)(e))
+}
+
+func (e GitHubFieldError) String() string {
+	var parts []string
+	if e.Resource != "" && e.Field != "" {
+		parts = append(parts, e.Resource+"."+e.Field)
+	} else if e.Field != "" {
+		parts = append(parts, e.Field)
+	}
+	if e.Code != "" {
+		parts = append(parts, e.Code)
+	}
+	if e.Message != "" {
+		parts = append(parts, e.Message)
+	}
+	return strings.Join(parts, " ")
+}
+
+// GitHubError is an error response from the GitHub REST API
+type GitHubError struct {
+	StatusCode       int                `json:"-"`
+	Message          string             `json:"message"`
+	DocumentationURL string             `json:"documentation_url"`
+	Errors           []GitHubFieldError `json:"errors"`
+}
+
+// parseGitHubError builds a GitHubError from a response status and body. A
+// body that is not a GitHub error document leaves only StatusCode set.
+func parseGitHubError(statusCode int, body []byte) *GitHubError {
+	var apiErr GitHubError
+	if err := json.Unmarshal(body, &apiErr); err != nil {
+		apiErr = GitHubError{}
+	}
+	apiErr.StatusCode = statusCode
+	return &apiErr
+}
+
+// IsRateLimit reports whether the error is GitHub's rate limit response
+func (e *GitHubError) IsRateLimit() bool {
+	return (e.StatusCode == 403 || e.StatusCode == 429) &&
+		strings.Contains(strings.ToLower(e.Message), "rate limit")
+}
+
+func (e *GitHubError) Error() string {
+	if e.Message == "" {
+		return fmt.Sprintf("GitHub API request failed with status: %d", e.StatusCode)
+	}
+
+	msg := fmt.Sprintf("GitHub API error: %s (status: %d)", e.Message, e.StatusCode)
+	if len(e.Errors) > 0 {
+		details := make([]string, len(e.Errors))
+		for i, fieldErr := range e.Errors {
+			details[i] = fieldErr.String()
+		}
+		msg += ", details: " + strings.Join(details, "; ")
+	}
+	if e.DocumentationURL != "" {
+		msg += ", see " + e.DocumentationURL
+	}
+	return msg
+}
@@ -0,0 +1,71 @@
+//go:build wasm || ignore
+
+package main
+
+import "testing"
+
+// Run with: go test github_errors.go github_errors_test.go
+func TestParseGitHubErrorValidation(t *testing.T) {
+	body := []byte(`{
+		"message": "Validation Failed",
+		"errors": [
+			{"resource": "PullRequest", "field": "head", "code": "missing_field"},
+			"body is too long"
+		],
+		"documentation_url": "https://docs.github.com/rest/pulls/pulls#create-a-pull-request"
+	}`)
+
+	err := parseGitHubError(422, body)
+
+	if err.StatusCode != 422 || err.Message != "Validation Failed" {
+		t.Fatalf("unexpected status/message: %d %q", err.StatusCode, err.Message)
+	}
+	if err.DocumentationURL != "https://docs.github.com/rest/pulls/pulls#create-a-pull-request" {
+		t.Errorf("DocumentationURL = %q", err.DocumentationURL)
+	}
+	if len(err.Errors) != 2 {
+		t.Fatalf("got %d field errors, want 2", len(err.Errors))
+	}
+	want := GitHubFieldError{Resource: "PullRequest", Field: "head", Code: "missing_field"}
+	if err.Errors[0] != want {
+		t.Errorf("Errors[0] = %+v, want %+v", err.Errors[0], want)
+	}
+	if err.Errors[1].Message != "body is too long" {
+		t.Errorf("Errors[1].Message = %q", err.Errors[1].Message)
+	}
+	if err.IsRateLimit() {
+		t.Error("validation error reported as rate limit")
+	}
+
+	wantMsg := "GitHub API error: Validation Failed (status: 422), details: PullRequest.head missing_field; body is too long, see https://docs.github.com/rest/pulls/pulls#create-a-pull-request"
+	if err.Error() != wantMsg {
+		t.Errorf("Error() = %q, want %q", err.Error(), wantMsg)
+	}
+}
+
+func TestParseGitHubErrorRateLimit(t *testing.T) {
+	body := []byte(`{"message": "API rate limit exceeded for user ID 1.", "documentation_url": "https://docs.github.com/rest/overview/rate-limits-for-the-rest-api"}`)
+
+	err := parseGitHubError(403, body)
+
+	if err.Message != "API rate limit exceeded for user ID 1." {
+		t.Errorf("Message = %q", err.Message)
+	}
+	if err.DocumentationURL != "https://docs.github.com/rest/overview/rate-limits-for-the-rest-api" {
+		t.Errorf("DocumentationURL = %q", err.DocumentationURL)
+	}
+	if len(err.Errors) != 0 {
+		t.Errorf("unexpected field errors: %+v", err.Errors)
+	}
+	if !err.IsRateLimit() {
+		t.Error("rate limit error not detected")
+	}
+}
+
+func TestParseGitHubErrorNonJSON(t *testing.T) {
+	err := parseGitHubError(502, []byte("<html>Bad Gateway</html>"))
+
+	if err.Error() != "GitHub API request failed with status: 502" {
+		t.Errorf("Error() = %q", err.Error())
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
+	"strings"
 	"unsafe"
 )
 
@@ -24,6 +25,9 @@ type Input struct {
 	Prompt PullRequestInput `json:"prompt"`
 	Body   string           `json:"body,omitempty"`  // Pull request description (optional)
 	Draft  bool             `json:"draft,omitempty"` // Whether to create as draft (optional)
+	// GitHub REST API base URL (optional; defaults to https://api.github.com/,
+	// GitHub Enterprise Server uses https://HOST/api/v3/)
+	APIBaseURL string `json:"api_base_url,omitempty"`
 }
 
 type PullRequestInput struct {
@@ -106,6 +110,19 @@ func getCurrentBranchName() (string, error) {
 	return string(buffer[:requiredSize]), nil
 }
 
+// githubAPIBase returns the GitHub REST API base URL with a trailing slash,
+// defaulting to github.com
+func githubAPIBase(apiBase string) string {
+	apiBase = strings.TrimSpace(apiBase)
+	if apiBase == "" {
+		return "https://api.github.com/"
+	}
+	if !strings.HasSuffix(apiBase, "/") {
+		apiBase += "/"
+	}
+	return apiBase
+}
+
 func main() {
 	// Read input from stdin
 	var input Input
@@ -207,7 +224,7 @@ func main() {
 	// Prepare HTTP request parameters
 	method := "POST"
 	methodPtr, methodSize := stringToPtr(method)
-	url := fmt.Sprintf("https://api.github.com/repos/%s/%s/pulls", input.Owner, input.Repo)
+	url := fmt.Sprintf("%srepos/%s/%s/pulls", githubAPIBase(input.APIBaseURL), input.Owner, input.Repo)
 	urlPtr, urlSize := stringToPtr(url)
 
 	// Make HTTP request using the enhanced host function
@@ -236,7 +253,6 @@ func main() {
 
 	bodySizeRet := get_last_response_body(bufferPtr, bufferSize)
 
-	var responseMessage string
 	var responseURL string
 
 	if bodySizeRet > 0 && bodySizeRet <= uint32(len(buffer)) {
@@ -249,9 +265,6 @@ func main() {
 			if url, ok := responseMap["html_url"].(string); ok {
 				responseURL = url
 			}
-			if message, ok := responseMap["message"].(string); ok {
-				responseMessage = message
-			}
 		}
 	}
 
@@ -272,27 +285,11 @@ func main() {
 		}
 	} else {
 		// Error
-		var errorMessage string
-		if responseMessage != "" {
-			errorMessage = fmt.Sprintf("GitHub API error: %s (status: %d)", responseMessage, statusCode)
-		} else {
-			errorMessage = fmt.Sprintf("GitHub API request failed with status: %d", statusCode)
-		}
-
-		// Try to parse detailed error response
+		var responseBody []byte
 		if bodySizeRet > 0 && bodySizeRet <= uint32(len(buffer)) {
-			responseBody := string(buffer[:bodySizeRet])
-			var errorDetails map[string]interface{}
-			if err := json.Unmarshal([]byte(responseBody), &errorDetails); err == nil {
-				if details, ok := errorDetails["errors"]; ok {
-					if detailsBytes, err := json.Marshal(details); err == nil {
-						errorMessage += fmt.Sprintf(", details: %s", string(detailsBytes))
-					}
-				}
-			}
+			responseBody = buffer[:bodySizeRet]
 		}
-
-		outputError(fmt.Errorf(errorMessage))
+		outputError(parseGitHubError(statusCode, responseBody))
 		return
 	}
 }
@@ -0,0 +1,140 @@
+//go:build ignore
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// defaultGitHubAPIBase is the REST API base URL for github.com
+const defaultGitHubAPIBase = "https://api.github.com/"
+
+// normalizeAPIBase returns the GitHub REST API base URL with a trailing slash,
+// defaulting to github.com. GitHub Enterprise Server hosts use a base such as
+// https://github.mycorp.com/api/v3/.
+func normalizeAPIBase(apiBase string) string {
+	apiBase = strings.TrimSpace(apiBase)
+	if apiBase == "" {
+		return defaultGitHubAPIBase
+	}
+	if !strings.HasSuffix(apiBase, "/") {
+		apiBase += "/"
+	}
+	return apiBase
+}
+
+// isValidGitHubAPIURL validates that the URL is an issue URL under the given
+// API base: {apiBase}repos/{owner}/{repo}/issues/{number}
+func isValidGitHubAPIURL(url, apiBase string) bool {
+	// Check if it starts with the repos endpoint of the API base URL
+	reposBase := normalizeAPIBase(apiBase) + "repos/"
+	if !strings.HasPrefix(url, reposBase) {
+		return false
+	}
+
+	// Check if it has the expected path structure
+	path := url[len(reposBase):]
+	parts := strings.Split(path, "/")
+
+	// Should have at least owner/repo/issues/number (4 parts)
+	if len(parts) < 4 {
+		return false
+	}
+
+	// Check if the second-to-last part is "issues"
+	if parts[len(parts)-2] != "issues" {
+		return false
+	}
+
+	// Check if the last part (issue number) is numeric
+	issueNumber := parts[len(parts)-1]
+	if _, err := strconv.Atoi(issueNumber); err != nil {
+		return false
+	}
+
+	return true
+}
+
+// GitHubFieldError is one entry of the errors array in a GitHub API error
+// response, such as a validation failure on a single field
+type GitHubFieldError struct {
+	Resource string `json:"resource"`
+	Field    string `json:"field"`
+	Code     string `json:"code"`
+	Message  string `json:"message"`
+}
+
+// UnmarshalJSON accepts both object entries and the plain strings GitHub
+// sometimes returns in the errors array
+func (e *GitHubFieldError) UnmarshalJSON(data []byte) error {
+	var message string
+	if err := json.Unmarshal(data, &message); err == nil {
+		e.Message = message
+		return nil
+	}
+	type fieldError GitHubFieldError
+	return json.Unmarshal(data, (*fieldError)(e))
+}
+
+func (e GitHubFieldError) String() string {
+	var parts []string
+	if e.Resource != "" && e.Field != "" {
+		parts = append(parts, e.Resource+"."+e.Field)
+	} else if e.Field != "" {
+		parts = append(parts, e.Field)
+	}
+	if e.Code != "" {
+		parts = append(parts, e.Code)
+	}
+	if e.Message != "" {
+		parts = append(parts, e.Message)
+	}
+	return strings.Join(parts, " ")
+}
+
+// GitHubError is an error response from the GitHub REST API
+type GitHubError struct {
+	StatusCode       int                `json:"-"`
+	Message          string             `json:"message"`
+	DocumentationURL string             `json:"documentation_url"`
+	Errors           []GitHubFieldError `json:"errors"`
+}
+
+// parseGitHubError builds a GitHubError from a response status and body. A
+// body that is not a GitHub error document leaves only StatusCode set.
+func parseGitHubError(statusCode int, body []byte) *GitHubError {
+	var apiErr GitHubError
+	if err := json.Unmarshal(body, &apiErr); err != nil {
+		apiErr = GitHubError{}
+	}
+	apiErr.StatusCode = statusCode
+	return &apiErr
+}
+
+// IsRateLimit reports whether the error is GitHub's rate limit response
+func (e *GitHubError) IsRateLimit() bool {
+	return (e.StatusCode == 403 || e.StatusCode == 429) &&
+		strings.Contains(strings.ToLower(e.Message), "rate limit")
+}
+
+func (e *GitHubError) Error() string {
+	if e.Message == "" {
+		return fmt.Sprintf("GitHub API request failed with status: %d", e.StatusCode)
+	}
+
+	msg := fmt.Sprintf("GitHub API error: %s (status: %d)", e.Message, e.StatusCode)
+	if len(e.Errors) > 0 {
+		details := make([]string, len(e.Errors))
+		for i, fieldErr := range e.Errors {
+			details[i] = fieldErr.String()
+		}
+		msg += ", details: " + strings.Join(details, "; ")
+	}
+	if e.DocumentationURL != "" {
+		msg += ", see " + e.DocumentationURL
+	}
+	return msg
+}
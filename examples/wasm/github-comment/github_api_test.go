@@ -0,0 +1,98 @@
+//go:build ignore
+
+package main
+
+import "testing"
+
+// Run with: go test github_api.go github_api_test.go
+func TestIsValidGitHubAPIURL(t *testing.T) {
+	tests := []struct {
+		name    string
+		url     string
+		apiBase string
+		want    bool
+	}{
+		{"github.com default base", "https://api.github.com/repos/octocat/Hello-World/issues/1", "", true},
+		{"github.com explicit base", "https://api.github.com/repos/octocat/Hello-World/issues/1", "https://api.github.com", true},
+		{"enterprise base", "https://github.mycorp.com/api/v3/repos/team/service/issues/42", "https://github.mycorp.com/api/v3/", true},
+		{"enterprise base without trailing slash", "https://github.mycorp.com/api/v3/repos/team/service/issues/42", "https://github.mycorp.com/api/v3", true},
+		{"enterprise URL against github.com base", "https://github.mycorp.com/api/v3/repos/team/service/issues/42", "", false},
+		{"github.com URL against enterprise base", "https://api.github.com/repos/octocat/Hello-World/issues/1", "https://github.mycorp.com/api/v3/", false},
+		{"pull request path", "https://api.github.com/repos/octocat/Hello-World/pulls/1", "", false},
+		{"non-numeric issue", "https://api.github.com/repos/octocat/Hello-World/issues/abc", "", false},
+		{"missing repo", "https://api.github.com/repos/octocat/issues/1", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isValidGitHubAPIURL(tt.url, tt.apiBase); got != tt.want {
+				t.Errorf("isValidGitHubAPIURL(%q, %q) = %v, want %v", tt.url, tt.apiBase, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseGitHubErrorValidation(t *testing.T) {
+	body := []byte(`{
+		"message": "Validation Failed",
+		"errors": [
+			{"resource": "IssueComment", "field": "body", "code": "missing_field"},
+			"body is too long"
+		],
+		"documentation_url": "https://docs.github.com/rest/issues/comments#create-an-issue-comment"
+	}`)
+
+	err := parseGitHubError(422, body)
+
+	if err.StatusCode != 422 || err.Message != "Validation Failed" {
+		t.Fatalf("unexpected status/message: %d %q", err.StatusCode, err.Message)
+	}
+	if err.DocumentationURL != "https://docs.github.com/rest/issues/comments#create-an-issue-comment" {
+		t.Errorf("DocumentationURL = %q", err.DocumentationURL)
+	}
+	if len(err.Errors) != 2 {
+		t.Fatalf("got %d field errors, want 2", len(err.Errors))
+	}
+	want := GitHubFieldError{Resource: "IssueComment", Field: "body", Code: "missing_field"}
+	if err.Errors[0] != want {
+		t.Errorf("Errors[0] = %+v, want %+v", err.Errors[0], want)
+	}
+	if err.Errors[1].Message != "body is too long" {
+		t.Errorf("Errors[1].Message = %q", err.Errors[1].Message)
+	}
+	if err.IsRateLimit() {
+		t.Error("validation error reported as rate limit")
+	}
+
+	wantMsg := "GitHub API error: Validation Failed (status: 422), details: IssueComment.body missing_field; body is too long, see https://docs.github.com/rest/issues/comments#create-an-issue-comment"
+	if err.Error() != wantMsg {
+		t.Errorf("Error() = %q, want %q", err.Error(), wantMsg)
+	}
+}
+
+func TestParseGitHubErrorRateLimit(t *testing.T) {
+	body := []byte(`{"message": "API rate limit exceeded for user ID 1.", "documentation_url": "https://docs.github.com/rest/overview/rate-limits-for-the-rest-api"}`)
+
+	err := parseGitHubError(403, body)
+
+	if err.Message != "API rate limit exceeded for user ID 1." {
+		t.Errorf("Message = %q", err.Message)
+	}
+	if err.DocumentationURL != "https://docs.github.com/rest/overview/rate-limits-for-the-rest-api" {
+		t.Errorf("DocumentationURL = %q", err.DocumentationURL)
+	}
+	if len(err.Errors) != 0 {
+		t.Errorf("unexpected field errors: %+v", err.Errors)
+	}
+	if !err.IsRateLimit() {
+		t.Error("rate limit error not detected")
+	}
+}
+
+func TestParseGitHubErrorNonJSON(t *testing.T) {
+	err := parseGitHubError(502, []byte("<html>Bad Gateway</html>"))
+
+	if err.Error() != "GitHub API request failed with status: 502" {
+		t.Errorf("Error() = %q", err.Error())
+	}
+}
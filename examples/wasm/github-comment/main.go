@@ -6,15 +6,16 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
-	"strconv"
-	"strings"
 	"unsafe"
 )
 
 // Input represents the input structure received from Mule runtime
 type Input struct {
 	Prompt string `json:"prompt"` // JSON string containing the actual input (issue and comment)
-	Token  string `json:"token"`  // GitHub token for authentication
+	Token  string `json:"token"`  // GitHub token (optional; falls back to the GITHUB_TOKEN secret)
+	// GitHub REST API base URL (optional; defaults to https://api.github.com/,
+	// GitHub Enterprise Server uses https://HOST/api/v3/)
+	APIBaseURL string `json:"api_base_url"`
 }
 
 // CommentInput represents the actual input structure for posting a comment
@@ -55,6 +56,32 @@ func get_last_response_body(bufferPtr, bufferSize uintptr) uint32
 //go:wasmimport env get_last_response_status
 func get_last_response_status() uint32
 
+// get_secret reads a named secret from the host's secret store
+//
+//go:wasmimport env get_secret
+func get_secret(namePtr, nameSize, bufferPtr, bufferSize uintptr) uint32
+
+// getSecret returns the named secret from the host, using the two-call pattern
+// to size the buffer first
+func getSecret(name string) (string, error) {
+	namePtr, nameSize := stringToPtr(name)
+
+	size := get_secret(namePtr, nameSize, 0, 0)
+	if size >= 0xFFFFFFF0 {
+		return "", fmt.Errorf("secret %s not available (error code 0x%X)", name, size)
+	}
+	if size == 0 {
+		return "", nil
+	}
+
+	buffer := make([]byte, size)
+	result := get_secret(namePtr, nameSize, uintptr(unsafe.Pointer(&buffer[0])), uintptr(size))
+	if result >= 0xFFFFFFF0 {
+		return "", fmt.Errorf("failed to read secret %s (error code 0x%X)", name, result)
+	}
+	return string(buffer[:result]), nil
+}
+
 // stringToPtr converts a string to a pointer and size for WASM host functions
 func stringToPtr(s string) (uintptr, uintptr) {
 	bytes := []byte(s)
@@ -70,38 +97,6 @@ func mapToJSONPtr(m interface{}) (uintptr, uintptr, error) {
 	return uintptr(unsafe.Pointer(&bytes[0])), uintptr(len(bytes)), nil
 }
 
-// isValidGitHubAPIURL validates that the URL follows GitHub API format
-func isValidGitHubAPIURL(url string) bool {
-	// Check if it starts with the GitHub API base URL
-	const githubAPIBase = "https://api.github.com/repos/"
-	if !strings.HasPrefix(url, githubAPIBase) {
-		return false
-	}
-
-	// Check if it has the expected path structure
-	// Expected: https://api.github.com/repos/{owner}/{repo}/issues/{number}
-	path := url[len(githubAPIBase):]
-	parts := strings.Split(path, "/")
-
-	// Should have at least owner/repo/issues/number (4 parts)
-	if len(parts) < 4 {
-		return false
-	}
-
-	// Check if the third-to-last part is "issues"
-	if parts[len(parts)-2] != "issues" {
-		return false
-	}
-
-	// Check if the last part (issue number) is numeric
-	issueNumber := parts[len(parts)-1]
-	if _, err := strconv.Atoi(issueNumber); err != nil {
-		return false
-	}
-
-	return true
-}
-
 func main() {
 	// Read input from stdin
 	// We need to handle the case where prompt might be a JSON object instead of a string
@@ -167,9 +162,15 @@ func main() {
 		return
 	}
 
+	// Extract the API base URL if present
+	var apiBase string
+	if baseVal, ok := inputMap["api_base_url"].(string); ok {
+		apiBase = baseVal
+	}
+
 	// Basic validation of GitHub API URL format
-	if !isValidGitHubAPIURL(commentInput.Issue) {
-		outputError(fmt.Errorf("invalid GitHub API URL format. Expected format: https://api.github.com/repos/{owner}/{repo}/issues/{number}"))
+	if !isValidGitHubAPIURL(commentInput.Issue, apiBase) {
+		outputError(fmt.Errorf("invalid GitHub API URL format. Expected format: %srepos/{owner}/{repo}/issues/{number}", normalizeAPIBase(apiBase)))
 		return
 	}
 
@@ -188,6 +189,11 @@ func main() {
 		return
 	}
 
+	// Fall back to the host secret store so the token never passes through input
+	if token == "" {
+		token, _ = getSecret("GITHUB_TOKEN")
+	}
+
 	// Validate token
 	if token == "" {
 		outputError(fmt.Errorf("GitHub token is required"))
@@ -252,7 +258,6 @@ func main() {
 
 	bodySizeRet := get_last_response_body(bufferPtr, bufferSize)
 
-	var responseMessage string
 	var responseURL string
 
 	if bodySizeRet > 0 && bodySizeRet <= uint32(len(buffer)) {
@@ -265,9 +270,6 @@ func main() {
 			if url, ok := responseMap["html_url"].(string); ok {
 				responseURL = url
 			}
-			if message, ok := responseMap["message"].(string); ok {
-				responseMessage = message
-			}
 		}
 	}
 
@@ -289,27 +291,11 @@ func main() {
 		}
 	} else {
 		// Error
-		var errorMessage string
-		if responseMessage != "" {
-			errorMessage = fmt.Sprintf("GitHub API error: %s (status: %d)", responseMessage, statusCode)
-		} else {
-			errorMessage = fmt.Sprintf("GitHub API request failed with status: %d", statusCode)
-		}
-
-		// Try to parse detailed error response
+		var responseBody []byte
 		if bodySizeRet > 0 && bodySizeRet <= uint32(len(buffer)) {
-			responseBody := string(buffer[:bodySizeRet])
-			var errorDetails map[string]interface{}
-			if err := json.Unmarshal([]byte(responseBody), &errorDetails); err == nil {
-				if details, ok := errorDetails["errors"]; ok {
-					if detailsBytes, err := json.Marshal(details); err == nil {
-						errorMessage += fmt.Sprintf(", details: %s", string(detailsBytes))
-					}
-				}
-			}
+			responseBody = buffer[:bodySizeRet]
 		}
-
-		outputError(fmt.Errorf(errorMessage))
+		outputError(parseGitHubError(statusCode, responseBody))
 		return
 	}
 }
@@ -13,6 +13,8 @@ import (
 type Input struct {
 	RepoURL string `json:"repo_url"`
 	Token   string `json:"token"`
+	State   string `json:"state,omitempty"`  // open, closed, all
+	Labels  string `json:"labels,omitempty"` // comma-separated label names
 }
 
 // Label represents a GitHub label
@@ -79,6 +81,12 @@ func main() {
 		return
 	}
 
+	query, err := issuesQuery(input.State, input.Labels)
+	if err != nil {
+		outputError(err)
+		return
+	}
+
 	// Extract owner and repo from URL
 	owner, repo, err := parseGitHubURL(input.RepoURL)
 	if err != nil {
@@ -89,6 +97,11 @@ func main() {
 	// Construct GitHub API URL
 	apiURL := fmt.Sprintf("https://api.github.com/repos/%s/%s/issues", owner, repo)
 
+	// Forward the optional filters
+	if query != "" {
+		apiURL += "?" + query
+	}
+
 	// Prepare headers
 	headers := map[string]string{
 		"Authorization": fmt.Sprintf("Bearer %s", input.Token),
@@ -197,24 +210,24 @@ func main() {
 }
 
 // parseGitHubURL extracts owner and repo from a GitHub URL
-func parseGitHubURL(url string) (owner, repo string, err error) {
+func parseGitHubURL(repoURL string) (owner, repo string, err error) {
 	// Handle different GitHub URL formats
 	// https://github.com/owner/repo
 	// https://github.com/owner/repo/
 
 	// Remove trailing slash if present
-	if len(url) > 0 && url[len(url)-1] == '/' {
-		url = url[:len(url)-1]
+	if len(repoURL) > 0 && repoURL[len(repoURL)-1] == '/' {
+		repoURL = repoURL[:len(repoURL)-1]
 	}
 
 	// Check if it's a GitHub URL
 	const prefix = "https://github.com/"
-	if !startsWith(url, prefix) {
+	if !startsWith(repoURL, prefix) {
 		return "", "", fmt.Errorf("not a valid GitHub URL")
 	}
 
 	// Extract owner/repo part
-	path := url[len(prefix):]
+	path := repoURL[len(prefix):]
 
 	// Split by slash
 	parts := split(path, "/")
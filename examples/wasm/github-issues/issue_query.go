@@ -0,0 +1,37 @@
+//go:build wasm || ignore
+
+package main
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// issuesQuery builds the query string for the GitHub list issues API from the
+// optional state and comma-separated labels filters. It returns an empty
+// string when neither is set, in which case GitHub returns open issues of any
+// label.
+func issuesQuery(state, labels string) (string, error) {
+	params := url.Values{}
+
+	switch state {
+	case "":
+	case "open", "closed", "all":
+		params.Set("state", state)
+	default:
+		return "", fmt.Errorf("state must be open, closed or all")
+	}
+
+	var names []string
+	for _, name := range strings.Split(labels, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			names = append(names, name)
+		}
+	}
+	if len(names) > 0 {
+		params.Set("labels", strings.Join(names, ","))
+	}
+
+	return params.Encode(), nil
+}
@@ -0,0 +1,57 @@
+//go:build ignore
+
+package main
+
+import (
+	"strconv"
+	"strings"
+)
+
+// defaultGitHubAPIBase is the REST API base URL for github.com
+const defaultGitHubAPIBase = "https://api.github.com/"
+
+// normalizeAPIBase returns the GitHub REST API base URL with a trailing slash,
+// defaulting to github.com. GitHub Enterprise Server hosts use a base such as
+// https://github.mycorp.com/api/v3/.
+func normalizeAPIBase(apiBase string) string {
+	apiBase = strings.TrimSpace(apiBase)
+	if apiBase == "" {
+		return defaultGitHubAPIBase
+	}
+	if !strings.HasSuffix(apiBase, "/") {
+		apiBase += "/"
+	}
+	return apiBase
+}
+
+// isValidGitHubAPIURL validates that the URL is an issue URL under the given
+// API base: {apiBase}repos/{owner}/{repo}/issues/{number}
+func isValidGitHubAPIURL(url, apiBase string) bool {
+	// Check if it starts with the repos endpoint of the API base URL
+	reposBase := normalizeAPIBase(apiBase) + "repos/"
+	if !strings.HasPrefix(url, reposBase) {
+		return false
+	}
+
+	// Check if it has the expected path structure
+	path := url[len(reposBase):]
+	parts := strings.Split(path, "/")
+
+	// Should have at least owner/repo/issues/number (4 parts)
+	if len(parts) < 4 {
+		return false
+	}
+
+	// Check if the second-to-last part is "issues"
+	if parts[len(parts)-2] != "issues" {
+		return false
+	}
+
+	// Check if the last part (issue number) is numeric
+	issueNumber := parts[len(parts)-1]
+	if _, err := strconv.Atoi(issueNumber); err != nil {
+		return false
+	}
+
+	return true
+}
@@ -0,0 +1,33 @@
+//go:build ignore
+
+package main
+
+import "testing"
+
+// Run with: go test github_api.go github_api_test.go
+func TestIsValidGitHubAPIURL(t *testing.T) {
+	tests := []struct {
+		name    string
+		url     string
+		apiBase string
+		want    bool
+	}{
+		{"github.com default base", "https://api.github.com/repos/octocat/Hello-World/issues/1", "", true},
+		{"github.com explicit base", "https://api.github.com/repos/octocat/Hello-World/issues/1", "https://api.github.com", true},
+		{"enterprise base", "https://github.mycorp.com/api/v3/repos/team/service/issues/42", "https://github.mycorp.com/api/v3/", true},
+		{"enterprise base without trailing slash", "https://github.mycorp.com/api/v3/repos/team/service/issues/42", "https://github.mycorp.com/api/v3", true},
+		{"enterprise URL against github.com base", "https://github.mycorp.com/api/v3/repos/team/service/issues/42", "", false},
+		{"github.com URL against enterprise base", "https://api.github.com/repos/octocat/Hello-World/issues/1", "https://github.mycorp.com/api/v3/", false},
+		{"pull request path", "https://api.github.com/repos/octocat/Hello-World/pulls/1", "", false},
+		{"non-numeric issue", "https://api.github.com/repos/octocat/Hello-World/issues/abc", "", false},
+		{"missing repo", "https://api.github.com/repos/octocat/issues/1", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isValidGitHubAPIURL(tt.url, tt.apiBase); got != tt.want {
+				t.Errorf("isValidGitHubAPIURL(%q, %q) = %v, want %v", tt.url, tt.apiBase, got, tt.want)
+			}
+		})
+	}
+}
@@ -6,7 +6,6 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
-	"strconv"
 	"strings"
 	"unsafe"
 )
@@ -15,6 +14,9 @@ type Input struct {
 	Prompt string   `json:"prompt"`
 	Token  string   `json:"token"`
 	States []string `json:"states"`
+	// GitHub REST API base URL (optional; defaults to https://api.github.com/,
+	// GitHub Enterprise Server uses https://HOST/api/v3/)
+	APIBaseURL string `json:"api_base_url"`
 }
 
 // StateInput represents the actual input structure for updating issue state
@@ -63,6 +65,32 @@ func get_last_response_body(bufferPtr, bufferSize uintptr) uint32
 //go:wasmimport env get_last_response_status
 func get_last_response_status() uint32
 
+// get_secret reads a named secret from the host's secret store
+//
+//go:wasmimport env get_secret
+func get_secret(namePtr, nameSize, bufferPtr, bufferSize uintptr) uint32
+
+// getSecret returns the named secret from the host, using the two-call pattern
+// to size the buffer first
+func getSecret(name string) (string, error) {
+	namePtr, nameSize := stringToPtr(name)
+
+	size := get_secret(namePtr, nameSize, 0, 0)
+	if size >= 0xFFFFFFF0 {
+		return "", fmt.Errorf("secret %s not available (error code 0x%X)", name, size)
+	}
+	if size == 0 {
+		return "", nil
+	}
+
+	buffer := make([]byte, size)
+	result := get_secret(namePtr, nameSize, uintptr(unsafe.Pointer(&buffer[0])), uintptr(size))
+	if result >= 0xFFFFFFF0 {
+		return "", fmt.Errorf("failed to read secret %s (error code 0x%X)", name, result)
+	}
+	return string(buffer[:result]), nil
+}
+
 // stringToPtr converts a string to a pointer and size for WASM host functions
 func stringToPtr(s string) (uintptr, uintptr) {
 	bytes := []byte(s)
@@ -78,38 +106,6 @@ func mapToJSONPtr(m interface{}) (uintptr, uintptr, error) {
 	return uintptr(unsafe.Pointer(&bytes[0])), uintptr(len(bytes)), nil
 }
 
-// isValidGitHubAPIURL validates that the URL follows GitHub API format
-func isValidGitHubAPIURL(url string) bool {
-	// Check if it starts with the GitHub API base URL
-	const githubAPIBase = "https://api.github.com/repos/"
-	if !strings.HasPrefix(url, githubAPIBase) {
-		return false
-	}
-
-	// Check if it has the expected path structure
-	// Expected: https://api.github.com/repos/{owner}/{repo}/issues/{number}
-	path := url[len(githubAPIBase):]
-	parts := strings.Split(path, "/")
-
-	// Should have at least owner/repo/issues/number (4 parts)
-	if len(parts) < 4 {
-		return false
-	}
-
-	// Check if the third-to-last part is "issues"
-	if parts[len(parts)-2] != "issues" {
-		return false
-	}
-
-	// Check if the last part (issue number) is numeric
-	issueNumber := parts[len(parts)-1]
-	if _, err := strconv.Atoi(issueNumber); err != nil {
-		return false
-	}
-
-	return true
-}
-
 // containsString checks if a string is in a slice
 func containsString(slice []string, s string) bool {
 	for _, item := range slice {
@@ -149,8 +145,8 @@ func main() {
 	}
 
 	// Basic validation of GitHub API URL format
-	if !isValidGitHubAPIURL(stateInput.Issue) {
-		outputError(fmt.Errorf("invalid GitHub API URL format. Expected format: https://api.github.com/repos/{owner}/{repo}/issues/{number}"))
+	if !isValidGitHubAPIURL(stateInput.Issue, input.APIBaseURL) {
+		outputError(fmt.Errorf("invalid GitHub API URL format. Expected format: %srepos/{owner}/{repo}/issues/{number}", normalizeAPIBase(input.APIBaseURL)))
 		return
 	}
 
@@ -167,6 +163,11 @@ func main() {
 		}
 	}
 
+	// Fall back to the host secret store so the token never passes through input
+	if input.Token == "" {
+		input.Token, _ = getSecret("GITHUB_TOKEN")
+	}
+
 	// Validate token
 	if input.Token == "" {
 		outputError(fmt.Errorf("GitHub token is required"))
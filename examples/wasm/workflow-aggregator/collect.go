@@ -0,0 +1,31 @@
+//go:build ignore
+
+package main
+
+import "sync"
+
+// collectOrdered runs run(0) through run(n-1) with at most concurrency calls
+// in flight and returns the results indexed by input position, so the output
+// order never depends on which call finishes first. A concurrency of 0 or
+// less runs every call at once.
+func collectOrdered[T any](n, concurrency int, run func(index int) T) []T {
+	results := make([]T, n)
+	if concurrency <= 0 || concurrency > n {
+		concurrency = n
+	}
+
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, max(concurrency, 1))
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(index int) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			results[index] = run(index)
+		}(i)
+	}
+	wg.Wait()
+
+	return results
+}
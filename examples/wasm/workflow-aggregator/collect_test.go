@@ -0,0 +1,50 @@
+//go:build ignore
+
+package main
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestCollectOrderedPreservesInputOrder(t *testing.T) {
+	// Earlier inputs take longer, so they finish last
+	delays := []time.Duration{40 * time.Millisecond, 30 * time.Millisecond, 20 * time.Millisecond, 10 * time.Millisecond, 0}
+
+	for _, concurrency := range []int{0, 1, 2, len(delays)} {
+		results := collectOrdered(len(delays), concurrency, func(i int) int {
+			time.Sleep(delays[i])
+			return i
+		})
+
+		if len(results) != len(delays) {
+			t.Fatalf("concurrency %d: got %d results, want %d", concurrency, len(results), len(delays))
+		}
+		for i, got := range results {
+			if got != i {
+				t.Errorf("concurrency %d: results[%d] = %d, want %d", concurrency, i, got, i)
+			}
+		}
+	}
+}
+
+func TestCollectOrderedLimitsConcurrency(t *testing.T) {
+	var inFlight, peak atomic.Int32
+	collectOrdered(8, 3, func(i int) struct{} {
+		current := inFlight.Add(1)
+		for {
+			p := peak.Load()
+			if current <= p || peak.CompareAndSwap(p, current) {
+				break
+			}
+		}
+		time.Sleep(5 * time.Millisecond)
+		inFlight.Add(-1)
+		return struct{}{}
+	})
+
+	if got := peak.Load(); got > 3 {
+		t.Errorf("peak concurrency = %d, want at most 3", got)
+	}
+}
@@ -6,7 +6,6 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
-	"sync"
 	"time"
 	"unsafe"
 )
@@ -209,17 +208,14 @@ type WorkflowResult struct {
 }
 
 // executeWorkflow executes a single workflow with the given parameters
-func executeWorkflow(name string, params map[string]interface{}, wg *sync.WaitGroup, results chan<- WorkflowResult) {
-	defer wg.Done()
-
+func executeWorkflow(name string, params map[string]interface{}) WorkflowResult {
 	// Convert params to JSON
 	paramsJSON, err := json.Marshal(params)
 	if err != nil {
-		results <- WorkflowResult{
+		return WorkflowResult{
 			Name:  name,
 			Error: fmt.Sprintf("Error marshaling params: %v", err),
 		}
-		return
 	}
 
 	// Convert strings to pointers and sizes
@@ -242,63 +238,57 @@ func executeWorkflow(name string, params map[string]interface{}, wg *sync.WaitGr
 	// Call the execute_target host function to trigger the workflow
 	errorCode := execute_target(targetTypePtr, targetTypeSize, targetIDPtr, targetIDSize, paramsPtr, paramsSize)
 	if errorCode != 0 {
-		results <- WorkflowResult{
+		return WorkflowResult{
 			Name:  name,
 			Error: fmt.Sprintf("Error executing workflow: %d", errorCode),
 		}
-		return
 	}
 
 	// Get the status
 	status := get_last_operation_status()
 	if status != 0 {
-		results <- WorkflowResult{
+		return WorkflowResult{
 			Name:  name,
 			Error: fmt.Sprintf("Workflow execution failed with status: %d", status),
 		}
-		return
 	}
 
 	// Get the result (should contain job ID)
 	result, err := getLastOperationResult()
 	if err != nil {
-		results <- WorkflowResult{
+		return WorkflowResult{
 			Name:  name,
 			Error: fmt.Sprintf("Error getting result: %v", err),
 		}
-		return
 	}
 
 	// Parse the result to get the job ID
 	var jobResult map[string]interface{}
 	if err := json.Unmarshal(result, &jobResult); err != nil {
-		results <- WorkflowResult{
+		return WorkflowResult{
 			Name:  name,
 			Error: fmt.Sprintf("Error parsing job result: %v", err),
 		}
-		return
 	}
 
 	jobID, ok := jobResult["job_id"].(string)
 	if !ok {
-		results <- WorkflowResult{
+		return WorkflowResult{
 			Name:  name,
 			Error: fmt.Sprintf("Job ID not found in result: %s", string(result)),
 		}
-		return
 	}
 
 	// Wait for job completion by polling
 	output, err := waitForJobCompletion(jobID)
 	if err != nil {
-		results <- WorkflowResult{
+		return WorkflowResult{
 			Name:  name,
 			Error: fmt.Sprintf("Error waiting for job completion: %v", err),
 		}
-		return
 	}
 
-	results <- WorkflowResult{
+	return WorkflowResult{
 		Name:    name,
 		Success: true,
 		Output:  output,
@@ -484,12 +474,15 @@ func main() {
 		workingDir = wd
 	}
 
-	// Process each workflow
-	var wg sync.WaitGroup
-	results := make(chan WorkflowResult, len(workflowNameStrings))
+	// Limit how many workflows run at once (optional, default all at once)
+	maxConcurrency := 0
+	if mc, ok := inputData["max_concurrency"].(float64); ok {
+		maxConcurrency = int(mc)
+	}
 
-	// Launch workflows in parallel
-	for _, name := range workflowNameStrings {
+	// Launch workflows in parallel, collecting results in input order so the
+	// aggregated output is the same whichever workflow finishes first
+	allResults := collectOrdered(len(workflowNameStrings), maxConcurrency, func(i int) WorkflowResult {
 		// Prepare parameters for the workflow
 		params := map[string]interface{}{
 			"prompt": prompt,
@@ -500,22 +493,8 @@ func main() {
 			params["working_directory"] = workingDir
 		}
 
-		// Launch workflow in a goroutine
-		wg.Add(1)
-		go executeWorkflow(name, params, &wg, results)
-	}
-
-	// Close results channel when all goroutines are done
-	go func() {
-		wg.Wait()
-		close(results)
-	}()
-
-	// Collect all results
-	var allResults []WorkflowResult
-	for result := range results {
-		allResults = append(allResults, result)
-	}
+		return executeWorkflow(workflowNameStrings[i], params)
+	})
 
 	// Aggregate all outputs into a single string
 	var aggregatedOutput string
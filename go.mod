@@ -10,6 +10,7 @@ require (
 	github.com/itchyny/gojq v0.12.18
 	github.com/jbutlerdev/genai v0.0.0-20251123212530-26126dc7ac1f
 	github.com/lib/pq v1.10.9
+	github.com/santhosh-tekuri/jsonschema/v6 v6.0.3
 	github.com/stretchr/testify v1.11.1
 	github.com/tetratelabs/wazero v1.10.1
 )
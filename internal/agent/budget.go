@@ -0,0 +1,179 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+)
+
+// ErrBudgetExceeded is returned when an agent call would go over a workflow
+// or agent budget
+var ErrBudgetExceeded = errors.New("budget exceeded")
+
+// BudgetLimits caps the model calls and estimated tokens a run may use. Zero
+// means no limit.
+type BudgetLimits struct {
+	MaxModelCalls int
+	MaxTokens     int
+}
+
+// IsZero reports whether no limit is set
+func (l BudgetLimits) IsZero() bool {
+	return l.MaxModelCalls <= 0 && l.MaxTokens <= 0
+}
+
+// LoadBudgetLimits reads the "budget" object from a workflow config or an
+// agent's pi_config:
+//
+//	{"budget": {"max_model_calls": 20, "max_tokens": 50000}}
+func LoadBudgetLimits(config map[string]interface{}) BudgetLimits {
+	var limits BudgetLimits
+	budget, ok := config["budget"].(map[string]interface{})
+	if !ok {
+		return limits
+	}
+	limits.MaxModelCalls = intValue(budget["max_model_calls"])
+	limits.MaxTokens = intValue(budget["max_tokens"])
+	return limits
+}
+
+// intValue converts a config number, which is a float64 once it has been
+// through JSON, to an int
+func intValue(v interface{}) int {
+	switch n := v.(type) {
+	case float64:
+		return int(n)
+	case int:
+		return n
+	case string:
+		if val, err := strconv.Atoi(n); err == nil {
+			return val
+		}
+	}
+	return 0
+}
+
+// BudgetUsage is the consumption counted against a budget
+type BudgetUsage struct {
+	ModelCalls int `json:"model_calls"`
+	Tokens     int `json:"tokens"`
+}
+
+// ToMap converts the usage to the form stored in job output data
+func (u BudgetUsage) ToMap() map[string]interface{} {
+	return map[string]interface{}{
+		"model_calls": u.ModelCalls,
+		"tokens":      u.Tokens,
+	}
+}
+
+// Budget tracks the model calls and tokens used during one run, in total and
+// per agent, so that workflow and agent limits can be enforced. It is safe for
+// concurrent use.
+type Budget struct {
+	mu     sync.Mutex
+	limits BudgetLimits
+	used   BudgetUsage
+	agents map[string]*BudgetUsage
+}
+
+// NewBudget creates a budget with the given workflow-wide limits
+func NewBudget(limits BudgetLimits) *Budget {
+	return &Budget{limits: limits, agents: make(map[string]*BudgetUsage)}
+}
+
+// budgetKey is the context key holding the run's budget
+type budgetKey struct{}
+
+// WithBudget attaches a budget to the context. A context that already carries
+// a budget keeps it, so nested execution is charged to the same run.
+func WithBudget(ctx context.Context, budget *Budget) context.Context {
+	if ctx.Value(budgetKey{}) != nil {
+		return ctx
+	}
+	return context.WithValue(ctx, budgetKey{}, budget)
+}
+
+// budgetFromContext returns the context's budget, or a fresh unlimited one so
+// that agent limits still apply to calls made outside a workflow
+func budgetFromContext(ctx context.Context) *Budget {
+	if budget, ok := ctx.Value(budgetKey{}).(*Budget); ok {
+		return budget
+	}
+	return NewBudget(BudgetLimits{})
+}
+
+// Usage returns the total consumption so far
+func (b *Budget) Usage() BudgetUsage {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.used
+}
+
+// reserve counts a model call for agentID, or returns ErrBudgetExceeded
+// without counting it if the workflow or agent budget has no calls or tokens
+// left
+func (b *Budget) reserve(agentID string, agentLimits BudgetLimits) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	agentUsed := b.agentUsage(agentID)
+	if err := checkBudget("workflow", b.limits, b.used, true); err != nil {
+		return err
+	}
+	if err := checkBudget("agent", agentLimits, *agentUsed, true); err != nil {
+		return err
+	}
+
+	b.used.ModelCalls++
+	agentUsed.ModelCalls++
+	return nil
+}
+
+// record adds the tokens used by a call to agentID and returns
+// ErrBudgetExceeded if that took the workflow or agent over its token limit
+func (b *Budget) record(agentID string, agentLimits BudgetLimits, usage ChatCompletionUsage) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	agentUsed := b.agentUsage(agentID)
+	b.used.Tokens += usage.TotalTokens
+	agentUsed.Tokens += usage.TotalTokens
+
+	if err := checkBudget("workflow", b.limits, b.used, false); err != nil {
+		return err
+	}
+	return checkBudget("agent", agentLimits, *agentUsed, false)
+}
+
+// agentUsage returns the consumption of agentID, creating it if needed. The
+// caller must hold b.mu.
+func (b *Budget) agentUsage(agentID string) *BudgetUsage {
+	used, ok := b.agents[agentID]
+	if !ok {
+		used = &BudgetUsage{}
+		b.agents[agentID] = used
+	}
+	return used
+}
+
+// checkBudget returns ErrBudgetExceeded if used is over limits. For a new
+// call, reaching either limit already counts as exceeded, since the call
+// would take it over.
+func checkBudget(scope string, limits BudgetLimits, used BudgetUsage, newCall bool) error {
+	calls := used.ModelCalls
+	if newCall {
+		calls++
+	}
+	if limits.MaxModelCalls > 0 && calls > limits.MaxModelCalls {
+		return fmt.Errorf("%w: %s limit of %d model calls reached (used %d calls, %d tokens)",
+			ErrBudgetExceeded, scope, limits.MaxModelCalls, used.ModelCalls, used.Tokens)
+	}
+	if limits.MaxTokens > 0 && (used.Tokens > limits.MaxTokens || (newCall && used.Tokens >= limits.MaxTokens)) {
+		return fmt.Errorf("%w: %s limit of %d tokens exceeded (used %d calls, %d tokens)",
+			ErrBudgetExceeded, scope, limits.MaxTokens, used.ModelCalls, used.Tokens)
+	}
+	return nil
+}
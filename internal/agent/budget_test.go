@@ -0,0 +1,58 @@
+package agent
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadBudgetLimits(t *testing.T) {
+	assert.True(t, LoadBudgetLimits(nil).IsZero())
+	assert.True(t, LoadBudgetLimits(map[string]interface{}{"budget": "lots"}).IsZero())
+	assert.Equal(t, BudgetLimits{MaxModelCalls: 3, MaxTokens: 500}, LoadBudgetLimits(map[string]interface{}{
+		"budget": map[string]interface{}{"max_model_calls": float64(3), "max_tokens": "500"},
+	}))
+}
+
+func TestBudgetModelCalls(t *testing.T) {
+	budget := NewBudget(BudgetLimits{MaxModelCalls: 2})
+
+	require.NoError(t, budget.reserve("agent-a", BudgetLimits{}))
+	require.NoError(t, budget.reserve("agent-b", BudgetLimits{}))
+	err := budget.reserve("agent-a", BudgetLimits{})
+	require.ErrorIs(t, err, ErrBudgetExceeded)
+	assert.Contains(t, err.Error(), "workflow limit of 2 model calls")
+	assert.Equal(t, BudgetUsage{ModelCalls: 2}, budget.Usage(), "a refused call is not counted")
+}
+
+func TestBudgetTokens(t *testing.T) {
+	budget := NewBudget(BudgetLimits{MaxTokens: 150})
+
+	require.NoError(t, budget.reserve("agent-a", BudgetLimits{}))
+	require.NoError(t, budget.record("agent-a", BudgetLimits{}, ChatCompletionUsage{TotalTokens: 100}))
+	require.NoError(t, budget.reserve("agent-a", BudgetLimits{}))
+	err := budget.record("agent-a", BudgetLimits{}, ChatCompletionUsage{TotalTokens: 100})
+	require.ErrorIs(t, err, ErrBudgetExceeded)
+	assert.Contains(t, err.Error(), "used 2 calls, 200 tokens")
+}
+
+func TestBudgetPerAgent(t *testing.T) {
+	budget := NewBudget(BudgetLimits{})
+	chatty := BudgetLimits{MaxModelCalls: 1}
+
+	require.NoError(t, budget.reserve("chatty", chatty))
+	err := budget.reserve("chatty", chatty)
+	require.ErrorIs(t, err, ErrBudgetExceeded)
+	assert.Contains(t, err.Error(), "agent limit of 1 model calls")
+	require.NoError(t, budget.reserve("other", BudgetLimits{}), "other agents are unaffected")
+}
+
+func TestWithBudgetKeepsExistingBudget(t *testing.T) {
+	outer := NewBudget(BudgetLimits{MaxModelCalls: 1})
+	ctx := WithBudget(context.Background(), outer)
+	ctx = WithBudget(ctx, NewBudget(BudgetLimits{}))
+	assert.Same(t, outer, budgetFromContext(ctx))
+	assert.NotNil(t, budgetFromContext(context.Background()))
+}
@@ -0,0 +1,185 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/mule-ai/mule/pkg/job"
+)
+
+// defaultRepoConcurrency is how many repositories ExecuteWorkflowAcrossRepos
+// runs at once when the caller does not say
+const defaultRepoConcurrency = 4
+
+// jobPollInterval is how often a job is checked while waiting for it to finish
+var jobPollInterval = 500 * time.Millisecond
+
+// repoStatusTimedOut is the status of a repository run whose job did not
+// finish before the request's deadline. The job is cancelled.
+const repoStatusTimedOut = "timed_out"
+
+// jobCanceller is implemented by workflow engines that can stop a running job
+type jobCanceller interface {
+	CancelJob(jobID string) error
+}
+
+// RepoResult is the outcome of running a workflow against one repository
+type RepoResult struct {
+	Repository string                 `json:"repository"`
+	JobID      string                 `json:"job_id,omitempty"`
+	Status     string                 `json:"status"`
+	Output     map[string]interface{} `json:"output,omitempty"`
+	Error      string                 `json:"error,omitempty"`
+}
+
+// RepoWorkflowResponse is the response to a workflow request that names
+// several repositories
+type RepoWorkflowResponse struct {
+	Object  string       `json:"object"`
+	Model   string       `json:"model"`
+	Results []RepoResult `json:"results"`
+}
+
+// ExecuteWorkflowAcrossRepos runs the requested workflow once per repository,
+// using each repository path as the job's working directory. At most
+// concurrency runs are in flight at a time (defaultRepoConcurrency when not
+// positive), and each run is waited on until its job finishes. Jobs still
+// running when ctx ends are cancelled and reported as timed out (or cancelled
+// if ctx was cancelled rather than timed out). Results are returned in the
+// order of repos; a failed run does not stop the others.
+func (r *Runtime) ExecuteWorkflowAcrossRepos(ctx context.Context, req *ChatCompletionRequest, repos []string, concurrency int) []RepoResult {
+	if concurrency <= 0 {
+		concurrency = defaultRepoConcurrency
+	}
+
+	results := make([]RepoResult, len(repos))
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+
+	for i, repo := range repos {
+		wg.Add(1)
+		go func(i int, repo string) {
+			defer wg.Done()
+
+			select {
+			case sem <- struct{}{}:
+			case <-ctx.Done():
+				results[i] = RepoResult{Repository: repo, Status: stoppedStatus(ctx), Error: ctx.Err().Error()}
+				return
+			}
+			defer func() { <-sem }()
+
+			results[i] = r.runWorkflowForRepo(ctx, req, repo)
+		}(i, repo)
+	}
+
+	wg.Wait()
+	return results
+}
+
+// runWorkflowForRepo submits the workflow for one repository and waits for it
+func (r *Runtime) runWorkflowForRepo(ctx context.Context, req *ChatCompletionRequest, repo string) RepoResult {
+	result := RepoResult{Repository: repo}
+
+	submitted, err := r.ExecuteWorkflowWithWorkingDir(ctx, req, repo)
+	if err != nil {
+		result.Status = string(job.StatusFailed)
+		result.Error = err.Error()
+		return result
+	}
+	result.JobID = submitted.ID
+
+	finished, err := r.waitForJob(ctx, submitted.ID)
+	if err != nil && ctx.Err() != nil {
+		// Nobody is waiting for the job any more, so stop it
+		r.cancelJob(submitted.ID)
+		result.Status = stoppedStatus(ctx)
+		result.Error = err.Error()
+		return result
+	}
+	if err != nil {
+		result.Status = string(submitted.Status)
+		result.Error = err.Error()
+		return result
+	}
+
+	result.Status = string(finished.Status)
+	if finished.Status == job.StatusFailed {
+		// Failed jobs keep their error in output_data
+		result.Error = fmt.Sprintf("%v", finished.OutputData["error"])
+	} else {
+		result.Output = finished.OutputData
+	}
+	return result
+}
+
+// SubmitWorkflowAcrossRepos queues the requested workflow once per repository,
+// like ExecuteWorkflowAcrossRepos, but returns as soon as the jobs are
+// submitted. Each result carries the job ID and its initial status.
+func (r *Runtime) SubmitWorkflowAcrossRepos(ctx context.Context, req *ChatCompletionRequest, repos []string) []RepoResult {
+	results := make([]RepoResult, len(repos))
+	for i, repo := range repos {
+		results[i] = RepoResult{Repository: repo}
+
+		submitted, err := r.ExecuteWorkflowWithWorkingDir(ctx, req, repo)
+		if err != nil {
+			results[i].Status = string(job.StatusFailed)
+			results[i].Error = err.Error()
+			continue
+		}
+		results[i].JobID = submitted.ID
+		results[i].Status = string(submitted.Status)
+	}
+	return results
+}
+
+// stoppedStatus is the status of a run abandoned because ctx ended: timed out
+// when its deadline passed, otherwise cancelled
+func stoppedStatus(ctx context.Context) string {
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return repoStatusTimedOut
+	}
+	return string(job.StatusCancelled)
+}
+
+// cancelJob cancels a job through the workflow engine when it supports that,
+// so a running step is stopped too, and otherwise in the job store
+func (r *Runtime) cancelJob(jobID string) {
+	var err error
+	if canceller, ok := r.workflowEngine.(jobCanceller); ok {
+		err = canceller.CancelJob(jobID)
+	} else {
+		err = r.jobStore.CancelJob(jobID)
+	}
+	if err != nil {
+		log.Printf("Failed to cancel job %s: %v", jobID, err)
+	}
+}
+
+// waitForJob polls the job store until the job completes, fails or is cancelled
+func (r *Runtime) waitForJob(ctx context.Context, jobID string) (*job.Job, error) {
+	ticker := time.NewTicker(jobPollInterval)
+	defer ticker.Stop()
+
+	for {
+		current, err := r.jobStore.GetJob(jobID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get job status: %w", err)
+		}
+
+		switch current.Status {
+		case job.StatusCompleted, job.StatusFailed, job.StatusCancelled:
+			return current, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("waiting for job %s: %w", jobID, ctx.Err())
+		case <-ticker.C:
+		}
+	}
+}
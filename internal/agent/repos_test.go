@@ -0,0 +1,212 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mule-ai/mule/internal/primitive"
+	"github.com/mule-ai/mule/pkg/job"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// repoJobStore runs each submitted job on its first status check and finishes
+// it on the second, recording how many jobs were running at once
+type repoJobStore struct {
+	MockJobStore
+
+	mu        sync.Mutex
+	jobs      map[string]*job.Job
+	active    int
+	maxActive int
+}
+
+func (s *repoJobStore) CreateJob(j *job.Job) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.jobs[j.ID] = j
+	return nil
+}
+
+func (s *repoJobStore) UpdateJob(j *job.Job) error {
+	return nil
+}
+
+func (s *repoJobStore) GetJob(id string) (*job.Job, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	j, ok := s.jobs[id]
+	if !ok {
+		return nil, job.ErrJobNotFound
+	}
+
+	switch j.Status {
+	case job.StatusQueued:
+		j.Status = job.StatusRunning
+		s.active++
+		s.maxActive = max(s.maxActive, s.active)
+	case job.StatusRunning:
+		if j.WorkingDirectory == "/repos/slow" {
+			break
+		}
+		s.active--
+		if j.WorkingDirectory == "/repos/broken" {
+			j.Status = job.StatusFailed
+			j.OutputData = map[string]interface{}{"error": "step 1 failed"}
+		} else {
+			j.Status = job.StatusCompleted
+			j.OutputData = map[string]interface{}{"prompt": "done in " + j.WorkingDirectory}
+		}
+	}
+
+	copied := *j
+	return &copied, nil
+}
+
+// repoWorkflowEngine queues jobs in a repoJobStore
+type repoWorkflowEngine struct {
+	store     *repoJobStore
+	count     int
+	cancelled []string
+	mu        sync.Mutex
+}
+
+func (e *repoWorkflowEngine) CancelJob(jobID string) error {
+	e.mu.Lock()
+	e.cancelled = append(e.cancelled, jobID)
+	e.mu.Unlock()
+
+	e.store.mu.Lock()
+	defer e.store.mu.Unlock()
+	e.store.jobs[jobID].Status = job.StatusCancelled
+	return nil
+}
+
+func (e *repoWorkflowEngine) SubmitJob(ctx context.Context, workflowID string, inputData map[string]interface{}) (*job.Job, error) {
+	e.mu.Lock()
+	e.count++
+	id := fmt.Sprintf("job-%d", e.count)
+	e.mu.Unlock()
+
+	j := &job.Job{ID: id, WorkflowID: workflowID, Status: job.StatusQueued, InputData: inputData}
+	return j, e.store.CreateJob(j)
+}
+
+func TestRuntime_ExecuteWorkflowAcrossRepos(t *testing.T) {
+	originalInterval := jobPollInterval
+	jobPollInterval = 5 * time.Millisecond
+	defer func() { jobPollInterval = originalInterval }()
+
+	store := &MockAgentStore{
+		workflows: map[string]*primitive.Workflow{
+			"add-badge": {ID: "add-badge", Name: "add-badge"},
+		},
+	}
+	jobStore := &repoJobStore{jobs: make(map[string]*job.Job)}
+	runtime := NewRuntime(store, jobStore)
+	runtime.SetWorkflowEngine(&repoWorkflowEngine{store: jobStore})
+
+	req := &ChatCompletionRequest{
+		Model:    "workflow/add-badge",
+		Messages: []ChatCompletionMessage{{Role: "user", Content: "Add a CI badge"}},
+	}
+	repos := []string{"/repos/api", "/repos/broken", "/repos/web"}
+
+	results := runtime.ExecuteWorkflowAcrossRepos(context.Background(), req, repos, 2)
+
+	require.Len(t, results, 3)
+	jobIDs := make(map[string]bool)
+	for i, result := range results {
+		assert.Equal(t, repos[i], result.Repository)
+		assert.NotEmpty(t, result.JobID)
+		jobIDs[result.JobID] = true
+	}
+	assert.Len(t, jobIDs, 3, "each repository should get its own job")
+
+	assert.Equal(t, string(job.StatusCompleted), results[0].Status)
+	assert.Equal(t, "done in /repos/api", results[0].Output["prompt"])
+
+	assert.Equal(t, string(job.StatusFailed), results[1].Status)
+	assert.Equal(t, "step 1 failed", results[1].Error)
+
+	assert.Equal(t, string(job.StatusCompleted), results[2].Status)
+	assert.Equal(t, "done in /repos/web", results[2].Output["prompt"])
+
+	assert.LessOrEqual(t, jobStore.maxActive, 2)
+}
+
+func TestRuntime_ExecuteWorkflowAcrossReposUnknownWorkflow(t *testing.T) {
+	jobStore := &repoJobStore{jobs: make(map[string]*job.Job)}
+	runtime := NewRuntime(&MockAgentStore{}, jobStore)
+	runtime.SetWorkflowEngine(&repoWorkflowEngine{store: jobStore})
+
+	req := &ChatCompletionRequest{Model: "workflow/missing"}
+	results := runtime.ExecuteWorkflowAcrossRepos(context.Background(), req, []string{"/repos/api"}, 0)
+
+	require.Len(t, results, 1)
+	assert.Equal(t, string(job.StatusFailed), results[0].Status)
+	assert.Contains(t, results[0].Error, "not found")
+	assert.Empty(t, results[0].JobID)
+}
+
+func TestRuntime_ExecuteWorkflowAcrossReposTimeout(t *testing.T) {
+	originalInterval := jobPollInterval
+	jobPollInterval = 5 * time.Millisecond
+	defer func() { jobPollInterval = originalInterval }()
+
+	store := &MockAgentStore{
+		workflows: map[string]*primitive.Workflow{
+			"add-badge": {ID: "add-badge", Name: "add-badge"},
+		},
+	}
+	jobStore := &repoJobStore{jobs: make(map[string]*job.Job)}
+	engine := &repoWorkflowEngine{store: jobStore}
+	runtime := NewRuntime(store, jobStore)
+	runtime.SetWorkflowEngine(engine)
+
+	req := &ChatCompletionRequest{
+		Model:    "workflow/add-badge",
+		Messages: []ChatCompletionMessage{{Role: "user", Content: "Add a CI badge"}},
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	results := runtime.ExecuteWorkflowAcrossRepos(ctx, req, []string{"/repos/api", "/repos/slow"}, 2)
+
+	require.Len(t, results, 2)
+	assert.Equal(t, string(job.StatusCompleted), results[0].Status)
+
+	assert.Equal(t, "timed_out", results[1].Status)
+	assert.NotEmpty(t, results[1].Error)
+	assert.Equal(t, []string{results[1].JobID}, engine.cancelled, "the unfinished job is cancelled")
+	assert.Equal(t, job.StatusCancelled, jobStore.jobs[results[1].JobID].Status)
+}
+
+func TestRuntime_SubmitWorkflowAcrossRepos(t *testing.T) {
+	store := &MockAgentStore{
+		workflows: map[string]*primitive.Workflow{
+			"add-badge": {ID: "add-badge", Name: "add-badge"},
+		},
+	}
+	jobStore := &repoJobStore{jobs: make(map[string]*job.Job)}
+	runtime := NewRuntime(store, jobStore)
+	runtime.SetWorkflowEngine(&repoWorkflowEngine{store: jobStore})
+
+	req := &ChatCompletionRequest{
+		Model:    "async/workflow/add-badge",
+		Messages: []ChatCompletionMessage{{Role: "user", Content: "Add a CI badge"}},
+	}
+	results := runtime.SubmitWorkflowAcrossRepos(context.Background(), req, []string{"/repos/api", "/repos/slow"})
+
+	require.Len(t, results, 2)
+	for i, repo := range []string{"/repos/api", "/repos/slow"} {
+		assert.Equal(t, repo, results[i].Repository)
+		assert.NotEmpty(t, results[i].JobID)
+		assert.Equal(t, string(job.StatusQueued), results[i].Status, "jobs are not waited on")
+		assert.Equal(t, repo, jobStore.jobs[results[i].JobID].WorkingDirectory)
+	}
+}
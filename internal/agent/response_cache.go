@@ -0,0 +1,93 @@
+package agent
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/mule-ai/mule/internal/primitive"
+)
+
+// responseCache holds agent responses keyed by a hash of the request so that
+// identical prompts to agents that opt in are not sent to the model again. It
+// is safe for concurrent use.
+type responseCache struct {
+	mu      sync.Mutex
+	entries map[string]cachedResponse
+	now     func() time.Time
+}
+
+// cachedResponse is a response and the time it stops being served
+type cachedResponse struct {
+	resp      ChatCompletionResponse
+	expiresAt time.Time
+}
+
+// newResponseCache creates an empty cache
+func newResponseCache() *responseCache {
+	return &responseCache{entries: make(map[string]cachedResponse), now: time.Now}
+}
+
+// cacheTTL reads the "cache" object from an agent's pi_config. Caching is off
+// unless a positive TTL is set:
+//
+//	{"cache": {"ttl_seconds": 600}}
+func cacheTTL(agent *primitive.Agent) time.Duration {
+	cache, ok := agent.PIConfig["cache"].(map[string]interface{})
+	if !ok {
+		return 0
+	}
+	seconds := intValue(cache["ttl_seconds"])
+	if seconds <= 0 {
+		return 0
+	}
+	return time.Duration(seconds) * time.Second
+}
+
+// responseCacheKey hashes everything that determines an agent's response: its
+// configuration, the prompt, the tools and the working directory
+func responseCacheKey(agent *primitive.Agent, prompt, workingDir string, tools []string) string {
+	data, _ := json.Marshal(struct {
+		AgentID      string                 `json:"agent_id"`
+		ProviderID   string                 `json:"provider_id"`
+		ModelID      string                 `json:"model_id"`
+		SystemPrompt string                 `json:"system_prompt"`
+		PIConfig     map[string]interface{} `json:"pi_config"`
+		Prompt       string                 `json:"prompt"`
+		WorkingDir   string                 `json:"working_dir"`
+		Tools        []string               `json:"tools"`
+	}{agent.ID, agent.ProviderID, agent.ModelID, agent.SystemPrompt, agent.PIConfig, prompt, workingDir, tools})
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// get returns the unexpired response stored under key
+func (c *responseCache) get(key string) (*ChatCompletionResponse, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	if !c.now().Before(entry.expiresAt) {
+		delete(c.entries, key)
+		return nil, false
+	}
+	resp := entry.resp
+	return &resp, true
+}
+
+// set stores resp under key for ttl, dropping any expired entries
+func (c *responseCache) set(key string, resp *ChatCompletionResponse, ttl time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := c.now()
+	for k, entry := range c.entries {
+		if !now.Before(entry.expiresAt) {
+			delete(c.entries, k)
+		}
+	}
+	c.entries[key] = cachedResponse{resp: *resp, expiresAt: now.Add(ttl)}
+}
@@ -0,0 +1,109 @@
+package agent
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mule-ai/mule/internal/primitive"
+)
+
+// countingPI answers every prompt with the same reply and appends a line to
+// $PI_CALLS for each invocation
+const countingPI = `#!/bin/sh
+echo call >> "$PI_CALLS"
+read -r _
+printf '{"type":"agent_end","messages":[{"role":"assistant","content":[{"type":"text","text":"summary"}]}]}\n'
+exec cat >/dev/null
+`
+
+// installCountingPI puts countingPI first on PATH and returns a function
+// reporting how many times it has been invoked
+func installCountingPI(t *testing.T) func() int {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("stub pi is a shell script")
+	}
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "pi"), []byte(countingPI), 0o755))
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+	calls := filepath.Join(dir, "calls")
+	t.Setenv("PI_CALLS", calls)
+
+	return func() int {
+		data, err := os.ReadFile(calls)
+		if os.IsNotExist(err) {
+			return 0
+		}
+		require.NoError(t, err)
+		return strings.Count(string(data), "call")
+	}
+}
+
+func TestExecuteAgentCachesResponses(t *testing.T) {
+	calls := installCountingPI(t)
+
+	store := &MockAgentStore{agents: map[string]*primitive.Agent{
+		"cached":   {ID: "cached", Name: "Summarizer", PIConfig: map[string]interface{}{"cache": map[string]interface{}{"ttl_seconds": float64(60)}}},
+		"uncached": {ID: "uncached", Name: "Writer"},
+	}}
+	r := NewRuntime(store, &MockJobStore{})
+
+	ask := func(model, prompt string) *ChatCompletionResponse {
+		t.Helper()
+		resp, err := r.ExecuteAgent(context.Background(), &ChatCompletionRequest{
+			Model:    model,
+			Messages: []ChatCompletionMessage{{Role: "user", Content: prompt}},
+		})
+		require.NoError(t, err)
+		return resp
+	}
+
+	t.Run("identical prompt is served from the cache", func(t *testing.T) {
+		first := ask("agent/summarizer", "summarize this article")
+		second := ask("agent/summarizer", "summarize this article")
+
+		assert.Equal(t, 1, calls(), "model should only be invoked once")
+		assert.Equal(t, first.Choices[0].Message.Content, second.Choices[0].Message.Content)
+		assert.Zero(t, second.Usage.TotalTokens, "a cached response uses no tokens")
+	})
+
+	t.Run("different prompt is not cached", func(t *testing.T) {
+		ask("agent/summarizer", "summarize another article")
+		assert.Equal(t, 2, calls())
+	})
+
+	t.Run("agents without cache config are not cached", func(t *testing.T) {
+		ask("agent/writer", "write a poem")
+		ask("agent/writer", "write a poem")
+		assert.Equal(t, 4, calls())
+	})
+}
+
+func TestResponseCacheExpiry(t *testing.T) {
+	now := time.Now()
+	cache := newResponseCache()
+	cache.now = func() time.Time { return now }
+
+	cache.set("key", &ChatCompletionResponse{ID: "resp"}, time.Minute)
+	cached, ok := cache.get("key")
+	require.True(t, ok)
+	assert.Equal(t, "resp", cached.ID)
+
+	now = now.Add(time.Minute)
+	_, ok = cache.get("key")
+	assert.False(t, ok)
+}
+
+func TestCacheTTL(t *testing.T) {
+	assert.Zero(t, cacheTTL(&primitive.Agent{}))
+	assert.Zero(t, cacheTTL(&primitive.Agent{PIConfig: map[string]interface{}{"cache": map[string]interface{}{"ttl_seconds": float64(0)}}}))
+	assert.Equal(t, 90*time.Second, cacheTTL(&primitive.Agent{PIConfig: map[string]interface{}{"cache": map[string]interface{}{"ttl_seconds": float64(90)}}}))
+}
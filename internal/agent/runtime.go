@@ -20,6 +20,7 @@ type Runtime struct {
 	workflowEngine WorkflowEngine
 	jobStore       job.JobStore
 	toolRegistry   *tools.Registry
+	cache          *responseCache
 }
 
 // NewRuntime creates a new agent runtime
@@ -36,6 +37,7 @@ func NewRuntime(store primitive.PrimitiveStore, jobStore job.JobStore) *Runtime
 		store:        store,
 		jobStore:     jobStore,
 		toolRegistry: toolRegistry,
+		cache:        newResponseCache(),
 	}
 }
 
@@ -52,12 +54,29 @@ func (r *Runtime) ReinitializeMemoryTool() error {
 	return fmt.Errorf("tool registry not initialized")
 }
 
+// MemoryHealth returns nil if the memory store is available, or the error
+// that made it unavailable
+func (r *Runtime) MemoryHealth() error {
+	if r.toolRegistry == nil {
+		return fmt.Errorf("tool registry not initialized")
+	}
+	return r.toolRegistry.MemoryHealth()
+}
+
 // ChatCompletionRequest represents the OpenAI-compatible request
 type ChatCompletionRequest struct {
 	Model            string                  `json:"model"`
 	Messages         []ChatCompletionMessage `json:"messages"`
 	Stream           bool                    `json:"stream,omitempty"`
 	WorkingDirectory string                  `json:"working_directory,omitempty"`
+	// Repositories runs a workflow once per repository path instead of in
+	// WorkingDirectory, at most MaxConcurrency at a time
+	Repositories   []string `json:"repositories,omitempty"`
+	MaxConcurrency int      `json:"max_concurrency,omitempty"`
+	// SystemPrompt and Tools override the agent's configured system prompt
+	// and the pi tools it may use, for this request only
+	SystemPrompt string   `json:"system_prompt,omitempty"`
+	Tools        []string `json:"tools,omitempty"`
 }
 
 // ChatCompletionMessage represents a message in the chat
@@ -74,6 +93,9 @@ type ChatCompletionResponse struct {
 	Model   string                 `json:"model"`
 	Choices []ChatCompletionChoice `json:"choices"`
 	Usage   ChatCompletionUsage    `json:"usage"`
+	Steps   []StepMetrics          `json:"steps,omitempty"`
+	// DurationMs is the total duration of Steps, reported alongside them
+	DurationMs int64 `json:"duration_ms,omitempty"`
 }
 
 // ChatCompletionChoice represents a choice in the response
@@ -88,6 +110,9 @@ type ChatCompletionUsage struct {
 	PromptTokens     int `json:"prompt_tokens"`
 	CompletionTokens int `json:"completion_tokens"`
 	TotalTokens      int `json:"total_tokens"`
+	// Estimated is set when the counts were guessed from the text length
+	// because pi did not report usage
+	Estimated bool `json:"estimated,omitempty"`
 }
 
 // AsyncJobResponse represents an asynchronous job response
@@ -117,7 +142,7 @@ func (r *Runtime) ExecuteAgentWithWorkingDir(ctx context.Context, req *ChatCompl
 
 	var targetAgent *primitive.Agent
 	for _, agent := range agents {
-		if strings.ToLower(agent.Name) == agentName {
+		if strings.EqualFold(agent.Name, agentName) {
 			targetAgent = agent
 			break
 		}
@@ -127,6 +152,13 @@ func (r *Runtime) ExecuteAgentWithWorkingDir(ctx context.Context, req *ChatCompl
 		return nil, fmt.Errorf("agent '%s' not found", agentName)
 	}
 
+	// Apply per-request overrides to a copy so the stored agent is untouched
+	if req.SystemPrompt != "" {
+		overridden := *targetAgent
+		overridden.SystemPrompt = req.SystemPrompt
+		targetAgent = &overridden
+	}
+
 	// Concatenate messages for the prompt
 	var prompt strings.Builder
 	for _, msg := range req.Messages {
@@ -135,12 +167,43 @@ func (r *Runtime) ExecuteAgentWithWorkingDir(ctx context.Context, req *ChatCompl
 		}
 	}
 
+	// Serve identical requests to agents that opt in to caching without
+	// calling the model or charging the budget
+	ttl := cacheTTL(targetAgent)
+	var cacheKey string
+	if ttl > 0 && r.cache != nil {
+		cacheKey = responseCacheKey(targetAgent, prompt.String(), workingDir, req.Tools)
+		if cached, ok := r.cache.get(cacheKey); ok {
+			log.Printf("Serving cached response for agent %s", targetAgent.Name)
+			cached.Usage = ChatCompletionUsage{}
+			return cached, nil
+		}
+	}
+
+	// Charge the call to the run's budget and to the agent's own budget
+	budget := budgetFromContext(ctx)
+	agentLimits := LoadBudgetLimits(targetAgent.PIConfig)
+	if err := budget.reserve(targetAgent.ID, agentLimits); err != nil {
+		return nil, err
+	}
+
 	// Use pi for agent execution
-	return r.executeWithPI(ctx, targetAgent, prompt.String(), workingDir)
+	resp, err := r.executeWithPI(ctx, targetAgent, prompt.String(), workingDir, req.Tools)
+	if err != nil {
+		return nil, err
+	}
+	if err := budget.record(targetAgent.ID, agentLimits, resp.Usage); err != nil {
+		return nil, err
+	}
+	if cacheKey != "" {
+		r.cache.set(cacheKey, resp, ttl)
+	}
+	return resp, nil
 }
 
-// executeWithPI executes the agent using pi RPC
-func (r *Runtime) executeWithPI(ctx context.Context, agent *primitive.Agent, prompt string, workingDir string) (*ChatCompletionResponse, error) {
+// executeWithPI executes the agent using pi RPC. If tools is non-empty, pi
+// is limited to those tools.
+func (r *Runtime) executeWithPI(ctx context.Context, agent *primitive.Agent, prompt string, workingDir string, tools []string) (*ChatCompletionResponse, error) {
 	// Get provider information for API key and provider name
 	var apiKey string
 	var providerName string
@@ -186,6 +249,7 @@ func (r *Runtime) executeWithPI(ctx context.Context, agent *primitive.Agent, pro
 		SystemPrompt:     agent.SystemPrompt,
 		ThinkingLevel:    thinkingLevel,
 		Skills:           skillPaths,
+		Tools:            strings.Join(tools, ","),
 		WorkingDirectory: workingDir,
 		Timeout:          5 * time.Minute, // Default timeout
 	}
@@ -210,7 +274,11 @@ func (r *Runtime) executeWithPI(ctx context.Context, agent *primitive.Agent, pro
 
 	// Collect events and build response
 	var responseText string
+	var usage ChatCompletionUsage
+	hasUsage := false
 	timeout := time.After(cfg.Timeout)
+	toolLimit := newToolCallLimiter(agent, r.maxToolCalls(ctx))
+	toolLimitReached := false
 
 	// Use a labeled break to exit when agent finishes
 AgentLoop:
@@ -228,6 +296,16 @@ AgentLoop:
 			}
 			return nil, fmt.Errorf("agent execution timed out after %v", cfg.Timeout)
 		case event := <-bridge.Events():
+			// Abort a model that keeps requesting tools; pi still ends the run
+			// with agent_end, so whatever it has produced so far is returned
+			if toolLimit.observe(event.Type) {
+				log.Printf("Agent %s exceeded %d tool calls, aborting", agent.Name, toolLimit.max)
+				toolLimitReached = true
+				if err := bridge.Abort(ctx); err != nil {
+					log.Printf("failed to abort bridge: %v", err)
+				}
+			}
+
 			// Only extract response from agent_end - ignore intermediate events
 			// to avoid duplicate content
 			switch event.Type {
@@ -257,6 +335,10 @@ AgentLoop:
 						}
 					}
 				}
+				usage, hasUsage = piUsage(msgData)
+				if toolLimitReached {
+					responseText = strings.TrimSpace(responseText + "\n\n" + toolLimit.note())
+				}
 				// Agent has finished - we can break out and return the response
 				break AgentLoop
 			case "error":
@@ -307,11 +389,15 @@ AgentLoop:
 				FinishReason: "stop",
 			},
 		},
-		Usage: ChatCompletionUsage{
+		Usage: usage,
+	}
+	if !hasUsage {
+		chatResp.Usage = ChatCompletionUsage{
 			PromptTokens:     estimateTokens(prompt),
 			CompletionTokens: estimateTokens(responseText),
 			TotalTokens:      estimateTokens(prompt) + estimateTokens(responseText),
-		},
+			Estimated:        true,
+		}
 	}
 
 	return chatResp, nil
@@ -0,0 +1,65 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/mule-ai/mule/internal/primitive"
+)
+
+// defaultMaxToolCalls caps how many tools a single agent generation may call
+// when neither the max_tool_calls setting nor the agent's pi_config sets it
+const defaultMaxToolCalls = 10
+
+// toolCallLimiter counts the tool calls pi reports during one generation so
+// a model that keeps requesting tools can be stopped
+type toolCallLimiter struct {
+	max   int
+	count int
+}
+
+// maxToolCalls returns the max_tool_calls setting, falling back to
+// defaultMaxToolCalls when it is unset or not a positive number
+func (r *Runtime) maxToolCalls(ctx context.Context) int {
+	if setting, err := r.store.GetSetting(ctx, "max_tool_calls"); err == nil {
+		if limit, err := strconv.Atoi(setting.Value); err == nil && limit > 0 {
+			return limit
+		}
+	}
+	return defaultMaxToolCalls
+}
+
+// newToolCallLimiter reads max_tool_calls from the agent's pi_config, falling
+// back to limit when it is unset or not a positive number
+func newToolCallLimiter(agent *primitive.Agent, limit int) *toolCallLimiter {
+	if agent.PIConfig != nil {
+		switch v := agent.PIConfig["max_tool_calls"].(type) {
+		case float64:
+			if v > 0 {
+				limit = int(v)
+			}
+		case int:
+			if v > 0 {
+				limit = v
+			}
+		}
+	}
+	return &toolCallLimiter{max: limit}
+}
+
+// observe records an event from pi and reports whether the tool call limit
+// has just been exceeded. It returns true once, for the first call over the
+// limit.
+func (l *toolCallLimiter) observe(eventType string) bool {
+	if eventType != "tool_execution_start" {
+		return false
+	}
+	l.count++
+	return l.count == l.max+1
+}
+
+// note explains why the generation was stopped early
+func (l *toolCallLimiter) note() string {
+	return fmt.Sprintf("[Stopped after %d tool calls: the agent's tool call limit was reached]", l.max)
+}
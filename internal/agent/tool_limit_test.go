@@ -0,0 +1,54 @@
+package agent
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mule-ai/mule/internal/primitive"
+)
+
+func TestNewToolCallLimiter(t *testing.T) {
+	tests := []struct {
+		name     string
+		piConfig map[string]interface{}
+		want     int
+	}{
+		{name: "no pi_config", want: 25},
+		{name: "unset", piConfig: map[string]interface{}{"thinking_level": "low"}, want: 25},
+		{name: "from JSON", piConfig: map[string]interface{}{"max_tool_calls": float64(5)}, want: 5},
+		{name: "int", piConfig: map[string]interface{}{"max_tool_calls": 7}, want: 7},
+		{name: "zero", piConfig: map[string]interface{}{"max_tool_calls": float64(0)}, want: 25},
+		{name: "wrong type", piConfig: map[string]interface{}{"max_tool_calls": "5"}, want: 25},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			limiter := newToolCallLimiter(&primitive.Agent{PIConfig: tt.piConfig}, 25)
+			assert.Equal(t, tt.want, limiter.max)
+		})
+	}
+}
+
+func TestRuntimeMaxToolCallsDefault(t *testing.T) {
+	runtime := &Runtime{store: &MockAgentStore{}}
+	assert.Equal(t, defaultMaxToolCalls, runtime.maxToolCalls(context.Background()))
+}
+
+func TestToolCallLimiterStopsAtCap(t *testing.T) {
+	limiter := newToolCallLimiter(&primitive.Agent{PIConfig: map[string]interface{}{"max_tool_calls": float64(3)}}, defaultMaxToolCalls)
+
+	// A model that always requests another tool
+	var trippedAt []int
+	for call := 1; call <= 10; call++ {
+		assert.False(t, limiter.observe("message_update"))
+		if limiter.observe("tool_execution_start") {
+			trippedAt = append(trippedAt, call)
+		}
+		assert.False(t, limiter.observe("tool_execution_done"))
+	}
+
+	assert.Equal(t, []int{4}, trippedAt, "limit should trip once, on the first call over the cap")
+	assert.Contains(t, limiter.note(), "3 tool calls")
+}
@@ -0,0 +1,109 @@
+package agent
+
+import "encoding/json"
+
+// StepMetrics reports how long a workflow step took and how many tokens it used.
+// It is included in chat completion responses when verbose output is requested.
+type StepMetrics struct {
+	StepOrder      int                  `json:"step_order"`
+	WorkflowStepID string               `json:"workflow_step_id,omitempty"`
+	Status         string               `json:"status"`
+	DurationMs     int64                `json:"duration_ms"`
+	Usage          *ChatCompletionUsage `json:"usage,omitempty"`
+}
+
+// TotalDurationMs returns the combined duration of the given steps
+func TotalDurationMs(steps []StepMetrics) int64 {
+	var total int64
+	for _, step := range steps {
+		total += step.DurationMs
+	}
+	return total
+}
+
+// Add returns the sum of two usage counts
+func (u ChatCompletionUsage) Add(other ChatCompletionUsage) ChatCompletionUsage {
+	return ChatCompletionUsage{
+		PromptTokens:     u.PromptTokens + other.PromptTokens,
+		CompletionTokens: u.CompletionTokens + other.CompletionTokens,
+		TotalTokens:      u.TotalTokens + other.TotalTokens,
+		Estimated:        u.Estimated || other.Estimated,
+	}
+}
+
+// ToMap converts the usage to the form stored in job output data
+func (u ChatCompletionUsage) ToMap() map[string]interface{} {
+	m := map[string]interface{}{
+		"prompt_tokens":     u.PromptTokens,
+		"completion_tokens": u.CompletionTokens,
+		"total_tokens":      u.TotalTokens,
+	}
+	if u.Estimated {
+		m["estimated"] = true
+	}
+	return m
+}
+
+// UsageFromMap reads usage from job output data. Values may be ints when the
+// data comes straight from a step, or float64 once it has been through JSON.
+// It returns false if v does not hold any token counts.
+func UsageFromMap(v interface{}) (ChatCompletionUsage, bool) {
+	var usage ChatCompletionUsage
+
+	m, ok := v.(map[string]interface{})
+	if !ok {
+		return usage, false
+	}
+
+	found := false
+	for key, dst := range map[string]*int{
+		"prompt_tokens":     &usage.PromptTokens,
+		"completion_tokens": &usage.CompletionTokens,
+		"total_tokens":      &usage.TotalTokens,
+	} {
+		switch n := m[key].(type) {
+		case int:
+			*dst = n
+			found = true
+		case float64:
+			*dst = int(n)
+			found = true
+		}
+	}
+
+	usage.Estimated, _ = m["estimated"].(bool)
+
+	return usage, found
+}
+
+// piUsage totals the usage pi reports on the assistant messages of an
+// agent_end event. Cached prompt tokens count as prompt tokens. It returns
+// false if no message carries usage.
+func piUsage(messages json.RawMessage) (ChatCompletionUsage, bool) {
+	var parsed []struct {
+		Role  string `json:"role"`
+		Usage *struct {
+			Input      int `json:"input"`
+			Output     int `json:"output"`
+			CacheRead  int `json:"cacheRead"`
+			CacheWrite int `json:"cacheWrite"`
+		} `json:"usage"`
+	}
+	if err := json.Unmarshal(messages, &parsed); err != nil {
+		return ChatCompletionUsage{}, false
+	}
+
+	var usage ChatCompletionUsage
+	found := false
+	for _, m := range parsed {
+		if m.Role != "assistant" || m.Usage == nil {
+			continue
+		}
+		usage.PromptTokens += m.Usage.Input + m.Usage.CacheRead + m.Usage.CacheWrite
+		usage.CompletionTokens += m.Usage.Output
+		found = true
+	}
+	usage.TotalTokens = usage.PromptTokens + usage.CompletionTokens
+
+	return usage, found
+}
@@ -0,0 +1,64 @@
+package agent
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUsageFromMap(t *testing.T) {
+	usage, ok := UsageFromMap(map[string]interface{}{"prompt_tokens": float64(2), "completion_tokens": 3, "total_tokens": float64(5)})
+	assert.True(t, ok)
+	assert.Equal(t, ChatCompletionUsage{PromptTokens: 2, CompletionTokens: 3, TotalTokens: 5}, usage)
+
+	_, ok = UsageFromMap(map[string]interface{}{"other": 1})
+	assert.False(t, ok)
+
+	_, ok = UsageFromMap(nil)
+	assert.False(t, ok)
+
+	total := usage.Add(ChatCompletionUsage{PromptTokens: 1, CompletionTokens: 1, TotalTokens: 2})
+	assert.Equal(t, ChatCompletionUsage{PromptTokens: 3, CompletionTokens: 4, TotalTokens: 7}, total)
+	assert.Equal(t, 7, total.ToMap()["total_tokens"])
+}
+
+func TestUsageEstimatedRoundTrip(t *testing.T) {
+	estimated := ChatCompletionUsage{PromptTokens: 10, CompletionTokens: 5, TotalTokens: 15, Estimated: true}
+	m := estimated.ToMap()
+	assert.Equal(t, true, m["estimated"])
+
+	usage, ok := UsageFromMap(m)
+	assert.True(t, ok)
+	assert.Equal(t, estimated, usage)
+
+	_, reported := ChatCompletionUsage{TotalTokens: 1}.ToMap()["estimated"]
+	assert.False(t, reported, "reported usage is not marked")
+	assert.True(t, ChatCompletionUsage{}.Add(estimated).Estimated, "a total including an estimate is an estimate")
+}
+
+func TestPiUsage(t *testing.T) {
+	messages := []byte(`[
+		{"role":"user","content":[{"type":"text","text":"fix the bug"}]},
+		{"role":"assistant","content":[{"type":"toolCall","name":"bash"}],"usage":{"input":1200,"output":80,"cacheRead":300,"cacheWrite":0,"totalTokens":1580}},
+		{"role":"toolResult","content":[{"type":"text","text":"ok"}]},
+		{"role":"assistant","content":[{"type":"text","text":"Done"}],"usage":{"input":1400,"output":20,"cacheRead":0,"cacheWrite":100,"totalTokens":1520}}
+	]`)
+	usage, ok := piUsage(messages)
+	assert.True(t, ok)
+	assert.Equal(t, ChatCompletionUsage{PromptTokens: 3000, CompletionTokens: 100, TotalTokens: 3100}, usage)
+
+	_, ok = piUsage([]byte(`[{"role":"assistant","content":[{"type":"text","text":"Done"}]}]`))
+	assert.False(t, ok, "messages without usage")
+
+	_, ok = piUsage([]byte(`not json`))
+	assert.False(t, ok)
+}
+
+func TestTotalDurationMs(t *testing.T) {
+	steps := []StepMetrics{
+		{StepOrder: 1, Status: "completed", DurationMs: 1200},
+		{StepOrder: 2, Status: "completed", DurationMs: 350},
+	}
+	assert.Equal(t, int64(1550), TotalDurationMs(steps))
+	assert.Equal(t, int64(0), TotalDurationMs(nil))
+}
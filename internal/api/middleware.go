@@ -8,11 +8,15 @@ import (
 	"log"
 	"net"
 	"net/http"
+	"net/url"
 	"strings"
 	"time"
 
+	"github.com/gorilla/mux"
+
 	"github.com/mule-ai/mule/internal/primitive"
 	"github.com/mule-ai/mule/internal/validation"
+	"github.com/mule-ai/mule/pkg/audit"
 )
 
 // ErrorResponse represents an error response
@@ -37,20 +41,113 @@ func LoggingMiddleware(next http.Handler) http.Handler {
 	})
 }
 
-// CORSMiddleware adds CORS headers
-func CORSMiddleware(next http.Handler) http.Handler {
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		w.Header().Set("Access-Control-Allow-Origin", "*")
-		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
+// CORSConfig holds the cross-origin policy applied by NewCORSMiddleware
+type CORSConfig struct {
+	AllowedOrigins   []string
+	AllowedMethods   []string
+	AllowedHeaders   []string
+	AllowCredentials bool
+}
 
-		if r.Method == "OPTIONS" {
-			w.WriteHeader(http.StatusOK)
-			return
+// DefaultCORSConfig returns the default policy: no cross-origin access, so only
+// same-origin pages such as the embedded frontend can call the API
+func DefaultCORSConfig() CORSConfig {
+	return CORSConfig{
+		AllowedMethods: []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
+		AllowedHeaders: []string{"Content-Type", "Authorization"},
+	}
+}
+
+// ParseCORSList splits a comma separated settings value into a trimmed list
+func ParseCORSList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
 		}
+	}
+	return items
+}
 
-		next.ServeHTTP(w, r)
-	})
+// Validate checks the configuration for unsafe or malformed entries. An empty
+// origin list is valid and allows same-origin requests only.
+func (c CORSConfig) Validate() error {
+	for _, origin := range c.AllowedOrigins {
+		if origin == "*" {
+			if c.AllowCredentials {
+				return fmt.Errorf("wildcard origin cannot be used with credentials")
+			}
+			continue
+		}
+		u, err := url.Parse(origin)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" ||
+			(u.Path != "" && u.Path != "/") || u.RawQuery != "" || u.Fragment != "" {
+			return fmt.Errorf("invalid allowed origin: %q", origin)
+		}
+	}
+	if len(c.AllowedMethods) == 0 {
+		return fmt.Errorf("at least one allowed method is required")
+	}
+	return nil
+}
+
+// allowOrigin returns the value for Access-Control-Allow-Origin, or an empty
+// string if the request origin is not permitted
+func (c CORSConfig) allowOrigin(origin string) string {
+	for _, allowed := range c.AllowedOrigins {
+		if allowed == "*" {
+			return "*"
+		}
+		if origin != "" && strings.EqualFold(strings.TrimSuffix(allowed, "/"), origin) {
+			return origin
+		}
+	}
+	return ""
+}
+
+// NewCORSMiddleware adds CORS headers according to the given configuration.
+// The configuration should be checked with Validate before use.
+func NewCORSMiddleware(config CORSConfig) func(http.Handler) http.Handler {
+	methods := strings.Join(config.AllowedMethods, ", ")
+	headers := strings.Join(config.AllowedHeaders, ", ")
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			origin := r.Header.Get("Origin")
+			allowed := config.allowOrigin(origin)
+
+			if allowed != "*" {
+				w.Header().Add("Vary", "Origin")
+			}
+
+			if allowed != "" {
+				w.Header().Set("Access-Control-Allow-Origin", allowed)
+				w.Header().Set("Access-Control-Allow-Methods", methods)
+				w.Header().Set("Access-Control-Allow-Headers", headers)
+				if config.AllowCredentials {
+					w.Header().Set("Access-Control-Allow-Credentials", "true")
+				}
+			}
+
+			// Only browser preflights carry an Origin; other OPTIONS
+			// requests are answered as before
+			if r.Method == "OPTIONS" {
+				if allowed == "" && origin != "" {
+					w.WriteHeader(http.StatusForbidden)
+					return
+				}
+				w.WriteHeader(http.StatusOK)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// CORSMiddleware adds CORS headers using the default configuration
+func CORSMiddleware(next http.Handler) http.Handler {
+	return NewCORSMiddleware(DefaultCORSConfig())(next)
 }
 
 // TimeoutMiddleware adds a timeout to requests with configurable duration
@@ -143,6 +240,69 @@ func TimeoutMiddleware(getTimeoutFunc func() time.Duration) func(http.Handler) h
 	}
 }
 
+// AuditActorHeader carries the identity a client claims for audit entries.
+// There is no built-in authentication, so the value is recorded as
+// claimed_actor alongside the client address rather than trusted as the actor.
+const AuditActorHeader = "X-Actor"
+
+// auditActor identifies who performed a request by its client address
+func auditActor(r *http.Request) string {
+	if host, _, err := net.SplitHostPort(r.RemoteAddr); err == nil {
+		return host
+	}
+	return r.RemoteAddr
+}
+
+// AuditMiddleware records mutating requests (POST, PUT, PATCH, DELETE) in the audit log
+func AuditMiddleware(store audit.Store) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch r.Method {
+			case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+			default:
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			wrapped := &responseWriter{ResponseWriter: w, statusCode: http.StatusOK}
+			defer func() {
+				statusCode := wrapped.statusCode
+				p := recover()
+				if p != nil {
+					statusCode = http.StatusInternalServerError
+				}
+
+				// Use the route template so actions group together regardless of IDs
+				action := r.URL.Path
+				if route := mux.CurrentRoute(r); route != nil {
+					if tmpl, err := route.GetPathTemplate(); err == nil {
+						action = tmpl
+					}
+				}
+
+				entry := &audit.Entry{
+					Actor:        auditActor(r),
+					ClaimedActor: strings.TrimSpace(r.Header.Get(AuditActorHeader)),
+					Action:       r.Method + " " + action,
+					Target:       r.URL.Path,
+					StatusCode:   statusCode,
+					Outcome:      audit.OutcomeForStatus(statusCode),
+				}
+				if err := store.Record(entry); err != nil {
+					log.Printf("Warning: failed to record audit entry for %s %s: %v", r.Method, r.URL.Path, err)
+				}
+
+				// Let RecoveryMiddleware handle the panic after it has been audited
+				if p != nil {
+					panic(p)
+				}
+			}()
+
+			next.ServeHTTP(wrapped, r)
+		})
+	}
+}
+
 // RecoveryMiddleware recovers from panics
 func RecoveryMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -12,6 +12,7 @@ import (
 	"time"
 
 	"github.com/mule-ai/mule/internal/validation"
+	"github.com/mule-ai/mule/pkg/audit"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -64,13 +65,65 @@ func TestLoggingMiddleware(t *testing.T) {
 }
 
 func TestCORSMiddleware(t *testing.T) {
-	t.Run("adds CORS headers", func(t *testing.T) {
+	t.Run("default allows no cross-origin access", func(t *testing.T) {
 		handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			w.WriteHeader(http.StatusOK)
 		})
 
 		middleware := CORSMiddleware(handler)
 
+		req := httptest.NewRequest("GET", "/test", nil)
+		req.Header.Set("Origin", "https://other.example.com")
+		rec := httptest.NewRecorder()
+
+		middleware.ServeHTTP(rec, req)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Empty(t, rec.Header().Get("Access-Control-Allow-Origin"))
+		assert.Empty(t, rec.Header().Get("Access-Control-Allow-Methods"))
+	})
+
+	t.Run("default rejects cross-origin preflight", func(t *testing.T) {
+		handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			t.Error("Handler should not be called for OPTIONS")
+		})
+
+		middleware := CORSMiddleware(handler)
+
+		req := httptest.NewRequest("OPTIONS", "/test", nil)
+		req.Header.Set("Origin", "https://other.example.com")
+		rec := httptest.NewRecorder()
+
+		middleware.ServeHTTP(rec, req)
+
+		assert.Equal(t, http.StatusForbidden, rec.Code)
+		assert.Empty(t, rec.Header().Get("Access-Control-Allow-Origin"))
+	})
+
+	t.Run("answers OPTIONS without Origin", func(t *testing.T) {
+		handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			t.Error("Handler should not be called for OPTIONS")
+		})
+
+		middleware := CORSMiddleware(handler)
+
+		req := httptest.NewRequest("OPTIONS", "/test", nil)
+		rec := httptest.NewRecorder()
+
+		middleware.ServeHTTP(rec, req)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+	})
+
+	t.Run("wildcard adds CORS headers", func(t *testing.T) {
+		handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusOK)
+		})
+
+		config := DefaultCORSConfig()
+		config.AllowedOrigins = []string{"*"}
+		middleware := NewCORSMiddleware(config)(handler)
+
 		req := httptest.NewRequest("GET", "/test", nil)
 		rec := httptest.NewRecorder()
 
@@ -82,21 +135,101 @@ func TestCORSMiddleware(t *testing.T) {
 		assert.Contains(t, rec.Header().Get("Access-Control-Allow-Methods"), "POST")
 		assert.Contains(t, rec.Header().Get("Access-Control-Allow-Headers"), "Content-Type")
 	})
+}
 
-	t.Run("handles OPTIONS preflight", func(t *testing.T) {
+func TestNewCORSMiddleware(t *testing.T) {
+	config := CORSConfig{
+		AllowedOrigins:   []string{"https://app.example.com"},
+		AllowedMethods:   []string{"GET", "POST"},
+		AllowedHeaders:   []string{"Content-Type", "Authorization"},
+		AllowCredentials: true,
+	}
+	assert.NoError(t, config.Validate())
+
+	t.Run("allows configured origin", func(t *testing.T) {
 		handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			t.Error("Handler should not be called for OPTIONS")
+			w.WriteHeader(http.StatusOK)
 		})
 
-		middleware := CORSMiddleware(handler)
+		middleware := NewCORSMiddleware(config)(handler)
+
+		req := httptest.NewRequest("GET", "/test", nil)
+		req.Header.Set("Origin", "https://app.example.com")
+		rec := httptest.NewRecorder()
+
+		middleware.ServeHTTP(rec, req)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "https://app.example.com", rec.Header().Get("Access-Control-Allow-Origin"))
+		assert.Equal(t, "true", rec.Header().Get("Access-Control-Allow-Credentials"))
+		assert.Equal(t, "GET, POST", rec.Header().Get("Access-Control-Allow-Methods"))
+		assert.Equal(t, "Origin", rec.Header().Get("Vary"))
+	})
+
+	t.Run("rejects origin not in list", func(t *testing.T) {
+		handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			t.Error("Handler should not be called for rejected preflight")
+		})
+
+		middleware := NewCORSMiddleware(config)(handler)
 
 		req := httptest.NewRequest("OPTIONS", "/test", nil)
+		req.Header.Set("Origin", "https://evil.example.com")
+		rec := httptest.NewRecorder()
+
+		middleware.ServeHTTP(rec, req)
+
+		assert.Equal(t, http.StatusForbidden, rec.Code)
+		assert.Empty(t, rec.Header().Get("Access-Control-Allow-Origin"))
+		assert.Empty(t, rec.Header().Get("Access-Control-Allow-Credentials"))
+	})
+
+	t.Run("omits headers for simple request from unlisted origin", func(t *testing.T) {
+		handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusOK)
+		})
+
+		middleware := NewCORSMiddleware(config)(handler)
+
+		req := httptest.NewRequest("GET", "/test", nil)
+		req.Header.Set("Origin", "https://evil.example.com")
 		rec := httptest.NewRecorder()
 
 		middleware.ServeHTTP(rec, req)
 
 		assert.Equal(t, http.StatusOK, rec.Code)
-		assert.Equal(t, "*", rec.Header().Get("Access-Control-Allow-Origin"))
+		assert.Empty(t, rec.Header().Get("Access-Control-Allow-Origin"))
+	})
+}
+
+func TestCORSConfigValidate(t *testing.T) {
+	t.Run("refuses wildcard with credentials", func(t *testing.T) {
+		config := DefaultCORSConfig()
+		config.AllowedOrigins = []string{"*"}
+		config.AllowCredentials = true
+
+		err := config.Validate()
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "wildcard")
+	})
+
+	t.Run("accepts default config", func(t *testing.T) {
+		assert.NoError(t, DefaultCORSConfig().Validate())
+	})
+
+	t.Run("rejects malformed origins", func(t *testing.T) {
+		for _, origin := range []string{"app.example.com", "ftp://example.com", "https://example.com/path"} {
+			config := DefaultCORSConfig()
+			config.AllowedOrigins = []string{origin}
+			assert.Error(t, config.Validate(), origin)
+		}
+	})
+
+	t.Run("accepts empty origin list as same-origin only", func(t *testing.T) {
+		config := DefaultCORSConfig()
+		config.AllowedOrigins = ParseCORSList(" , ")
+		assert.NoError(t, config.Validate())
+		assert.Empty(t, config.AllowedOrigins)
 	})
 }
 
@@ -171,6 +304,81 @@ func TestTimeoutMiddleware(t *testing.T) {
 	})
 }
 
+type recordingAuditStore struct {
+	entries []*audit.Entry
+}
+
+func (s *recordingAuditStore) Record(entry *audit.Entry) error {
+	s.entries = append(s.entries, entry)
+	return nil
+}
+
+func (s *recordingAuditStore) List(opts audit.ListOptions) ([]*audit.Entry, int, error) {
+	return s.entries, len(s.entries), nil
+}
+
+func TestAuditMiddleware(t *testing.T) {
+	t.Run("skips read requests", func(t *testing.T) {
+		store := &recordingAuditStore{}
+		handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusOK)
+		})
+
+		req := httptest.NewRequest("GET", "/api/v1/workflows", nil)
+		AuditMiddleware(store)(handler).ServeHTTP(httptest.NewRecorder(), req)
+
+		assert.Empty(t, store.entries)
+	})
+
+	t.Run("records failed request with client address", func(t *testing.T) {
+		store := &recordingAuditStore{}
+		handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusBadRequest)
+		})
+
+		req := httptest.NewRequest("PUT", "/api/v1/settings/foo", nil)
+		req.RemoteAddr = "10.0.0.5:1234"
+		AuditMiddleware(store)(handler).ServeHTTP(httptest.NewRecorder(), req)
+
+		assert.Len(t, store.entries, 1)
+		assert.Equal(t, "10.0.0.5", store.entries[0].Actor)
+		assert.Equal(t, "PUT /api/v1/settings/foo", store.entries[0].Action)
+		assert.Equal(t, audit.OutcomeFailure, store.entries[0].Outcome)
+	})
+
+	t.Run("keeps client address as actor when X-Actor is sent", func(t *testing.T) {
+		store := &recordingAuditStore{}
+		handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusOK)
+		})
+
+		req := httptest.NewRequest("POST", "/api/v1/workflows", nil)
+		req.RemoteAddr = "10.0.0.5:1234"
+		req.Header.Set(AuditActorHeader, "admin")
+		AuditMiddleware(store)(handler).ServeHTTP(httptest.NewRecorder(), req)
+
+		assert.Len(t, store.entries, 1)
+		assert.Equal(t, "10.0.0.5", store.entries[0].Actor)
+		assert.Equal(t, "admin", store.entries[0].ClaimedActor)
+	})
+
+	t.Run("records panicking request as failure", func(t *testing.T) {
+		store := &recordingAuditStore{}
+		handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			panic("boom")
+		})
+
+		req := httptest.NewRequest("DELETE", "/api/v1/jobs/1", nil)
+		rec := httptest.NewRecorder()
+		RecoveryMiddleware(AuditMiddleware(store)(handler)).ServeHTTP(rec, req)
+
+		assert.Equal(t, http.StatusInternalServerError, rec.Code)
+		assert.Len(t, store.entries, 1)
+		assert.Equal(t, http.StatusInternalServerError, store.entries[0].StatusCode)
+		assert.Equal(t, audit.OutcomeFailure, store.entries[0].Outcome)
+	})
+}
+
 func TestRecoveryMiddleware(t *testing.T) {
 	t.Run("recovers from panic", func(t *testing.T) {
 		handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -0,0 +1,16 @@
+-- CORS settings, read once at API server startup
+INSERT INTO settings (id, key, value, description, category)
+VALUES ('cors_allowed_origins', 'cors_allowed_origins', '', 'Comma separated list of allowed CORS origins; empty allows same-origin requests only (use explicit origins when credentials are enabled)', 'api')
+ON CONFLICT (key) DO NOTHING;
+
+INSERT INTO settings (id, key, value, description, category)
+VALUES ('cors_allowed_methods', 'cors_allowed_methods', 'GET, POST, PUT, DELETE, OPTIONS', 'Comma separated list of allowed CORS methods', 'api')
+ON CONFLICT (key) DO NOTHING;
+
+INSERT INTO settings (id, key, value, description, category)
+VALUES ('cors_allowed_headers', 'cors_allowed_headers', 'Content-Type, Authorization', 'Comma separated list of allowed CORS request headers', 'api')
+ON CONFLICT (key) DO NOTHING;
+
+INSERT INTO settings (id, key, value, description, category)
+VALUES ('cors_allow_credentials', 'cors_allow_credentials', 'false', 'Allow credentialed CORS requests (requires explicit origins)', 'api')
+ON CONFLICT (key) DO NOTHING;
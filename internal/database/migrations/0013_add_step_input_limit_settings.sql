@@ -0,0 +1,11 @@
+-- Default maximum size of a step's input (1 MiB); 0 disables the limit.
+-- Individual steps can override this with max_input_bytes in their config.
+INSERT INTO settings (id, key, value, description, category)
+VALUES ('step_max_input_bytes', 'step_max_input_bytes', '1048576', 'Maximum size in bytes of the input passed to a workflow step (0 disables the limit)', 'engine')
+ON CONFLICT (key) DO NOTHING;
+
+-- What to do when a step input exceeds the limit: truncate or fail.
+-- Individual steps can override this with input_size_policy in their config.
+INSERT INTO settings (id, key, value, description, category)
+VALUES ('step_input_size_policy', 'step_input_size_policy', 'truncate', 'Action when a step input exceeds the size limit: truncate or fail', 'engine')
+ON CONFLICT (key) DO NOTHING;
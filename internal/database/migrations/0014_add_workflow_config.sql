@@ -0,0 +1,2 @@
+-- Workflow-level execution options (e.g. result archival)
+ALTER TABLE workflows ADD COLUMN IF NOT EXISTS config JSONB NOT NULL DEFAULT '{}';
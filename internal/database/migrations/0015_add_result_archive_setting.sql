@@ -0,0 +1,5 @@
+-- Default directory for archived workflow results. Archival is enabled per
+-- workflow with {"archive": {"enabled": true}} in the workflow config.
+INSERT INTO settings (id, key, value, description, category)
+VALUES ('result_archive_dir', 'result_archive_dir', '', 'Directory where archived workflow results are written', 'engine')
+ON CONFLICT (key) DO NOTHING;
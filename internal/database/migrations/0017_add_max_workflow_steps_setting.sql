@@ -0,0 +1,5 @@
+-- Maximum number of steps a single job may run, including steps inside
+-- subworkflows; 0 disables the cap.
+INSERT INTO settings (id, key, value, description, category)
+VALUES ('max_workflow_steps', 'max_workflow_steps', '1000', 'Maximum number of steps a job may run, including nested subworkflow steps (0 disables the cap)', 'engine')
+ON CONFLICT (key) DO NOTHING;
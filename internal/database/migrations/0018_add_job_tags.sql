@@ -0,0 +1,4 @@
+-- Metadata tags on jobs (workflow, repository, trigger source) for filtering
+ALTER TABLE jobs ADD COLUMN IF NOT EXISTS tags JSONB NOT NULL DEFAULT '{}';
+
+CREATE INDEX IF NOT EXISTS idx_jobs_tags ON jobs USING GIN (tags);
@@ -0,0 +1,4 @@
+-- Allow skipped job steps, recorded when a step's condition is not met
+ALTER TABLE job_steps DROP CONSTRAINT IF EXISTS job_steps_status_check;
+ALTER TABLE job_steps ADD CONSTRAINT job_steps_status_check
+    CHECK (status IN ('queued', 'running', 'completed', 'failed', 'skipped'));
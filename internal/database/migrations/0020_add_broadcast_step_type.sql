@@ -0,0 +1,4 @@
+-- Allow broadcast steps, which fan one input out to several destinations
+ALTER TABLE workflow_steps DROP CONSTRAINT IF EXISTS workflow_steps_step_type_check;
+ALTER TABLE workflow_steps ADD CONSTRAINT workflow_steps_step_type_check
+    CHECK (step_type IN ('agent', 'wasm_module', 'subworkflow', 'broadcast'));
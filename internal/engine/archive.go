@@ -0,0 +1,133 @@
+package engine
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/mule-ai/mule/internal/primitive"
+)
+
+// archiveConfig controls result archival for a workflow. It is read from the
+// "archive" object in the workflow config:
+//
+//	{"archive": {"enabled": true, "directory": "team-a", "include_steps": true}}
+//
+// Archives are written under the result_archive_dir setting. A directory in
+// the workflow config names a subdirectory of it; since any API caller can set
+// workflow config, absolute paths and paths leading outside it are refused.
+type archiveConfig struct {
+	Enabled      bool
+	Directory    string
+	IncludeSteps bool
+}
+
+// archivedStep is the per-step output written to an archive when include_steps is set
+type archivedStep struct {
+	StepOrder      int                    `json:"step_order"`
+	WorkflowStepID string                 `json:"workflow_step_id"`
+	StepType       string                 `json:"type"`
+	Output         map[string]interface{} `json:"output"`
+}
+
+// archivedResult is the content of an archive file
+type archivedResult struct {
+	JobID        string                 `json:"job_id"`
+	WorkflowID   string                 `json:"workflow_id"`
+	WorkflowName string                 `json:"workflow_name"`
+	CompletedAt  time.Time              `json:"completed_at"`
+	Result       map[string]interface{} `json:"result"`
+	Steps        []archivedStep         `json:"steps,omitempty"`
+}
+
+// loadArchiveConfig reads the archive options for a workflow and resolves its
+// directory within the result_archive_dir setting
+func loadArchiveConfig(workflow *primitive.Workflow, settings []*primitive.Setting) (archiveConfig, error) {
+	var cfg archiveConfig
+
+	archive, ok := workflow.Config["archive"].(map[string]interface{})
+	if !ok {
+		return cfg, nil
+	}
+	cfg.Enabled, _ = archive["enabled"].(bool)
+	cfg.IncludeSteps, _ = archive["include_steps"].(bool)
+	subdir, _ := archive["directory"].(string)
+
+	var baseDir string
+	for _, setting := range settings {
+		if setting.Key == "result_archive_dir" {
+			baseDir = setting.Value
+			break
+		}
+	}
+
+	dir, err := resolveArchiveDir(baseDir, subdir)
+	if err != nil {
+		return archiveConfig{}, err
+	}
+	cfg.Directory = dir
+
+	return cfg, nil
+}
+
+// resolveArchiveDir joins a workflow's archive subdirectory onto the base
+// archive directory, refusing anything that would land outside it
+func resolveArchiveDir(baseDir, subdir string) (string, error) {
+	if subdir == "" {
+		return baseDir, nil
+	}
+	if baseDir == "" {
+		return "", fmt.Errorf("archive directory %q requires the result_archive_dir setting", subdir)
+	}
+	if filepath.IsAbs(subdir) || filepath.VolumeName(subdir) != "" {
+		return "", fmt.Errorf("archive directory %q must be relative to result_archive_dir", subdir)
+	}
+
+	base := filepath.Clean(baseDir)
+	dir := filepath.Join(base, subdir)
+	rel, err := filepath.Rel(base, dir)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("archive directory %q is outside result_archive_dir", subdir)
+	}
+
+	return dir, nil
+}
+
+// archiveFileName returns the archive file name for a job, keyed by job ID and
+// completion time so repeated runs never overwrite each other
+func archiveFileName(jobID string, completedAt time.Time) string {
+	return fmt.Sprintf("%s-%s.json", jobID, completedAt.UTC().Format("20060102T150405.000000000Z"))
+}
+
+// writeArchive writes the result to the archive directory and returns the file path
+func writeArchive(dir string, result *archivedResult) (string, error) {
+	if dir == "" {
+		return "", fmt.Errorf("no archive directory configured")
+	}
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create archive directory: %w", err)
+	}
+
+	data, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal archived result: %w", err)
+	}
+
+	path := filepath.Join(dir, archiveFileName(result.JobID, result.CompletedAt))
+
+	// Write to a temporary file first so readers never see a partial archive
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return "", fmt.Errorf("failed to write archive: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		_ = os.Remove(tmp)
+		return "", fmt.Errorf("failed to finalize archive: %w", err)
+	}
+
+	return path, nil
+}
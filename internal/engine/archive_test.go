@@ -0,0 +1,150 @@
+package engine
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mule-ai/mule/internal/agent"
+	"github.com/mule-ai/mule/internal/primitive"
+	"github.com/mule-ai/mule/pkg/job"
+)
+
+func TestProcessJobArchivesResult(t *testing.T) {
+	archiveDir := t.TempDir()
+
+	mockStore := &MockPrimitiveStore{
+		Workflows: []*primitive.Workflow{
+			{
+				ID:   "workflow-archived",
+				Name: "Archived Workflow",
+				Config: map[string]interface{}{
+					"archive": map[string]interface{}{
+						"enabled":   true,
+						"directory": "archived",
+					},
+				},
+			},
+			{ID: "workflow-plain", Name: "Plain Workflow"},
+		},
+		Settings: []*primitive.Setting{{Key: "result_archive_dir", Value: archiveDir}},
+	}
+	mockJobStore := &MockJobStore{
+		Jobs: map[string]*job.Job{
+			"job-archived": {
+				ID:         "job-archived",
+				WorkflowID: "workflow-archived",
+				Status:     job.StatusQueued,
+				InputData:  map[string]interface{}{"prompt": "archive me"},
+				CreatedAt:  time.Now(),
+			},
+			"job-plain": {
+				ID:         "job-plain",
+				WorkflowID: "workflow-plain",
+				Status:     job.StatusQueued,
+				InputData:  map[string]interface{}{"prompt": "do not archive"},
+				CreatedAt:  time.Now(),
+			},
+		},
+	}
+	agentRuntime := agent.NewRuntime(mockStore, mockJobStore)
+	engine := NewEngine(mockStore, mockJobStore, agentRuntime, NewWASMExecutor(nil, mockStore, agentRuntime, nil), Config{Workers: 1})
+
+	require.NoError(t, engine.processJob(context.Background(), "job-archived"))
+	require.NoError(t, engine.processJob(context.Background(), "job-plain"))
+
+	entries, err := os.ReadDir(filepath.Join(archiveDir, "archived"))
+	require.NoError(t, err)
+	require.Len(t, entries, 1, "only the workflow with archival enabled should be archived")
+
+	name := entries[0].Name()
+	assert.Regexp(t, regexp.MustCompile(`^job-archived-\d{8}T\d{6}\.\d{9}Z\.json$`), name)
+
+	data, err := os.ReadFile(filepath.Join(archiveDir, "archived", name))
+	require.NoError(t, err)
+
+	var archived archivedResult
+	require.NoError(t, json.Unmarshal(data, &archived))
+	assert.Equal(t, "job-archived", archived.JobID)
+	assert.Equal(t, "workflow-archived", archived.WorkflowID)
+	assert.Equal(t, "Archived Workflow", archived.WorkflowName)
+	assert.Equal(t, "archive me", archived.Result["prompt"])
+	assert.Empty(t, archived.Steps)
+}
+
+func TestLoadArchiveConfig(t *testing.T) {
+	settings := []*primitive.Setting{{Key: "result_archive_dir", Value: "/srv/results"}}
+
+	archiveIn := func(directory string) *primitive.Workflow {
+		return &primitive.Workflow{Config: map[string]interface{}{
+			"archive": map[string]interface{}{"enabled": true, "directory": directory},
+		}}
+	}
+
+	t.Run("disabled without archive config", func(t *testing.T) {
+		cfg, err := loadArchiveConfig(&primitive.Workflow{}, settings)
+		require.NoError(t, err)
+		assert.False(t, cfg.Enabled)
+	})
+
+	t.Run("falls back to setting directory", func(t *testing.T) {
+		workflow := &primitive.Workflow{Config: map[string]interface{}{
+			"archive": map[string]interface{}{"enabled": true, "include_steps": true},
+		}}
+		cfg, err := loadArchiveConfig(workflow, settings)
+		require.NoError(t, err)
+		assert.True(t, cfg.Enabled)
+		assert.True(t, cfg.IncludeSteps)
+		assert.Equal(t, "/srv/results", cfg.Directory)
+	})
+
+	t.Run("resolves workflow directory under setting directory", func(t *testing.T) {
+		cfg, err := loadArchiveConfig(archiveIn("team-a/nightly"), settings)
+		require.NoError(t, err)
+		assert.Equal(t, filepath.Join("/srv/results", "team-a", "nightly"), cfg.Directory)
+	})
+
+	t.Run("refuses paths outside setting directory", func(t *testing.T) {
+		for _, directory := range []string{"../etc", "team-a/../../etc", "..", "/etc/cron.d", "/srv/results"} {
+			cfg, err := loadArchiveConfig(archiveIn(directory), settings)
+			assert.Error(t, err, directory)
+			assert.False(t, cfg.Enabled, directory)
+		}
+	})
+
+	t.Run("refuses workflow directory without setting directory", func(t *testing.T) {
+		_, err := loadArchiveConfig(archiveIn("team-a"), nil)
+		assert.Error(t, err)
+	})
+}
+
+func TestWriteArchiveIncludesSteps(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "nested")
+	completedAt := time.Date(2026, 1, 2, 3, 4, 5, 6, time.UTC)
+
+	path, err := writeArchive(dir, &archivedResult{
+		JobID:       "job-1",
+		CompletedAt: completedAt,
+		Result:      map[string]interface{}{"prompt": "final"},
+		Steps: []archivedStep{
+			{StepOrder: 1, WorkflowStepID: "step-1", StepType: "agent", Output: map[string]interface{}{"prompt": "first"}},
+		},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(dir, "job-1-20260102T030405.000000006Z.json"), path)
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `"step_order": 1`)
+	assert.Contains(t, string(data), `"prompt": "first"`)
+
+	_, err = writeArchive("", &archivedResult{JobID: "job-2"})
+	assert.Error(t, err)
+}
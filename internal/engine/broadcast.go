@@ -0,0 +1,256 @@
+package engine
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mule-ai/mule/internal/agent"
+	"github.com/mule-ai/mule/internal/primitive"
+)
+
+// webhookTimeout bounds each webhook delivered by a broadcast step
+const webhookTimeout = 30 * time.Second
+
+// broadcastDestination is one target of a broadcast step: either a nested
+// step (agent, WASM module or subworkflow) or a webhook URL
+type broadcastDestination struct {
+	Name string
+	Step *primitive.WorkflowStep
+	URL  string
+}
+
+// broadcastOutcome is the result of delivering to one destination
+type broadcastOutcome struct {
+	Status string
+	Output map[string]interface{}
+	Error  string
+}
+
+// toMap converts the outcome to the form stored in the step output, so step
+// conditions can refer to fields such as broadcast.<name>.status
+func (o broadcastOutcome) toMap() map[string]interface{} {
+	m := map[string]interface{}{"status": o.Status}
+	if o.Output != nil {
+		m["output"] = o.Output
+	}
+	if o.Error != "" {
+		m["error"] = o.Error
+	}
+	return m
+}
+
+// loadBroadcastDestinations reads the destinations list from a broadcast
+// step's config. Each entry needs a unique name and a type of agent,
+// wasm_module, subworkflow or webhook, for example
+//
+//	{"name": "comment", "type": "wasm_module", "wasm_module_id": "...", "config": {...}}
+//	{"name": "notify", "type": "webhook", "url": "https://example.com/hook"}
+//	{"name": "archive", "type": "subworkflow", "workflow": "Archive"}
+func loadBroadcastDestinations(step *primitive.WorkflowStep) ([]broadcastDestination, error) {
+	raw, ok := step.Config["destinations"].([]interface{})
+	if !ok || len(raw) == 0 {
+		return nil, fmt.Errorf("broadcast step requires a non-empty destinations list")
+	}
+
+	seen := make(map[string]bool, len(raw))
+	destinations := make([]broadcastDestination, 0, len(raw))
+	for i, entry := range raw {
+		spec, ok := entry.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("destinations[%d] must be an object", i)
+		}
+
+		name, _ := spec["name"].(string)
+		name = strings.TrimSpace(name)
+		if name == "" {
+			return nil, fmt.Errorf("destinations[%d] requires a name", i)
+		}
+		if seen[name] {
+			return nil, fmt.Errorf("duplicate destination name %q", name)
+		}
+		seen[name] = true
+
+		dest := broadcastDestination{Name: name}
+		config, _ := spec["config"].(map[string]interface{})
+		child := &primitive.WorkflowStep{
+			ID:         step.ID + "/" + name,
+			WorkflowID: step.WorkflowID,
+			StepOrder:  step.StepOrder,
+			Config:     config,
+		}
+
+		stepType, _ := spec["type"].(string)
+		switch stepType {
+		case "webhook":
+			dest.URL, _ = spec["url"].(string)
+			if parsed, err := url.Parse(dest.URL); err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") {
+				return nil, fmt.Errorf("destination %q requires an http or https url", name)
+			}
+		case "agent":
+			agentID, _ := spec["agent_id"].(string)
+			if agentID == "" {
+				return nil, fmt.Errorf("destination %q requires an agent_id", name)
+			}
+			child.StepType = stepType
+			child.AgentID = &agentID
+			dest.Step = child
+		case "wasm_module":
+			moduleID, _ := spec["wasm_module_id"].(string)
+			if moduleID == "" {
+				return nil, fmt.Errorf("destination %q requires a wasm_module_id", name)
+			}
+			child.StepType = stepType
+			child.WasmModuleID = &moduleID
+			dest.Step = child
+		case "subworkflow":
+			ref, _ := spec["workflow"].(string)
+			if ref == "" {
+				return nil, fmt.Errorf("destination %q requires a workflow", name)
+			}
+			child.StepType = stepType
+			child.Config = map[string]interface{}{"workflow": ref}
+			dest.Step = child
+		default:
+			return nil, fmt.Errorf("destination %q has unsupported type %q", name, stepType)
+		}
+
+		destinations = append(destinations, dest)
+	}
+
+	return destinations, nil
+}
+
+// processBroadcastStep sends the step input to every configured destination
+// concurrently, except that WASM destinations run one after another, and
+// reports each outcome under the "broadcast" key. The input is passed through
+// unchanged as the step output so later steps are not affected by which
+// destinations succeeded. The step fails only if every destination fails.
+func (e *Engine) processBroadcastStep(ctx context.Context, step *primitive.WorkflowStep, inputData map[string]interface{}, workingDir string) (map[string]interface{}, error) {
+	// Check for context cancellation before processing
+	select {
+	case <-ctx.Done():
+		return nil, fmt.Errorf("broadcast step cancelled: %w", ctx.Err())
+	default:
+	}
+
+	destinations, err := loadBroadcastDestinations(step)
+	if err != nil {
+		return nil, err
+	}
+
+	outcomes := make([]broadcastOutcome, len(destinations))
+	deliver := func(i int, dest broadcastDestination) {
+		var output map[string]interface{}
+		var err error
+		if dest.Step != nil {
+			output, err = e.processStepWithWorkingDir(ctx, dest.Step, inputData, workingDir)
+		} else {
+			output, err = deliverWebhook(ctx, dest.URL, inputData)
+		}
+
+		if err != nil {
+			log.Printf("Broadcast step %s: destination %s failed: %v", step.ID, dest.Name, err)
+			outcomes[i] = broadcastOutcome{Status: "failed", Error: err.Error()}
+			return
+		}
+		outcomes[i] = broadcastOutcome{Status: "success", Output: output}
+	}
+
+	// WASM destinations share the executor's per-execution state, so they run
+	// one at a time in a single goroutine alongside the other destinations
+	var wasmDestinations []int
+	var wg sync.WaitGroup
+	for i, dest := range destinations {
+		if dest.Step != nil && dest.Step.StepType == "wasm_module" {
+			wasmDestinations = append(wasmDestinations, i)
+			continue
+		}
+		wg.Add(1)
+		go func(i int, dest broadcastDestination) {
+			defer wg.Done()
+			deliver(i, dest)
+		}(i, dest)
+	}
+	if len(wasmDestinations) > 0 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for _, i := range wasmDestinations {
+				deliver(i, destinations[i])
+			}
+		}()
+	}
+	wg.Wait()
+
+	results := make(map[string]interface{}, len(destinations))
+	var totalUsage agent.ChatCompletionUsage
+	hasUsage := false
+	failed := 0
+	for i, dest := range destinations {
+		outcome := outcomes[i]
+		if outcome.Status != "success" {
+			failed++
+		}
+		if usage, ok := extractUsage(outcome.Output); ok {
+			totalUsage = totalUsage.Add(usage)
+			hasUsage = true
+		}
+		results[dest.Name] = outcome.toMap()
+	}
+
+	if failed == len(destinations) {
+		return nil, fmt.Errorf("all %d broadcast destinations failed", failed)
+	}
+
+	finalResult := make(map[string]interface{}, len(inputData)+2)
+	for k, v := range inputData {
+		finalResult[k] = v
+	}
+	finalResult["broadcast"] = results
+
+	// Report the usage of the destinations as the usage of this step
+	if hasUsage {
+		finalResult["usage"] = totalUsage.ToMap()
+	}
+
+	return finalResult, nil
+}
+
+// deliverWebhook POSTs the step input as JSON to target. Any 2xx response
+// counts as delivered.
+func deliverWebhook(ctx context.Context, target string, inputData map[string]interface{}) (map[string]interface{}, error) {
+	body, err := json.Marshal(inputData)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode webhook payload: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, webhookTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, target, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create webhook request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("webhook request failed: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return nil, fmt.Errorf("webhook returned status %d", resp.StatusCode)
+	}
+	return map[string]interface{}{"status_code": resp.StatusCode}, nil
+}
@@ -0,0 +1,168 @@
+package engine
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mule-ai/mule/internal/primitive"
+	"github.com/mule-ai/mule/pkg/job"
+)
+
+func TestProcessJobBroadcastsToAllDestinations(t *testing.T) {
+	var delivered, rejected atomic.Int32
+	var payload map[string]interface{}
+	okServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		delivered.Add(1)
+		_ = json.NewDecoder(r.Body).Decode(&payload)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer okServer.Close()
+	failingServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rejected.Add(1)
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer failingServer.Close()
+
+	mockStore := &MockPrimitiveStore{
+		Workflows: []*primitive.Workflow{
+			{ID: "workflow-notify", Name: "Notify"},
+			{ID: "workflow-stub", Name: "Stub"},
+		},
+		WorkflowSteps: []*primitive.WorkflowStep{
+			{ID: "step-1", WorkflowID: "workflow-notify", StepOrder: 1, StepType: "broadcast", Config: map[string]interface{}{
+				"destinations": []interface{}{
+					map[string]interface{}{"name": "hook", "type": "webhook", "url": okServer.URL},
+					map[string]interface{}{"name": "broken", "type": "webhook", "url": failingServer.URL},
+					map[string]interface{}{"name": "archive", "type": "subworkflow", "workflow": "Stub"},
+				},
+			}},
+		},
+	}
+	mockJobStore := &MockJobStore{
+		Jobs: map[string]*job.Job{
+			"job-broadcast": {
+				ID:         "job-broadcast",
+				WorkflowID: "workflow-notify",
+				Status:     job.StatusQueued,
+				InputData:  map[string]interface{}{"prompt": "release notes"},
+				CreatedAt:  time.Now(),
+			},
+		},
+	}
+	engine := newSubworkflowTestEngine(mockStore, mockJobStore)
+
+	require.NoError(t, engine.processJob(context.Background(), "job-broadcast"))
+
+	assert.Equal(t, int32(1), delivered.Load())
+	assert.Equal(t, int32(1), rejected.Load())
+	assert.Equal(t, "release notes", payload["prompt"])
+
+	completed := mockJobStore.Jobs["job-broadcast"]
+	assert.Equal(t, job.StatusCompleted, completed.Status)
+	assert.Equal(t, "release notes", completed.OutputData["prompt"], "input passes through")
+
+	outcomes, ok := completed.OutputData["broadcast"].(map[string]interface{})
+	require.True(t, ok, "broadcast outcomes missing from %v", completed.OutputData)
+	require.Len(t, outcomes, 3)
+
+	hook := outcomes["hook"].(map[string]interface{})
+	assert.Equal(t, "success", hook["status"])
+	assert.Equal(t, map[string]interface{}{"status_code": http.StatusNoContent}, hook["output"])
+
+	broken := outcomes["broken"].(map[string]interface{})
+	assert.Equal(t, "failed", broken["status"])
+	assert.Contains(t, broken["error"], "status 500")
+
+	archive := outcomes["archive"].(map[string]interface{})
+	assert.Equal(t, "success", archive["status"])
+	assert.Equal(t, "release notes", archive["output"].(map[string]interface{})["prompt"])
+}
+
+func TestProcessBroadcastStepFailsWhenEveryDestinationFails(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	engine := newSubworkflowTestEngine(&MockPrimitiveStore{}, &MockJobStore{})
+	step := &primitive.WorkflowStep{ID: "step-1", StepType: "broadcast", Config: map[string]interface{}{
+		"destinations": []interface{}{
+			map[string]interface{}{"name": "a", "type": "webhook", "url": server.URL},
+			map[string]interface{}{"name": "b", "type": "subworkflow", "workflow": "missing"},
+		},
+	}}
+
+	_, err := engine.processBroadcastStep(context.Background(), step, map[string]interface{}{"prompt": "hi"}, "")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "all 2 broadcast destinations failed")
+}
+
+func TestProcessBroadcastStepRunsWASMDestinations(t *testing.T) {
+	// WASM destinations share the executor, so run enough of them, enough
+	// times, that concurrent executions would be caught by the race detector
+	names := []string{"a", "b", "c", "d", "e", "f", "g", "h"}
+	store := &MockPrimitiveStore{}
+	engine := newSubworkflowTestEngine(store, &MockJobStore{})
+	var destinations []interface{}
+	for _, name := range names {
+		moduleID := "module-" + name
+		store.WasmModules = append(store.WasmModules, &primitive.WasmModuleListItem{ID: moduleID, Name: name})
+		engine.wasmExecutor.Modules()[moduleID] = stdoutModule(`{"from":"` + name + `"}`)
+		destinations = append(destinations, map[string]interface{}{"name": name, "type": "wasm_module", "wasm_module_id": moduleID})
+	}
+	step := &primitive.WorkflowStep{ID: "step-1", StepType: "broadcast", Config: map[string]interface{}{
+		"destinations": destinations,
+	}}
+
+	for run := 0; run < 5; run++ {
+		result, err := engine.processBroadcastStep(context.Background(), step, map[string]interface{}{"prompt": "hi"}, "")
+		require.NoError(t, err)
+
+		outcomes := result["broadcast"].(map[string]interface{})
+		for _, name := range names {
+			outcome := outcomes[name].(map[string]interface{})
+			require.Equal(t, "success", outcome["status"], "destination %s: %v", name, outcome["error"])
+			assert.Equal(t, map[string]interface{}{"prompt": map[string]interface{}{"from": name}}, outcome["output"])
+		}
+	}
+}
+
+func TestLoadBroadcastDestinations(t *testing.T) {
+	destinations, err := loadBroadcastDestinations(&primitive.WorkflowStep{ID: "step-1", Config: map[string]interface{}{
+		"destinations": []interface{}{
+			map[string]interface{}{"name": "comment", "type": "wasm_module", "wasm_module_id": "github-comment", "config": map[string]interface{}{"token": "x"}},
+			map[string]interface{}{"name": "review", "type": "agent", "agent_id": "reviewer"},
+			map[string]interface{}{"name": "hook", "type": "webhook", "url": "https://example.com/hook"},
+		},
+	}})
+	require.NoError(t, err)
+	require.Len(t, destinations, 3)
+	assert.Equal(t, "wasm_module", destinations[0].Step.StepType)
+	assert.Equal(t, "github-comment", *destinations[0].Step.WasmModuleID)
+	assert.Equal(t, "x", destinations[0].Step.Config["token"])
+	assert.Equal(t, "reviewer", *destinations[1].Step.AgentID)
+	assert.Nil(t, destinations[2].Step)
+	assert.Equal(t, "https://example.com/hook", destinations[2].URL)
+
+	for name, invalid := range map[string]interface{}{
+		"missing":        nil,
+		"empty":          []interface{}{},
+		"not an object":  []interface{}{"hook"},
+		"no name":        []interface{}{map[string]interface{}{"type": "webhook", "url": "https://example.com"}},
+		"duplicate name": []interface{}{map[string]interface{}{"name": "a", "type": "webhook", "url": "https://example.com"}, map[string]interface{}{"name": "a", "type": "webhook", "url": "https://example.com"}},
+		"bad url":        []interface{}{map[string]interface{}{"name": "a", "type": "webhook", "url": "ftp://example.com"}},
+		"no module":      []interface{}{map[string]interface{}{"name": "a", "type": "wasm_module"}},
+		"unknown type":   []interface{}{map[string]interface{}{"name": "a", "type": "email"}},
+	} {
+		_, err := loadBroadcastDestinations(&primitive.WorkflowStep{Config: map[string]interface{}{"destinations": invalid}})
+		assert.Error(t, err, name)
+	}
+}
@@ -0,0 +1,92 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mule-ai/mule/internal/primitive"
+	"github.com/mule-ai/mule/pkg/job"
+)
+
+// chattyPI answers every prompt with 400 characters, about 100 tokens
+var chattyPI = fmt.Sprintf(`#!/bin/sh
+read -r _
+printf '{"type":"agent_end","messages":[{"role":"assistant","content":[{"type":"text","text":"%s"}]}]}\n'
+exec cat >/dev/null
+`, strings.Repeat("a", 400))
+
+func newBudgetTestJob(t *testing.T, workflowConfig, piConfig map[string]interface{}) (*Engine, *MockJobStore) {
+	t.Helper()
+	installStubPI(t, chattyPI)
+
+	agentID := "agent-chatty"
+	store := &MockPrimitiveStore{
+		Agents:    []*primitive.Agent{{ID: agentID, Name: "Chatty", PIConfig: piConfig}},
+		Workflows: []*primitive.Workflow{{ID: "workflow-budget", Name: "Budget", Config: workflowConfig}},
+	}
+	for i := 1; i <= 3; i++ {
+		store.WorkflowSteps = append(store.WorkflowSteps, &primitive.WorkflowStep{
+			ID: fmt.Sprintf("step-%d", i), WorkflowID: "workflow-budget", StepOrder: i, StepType: "agent", AgentID: &agentID,
+		})
+	}
+	jobStore := &MockJobStore{
+		Jobs: map[string]*job.Job{
+			"job-budget": {ID: "job-budget", WorkflowID: "workflow-budget", Status: job.StatusQueued, InputData: map[string]interface{}{"prompt": "hello"}, CreatedAt: time.Now()},
+		},
+	}
+	return newSubworkflowTestEngine(store, jobStore), jobStore
+}
+
+func TestProcessJobStopsAtModelCallBudget(t *testing.T) {
+	engine, jobStore := newBudgetTestJob(t, map[string]interface{}{
+		"budget": map[string]interface{}{"max_model_calls": float64(2)},
+	}, nil)
+
+	err := engine.processJob(context.Background(), "job-budget")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "step 3 failed")
+	assert.Contains(t, err.Error(), "budget exceeded: workflow limit of 2 model calls reached (used 2 calls, 301 tokens)")
+
+	failed := jobStore.Jobs["job-budget"]
+	assert.Equal(t, job.StatusFailed, failed.Status)
+}
+
+func TestProcessJobStopsAtTokenBudget(t *testing.T) {
+	// The first call uses 101 tokens and the second, whose prompt is the first
+	// reply, takes the total to 301
+	engine, _ := newBudgetTestJob(t, map[string]interface{}{
+		"budget": map[string]interface{}{"max_tokens": float64(150)},
+	}, nil)
+
+	err := engine.processJob(context.Background(), "job-budget")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "budget exceeded: workflow limit of 150 tokens exceeded (used 2 calls, 301 tokens)")
+}
+
+func TestProcessJobStopsAtAgentBudget(t *testing.T) {
+	engine, _ := newBudgetTestJob(t, nil, map[string]interface{}{
+		"budget": map[string]interface{}{"max_model_calls": float64(1)},
+	})
+
+	err := engine.processJob(context.Background(), "job-budget")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "budget exceeded: agent limit of 1 model calls reached (used 1 calls, 101 tokens)")
+}
+
+func TestProcessJobReportsBudgetConsumption(t *testing.T) {
+	engine, jobStore := newBudgetTestJob(t, map[string]interface{}{
+		"budget": map[string]interface{}{"max_model_calls": float64(5), "max_tokens": float64(1000)},
+	}, nil)
+
+	require.NoError(t, engine.processJob(context.Background(), "job-budget"))
+
+	completed := jobStore.Jobs["job-budget"]
+	assert.Equal(t, job.StatusCompleted, completed.Status)
+	assert.Equal(t, map[string]interface{}{"model_calls": 3, "tokens": 501}, completed.OutputData["budget"])
+}
@@ -0,0 +1,129 @@
+package engine
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/mule-ai/mule/internal/primitive"
+)
+
+// conditionPattern matches a step condition of the form
+//
+//	steps.<order>.output.<field> <op> <JSON literal>
+//	previous.output.<field> <op> <JSON literal>
+//
+// where <op> is == or != and <field> may be a dotted path into nested output
+var conditionPattern = regexp.MustCompile(`^(steps\.(\d+)|previous)\.output\.([A-Za-z0-9_\-]+(?:\.[A-Za-z0-9_\-]+)*)\s*(==|!=)\s*(.+)$`)
+
+// stepCondition is a parsed step condition
+type stepCondition struct {
+	// stepOrder is the referenced step, or 0 for the previous step's output
+	stepOrder int
+	path      []string
+	negate    bool
+	literal   interface{}
+}
+
+// parseStepCondition parses the condition expression from a step config
+func parseStepCondition(expr string) (*stepCondition, error) {
+	match := conditionPattern.FindStringSubmatch(strings.TrimSpace(expr))
+	if match == nil {
+		return nil, fmt.Errorf("invalid condition %q: expected steps.<order>.output.<field> or previous.output.<field>, then == or !=, then a JSON value", expr)
+	}
+
+	cond := &stepCondition{
+		path:   strings.Split(match[3], "."),
+		negate: match[4] == "!=",
+	}
+	if match[2] != "" {
+		order, err := strconv.Atoi(match[2])
+		if err != nil || order <= 0 {
+			return nil, fmt.Errorf("invalid condition %q: step order must be a positive number", expr)
+		}
+		cond.stepOrder = order
+	}
+	if err := json.Unmarshal([]byte(strings.TrimSpace(match[5])), &cond.literal); err != nil {
+		return nil, fmt.Errorf("invalid condition %q: value must be a JSON literal such as \"text\", 1 or true", expr)
+	}
+
+	return cond, nil
+}
+
+// lookup returns the value at the condition's field path, or nil when any
+// part of it is missing
+func (c *stepCondition) lookup(output map[string]interface{}) interface{} {
+	value, _ := lookupPath(output, c.path)
+	return value
+}
+
+// lookupPath walks a field path through nested output maps, reporting
+// whether the field is present
+func lookupPath(output map[string]interface{}, path []string) (interface{}, bool) {
+	var value interface{} = output
+	for _, key := range path {
+		m, ok := value.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		if value, ok = m[key]; !ok {
+			return nil, false
+		}
+	}
+	return value, true
+}
+
+// matches reports whether the condition holds for a field value. A missing
+// field compares equal to the empty string.
+func (c *stepCondition) matches(value interface{}) bool {
+	var equal bool
+	if s, ok := c.literal.(string); ok && value == nil {
+		equal = s == ""
+	} else {
+		equal = reflect.DeepEqual(value, c.literal)
+	}
+	return equal != c.negate
+}
+
+// shouldRunStep evaluates the step's condition config, if any. previous is
+// the output passed to this step and outputs holds the output of each earlier
+// step by step order, nil for skipped steps. A malformed condition or one
+// that refers to the current or a later step is an error.
+func shouldRunStep(step *primitive.WorkflowStep, previous map[string]interface{}, outputs map[int]map[string]interface{}) (bool, error) {
+	if step.Config == nil {
+		return true, nil
+	}
+	raw, ok := step.Config["condition"]
+	if !ok || raw == nil {
+		return true, nil
+	}
+	expr, ok := raw.(string)
+	if !ok {
+		return false, fmt.Errorf("condition must be a string")
+	}
+	if strings.TrimSpace(expr) == "" {
+		return true, nil
+	}
+
+	cond, err := parseStepCondition(expr)
+	if err != nil {
+		return false, err
+	}
+
+	output := previous
+	if cond.stepOrder != 0 {
+		if cond.stepOrder >= step.StepOrder {
+			return false, fmt.Errorf("condition %q refers to step %d, which has not run before step %d", expr, cond.stepOrder, step.StepOrder)
+		}
+		var ran bool
+		output, ran = outputs[cond.stepOrder]
+		if !ran {
+			return false, fmt.Errorf("condition %q refers to unknown step %d", expr, cond.stepOrder)
+		}
+	}
+
+	return cond.matches(cond.lookup(output)), nil
+}
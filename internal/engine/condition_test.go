@@ -0,0 +1,138 @@
+package engine
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mule-ai/mule/internal/primitive"
+	"github.com/mule-ai/mule/pkg/job"
+)
+
+func TestShouldRunStep(t *testing.T) {
+	outputs := map[int]map[string]interface{}{
+		1: {"comment": "Looks good", "count": float64(2), "review": map[string]interface{}{"approved": true}},
+		2: nil, // skipped
+	}
+	previous := map[string]interface{}{"prompt": "hello"}
+
+	tests := []struct {
+		condition string
+		want      bool
+	}{
+		{`steps.1.output.comment != ""`, true},
+		{`steps.1.output.comment == "Looks good"`, true},
+		{`steps.1.output.count == 2`, true},
+		{`steps.1.output.review.approved == true`, true},
+		{`steps.1.output.missing != ""`, false},
+		{`steps.1.output.missing == null`, true},
+		{`steps.2.output.comment != ""`, false},
+		{`previous.output.prompt == "hello"`, true},
+		{`previous.output.prompt != "hello"`, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.condition, func(t *testing.T) {
+			step := &primitive.WorkflowStep{StepOrder: 3, Config: map[string]interface{}{"condition": tt.condition}}
+			run, err := shouldRunStep(step, previous, outputs)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, run)
+		})
+	}
+
+	t.Run("no condition", func(t *testing.T) {
+		run, err := shouldRunStep(&primitive.WorkflowStep{StepOrder: 3}, previous, outputs)
+		require.NoError(t, err)
+		assert.True(t, run)
+	})
+
+	for _, condition := range []interface{}{
+		`steps.1.output.comment`,
+		`steps.1.output.comment != unquoted`,
+		`steps.1.output.comment > ""`,
+		`steps.foo.output.comment != ""`,
+		`steps.3.output.comment != ""`,
+		`steps.9.output.comment != ""`,
+		42,
+	} {
+		step := &primitive.WorkflowStep{StepOrder: 3, Config: map[string]interface{}{"condition": condition}}
+		_, err := shouldRunStep(step, previous, outputs)
+		assert.Error(t, err, "condition %v", condition)
+	}
+}
+
+func TestProcessJobSkipsStepsWhoseConditionFails(t *testing.T) {
+	// Each subworkflow step runs a no-step stub that passes its input through
+	mockStore := &MockPrimitiveStore{
+		Workflows: []*primitive.Workflow{
+			{ID: "workflow-parent", Name: "Parent"},
+			{ID: "workflow-stub", Name: "Stub"},
+		},
+		WorkflowSteps: []*primitive.WorkflowStep{
+			{ID: "step-1", WorkflowID: "workflow-parent", StepOrder: 1, StepType: "subworkflow", Config: map[string]interface{}{"workflow": "workflow-stub"}},
+			{ID: "step-2", WorkflowID: "workflow-parent", StepOrder: 2, StepType: "subworkflow", Config: map[string]interface{}{"workflow": "workflow-stub", "condition": `steps.1.output.comment != ""`}},
+			{ID: "step-3", WorkflowID: "workflow-parent", StepOrder: 3, StepType: "subworkflow", Config: map[string]interface{}{"workflow": "workflow-stub", "condition": `previous.output.prompt == "hello"`}},
+		},
+	}
+	mockJobStore := &MockJobStore{
+		Jobs: map[string]*job.Job{
+			"job-conditional": {
+				ID:         "job-conditional",
+				WorkflowID: "workflow-parent",
+				Status:     job.StatusQueued,
+				InputData:  map[string]interface{}{"prompt": "hello", "comment": ""},
+				CreatedAt:  time.Now(),
+			},
+		},
+	}
+	engine := newSubworkflowTestEngine(mockStore, mockJobStore)
+
+	require.NoError(t, engine.processJob(context.Background(), "job-conditional"))
+
+	steps, err := mockJobStore.ListJobSteps("job-conditional")
+	require.NoError(t, err)
+	require.Len(t, steps, 3)
+	statuses := make(map[int]job.Status)
+	for _, step := range steps {
+		statuses[step.StepOrder] = step.Status
+	}
+	assert.Equal(t, job.StatusCompleted, statuses[1])
+	assert.Equal(t, job.StatusSkipped, statuses[2])
+	assert.Equal(t, job.StatusCompleted, statuses[3])
+
+	completed := mockJobStore.Jobs["job-conditional"]
+	assert.Equal(t, job.StatusCompleted, completed.Status)
+	assert.Equal(t, "hello", completed.OutputData["prompt"])
+}
+
+func TestProcessJobFailsOnMalformedCondition(t *testing.T) {
+	mockStore := &MockPrimitiveStore{
+		Workflows: []*primitive.Workflow{
+			{ID: "workflow-parent", Name: "Parent"},
+			{ID: "workflow-stub", Name: "Stub"},
+		},
+		WorkflowSteps: []*primitive.WorkflowStep{
+			{ID: "step-1", WorkflowID: "workflow-parent", StepOrder: 1, StepType: "subworkflow", Config: map[string]interface{}{"workflow": "workflow-stub", "condition": "comment is set"}},
+		},
+	}
+	mockJobStore := &MockJobStore{
+		Jobs: map[string]*job.Job{
+			"job-malformed": {
+				ID:         "job-malformed",
+				WorkflowID: "workflow-parent",
+				Status:     job.StatusQueued,
+				InputData:  map[string]interface{}{"prompt": "hello"},
+				CreatedAt:  time.Now(),
+			},
+		},
+	}
+	engine := newSubworkflowTestEngine(mockStore, mockJobStore)
+
+	err := engine.processJob(context.Background(), "job-malformed")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid condition")
+	assert.Equal(t, job.StatusFailed, mockJobStore.Jobs["job-malformed"].Status)
+}
@@ -0,0 +1,17 @@
+package engine
+
+import "log"
+
+// SetDebugLogging enables the executor's per-execution trace logs, such as the
+// input passed to each module and its raw stdout and stderr. They are off by
+// default; errors and warnings are always logged.
+func (e *WASMExecutor) SetDebugLogging(enabled bool) {
+	e.debugLogging = enabled
+}
+
+// debugf logs a trace message when debug logging is enabled
+func (e *WASMExecutor) debugf(format string, args ...interface{}) {
+	if e.debugLogging {
+		log.Printf(format, args...)
+	}
+}
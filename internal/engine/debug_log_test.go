@@ -0,0 +1,48 @@
+package engine
+
+import (
+	"bytes"
+	"context"
+	"log"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mule-ai/mule/internal/primitive"
+)
+
+func TestExecuteDebugLogging(t *testing.T) {
+	store := &MockPrimitiveStore{WasmModules: []*primitive.WasmModuleListItem{{ID: "chatty", Name: "chatty"}}}
+	executor := NewWASMExecutor(nil, store, nil, nil)
+	executor.Modules()["chatty"] = stderrModule("module trace", 0)
+
+	run := func() string {
+		var logBuf bytes.Buffer
+		oldOutput := log.Writer()
+		log.SetOutput(&logBuf)
+		defer log.SetOutput(oldOutput)
+
+		_, err := executor.Execute(context.Background(), "chatty", map[string]interface{}{"prompt": "payload-marker"}, "")
+		require.NoError(t, err)
+		return logBuf.String()
+	}
+
+	t.Run("off by default", func(t *testing.T) {
+		logs := run()
+		assert.Contains(t, logs, "Executing WASM module chatty")
+		assert.NotContains(t, logs, "payload-marker", "input should only be logged at debug level")
+		assert.NotContains(t, logs, "module trace", "raw output should only be logged at debug level")
+		assert.NotContains(t, logs, "Calling _start")
+	})
+
+	t.Run("enabled", func(t *testing.T) {
+		executor.SetDebugLogging(true)
+		defer executor.SetDebugLogging(false)
+
+		logs := run()
+		assert.Contains(t, logs, "payload-marker")
+		assert.Contains(t, logs, "stderr: 'module trace'")
+		assert.Contains(t, logs, "Calling _start")
+	})
+}
@@ -3,6 +3,7 @@ package engine
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"strconv"
@@ -28,6 +29,13 @@ type Engine struct {
 	wg           sync.WaitGroup
 	mu           sync.RWMutex
 	running      bool
+	jobsMu       sync.Mutex
+	runningJobs  map[string]context.CancelCauseFunc
+	// Step budgets shared by root jobs and the jobs they submit, and the
+	// root job of each submitted job
+	budgetsMu   sync.Mutex
+	stepBudgets map[string]*sharedStepBudget
+	jobRoots    map[string]string
 }
 
 // Config holds engine configuration
@@ -85,10 +93,18 @@ func (e *Engine) Stop() {
 	log.Println("Stopping workflow engine...")
 	e.running = false
 	close(e.stopCh)
+	e.cancelRunningJobs(errEngineStopped)
 	e.wg.Wait()
 	log.Println("Workflow engine stopped")
 }
 
+// Running reports whether the engine has been started and not yet stopped
+func (e *Engine) Running() bool {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.running
+}
+
 // SubmitJob submits a new job for execution
 func (e *Engine) SubmitJob(ctx context.Context, workflowID string, inputData map[string]interface{}) (*job.Job, error) {
 	// Call SubmitJobWithWorkingDir with empty working directory for backward compatibility
@@ -108,11 +124,17 @@ func (e *Engine) SubmitJobWithWorkingDir(ctx context.Context, workflowID string,
 		InputData:        inputData,
 		OutputData:       make(map[string]interface{}),
 		WorkingDirectory: workingDir,
+		Tags:             e.jobTags(ctx, workflowID),
 		CreatedAt:        time.Now(),
 	}
 
+	// A job submitted from a running job's context counts its steps against
+	// the step budget of that run's root job
+	rootID, _ := e.retainStepBudget(ctx, jobID)
+
 	// Save job to database
 	if err := e.jobStore.CreateJob(newJob); err != nil {
+		e.releaseStepBudget(rootID, jobID)
 		return nil, fmt.Errorf("failed to create job: %w", err)
 	}
 
@@ -120,6 +142,19 @@ func (e *Engine) SubmitJobWithWorkingDir(ctx context.Context, workflowID string,
 	return newJob, nil
 }
 
+// jobTags returns the tags for a new job: the workflow name and, when the
+// caller recorded one with job.WithTrigger, the trigger source
+func (e *Engine) jobTags(ctx context.Context, workflowID string) map[string]string {
+	tags := make(map[string]string)
+	if workflow, err := e.store.GetWorkflow(ctx, workflowID); err == nil {
+		tags[job.TagWorkflow] = workflow.Name
+	}
+	if trigger := job.TriggerFromContext(ctx); trigger != "" {
+		tags[job.TagTrigger] = trigger
+	}
+	return tags
+}
+
 // jobPoller polls for queued jobs and adds them to the processing queue
 func (e *Engine) jobPoller(ctx context.Context) {
 	defer e.wg.Done()
@@ -235,10 +270,41 @@ func (e *Engine) processJob(ctx context.Context, jobID string) error {
 		}
 	}
 
+	// Get the default step input size limit
+	inputLimitDefaults := loadInputLimit(settings)
+
+	// Get result archival options for the workflow
+	archiveCfg, err := loadArchiveConfig(workflow, settings)
+	if err != nil {
+		log.Printf("Warning: not archiving result of job %s: %v", jobID, err)
+	}
+	var archivedSteps []archivedStep
+
 	// Create a context with timeout for the job
 	jobCtx, cancel := context.WithTimeout(ctx, time.Duration(jobTimeoutSeconds)*time.Second)
 	defer cancel()
 
+	// CancelJob and Stop cancel the job with a cause so the two can be told apart
+	jobCtx, cancelJob := context.WithCancelCause(jobCtx)
+	defer cancelJob(nil)
+	defer e.trackJob(jobID, cancelJob)()
+
+	// Cap the total number of steps the job may run, including nested steps
+	// and the steps of any jobs its run submits
+	jobCtx, releaseStepBudget := e.acquireStepBudget(jobCtx, e.rootJobID(jobID), jobID, loadMaxWorkflowSteps(settings))
+	defer releaseStepBudget()
+
+	// Track model calls and tokens against the workflow's budget, if any
+	budgetLimits := agent.LoadBudgetLimits(workflow.Config)
+	budget := agent.NewBudget(budgetLimits)
+	jobCtx = agent.WithBudget(jobCtx, budget)
+
+	// Give WASM modules in this run a shared kv_set/kv_get store
+	jobCtx = withRunID(jobCtx, jobID)
+	if e.wasmExecutor != nil {
+		defer e.wasmExecutor.clearRunKV(jobID)
+	}
+
 	// Get workflow steps
 	steps, err := e.store.ListWorkflowSteps(ctx, workflow.ID)
 	if err != nil {
@@ -250,6 +316,9 @@ func (e *Engine) processJob(ctx context.Context, jobID string) error {
 
 	// Process each step
 	stepOutput := currentJob.InputData
+	stepOutputs := make(map[int]map[string]interface{}, len(steps))
+	var totalUsage agent.ChatCompletionUsage
+	hasUsage := false
 
 	for _, step := range steps {
 		// Check if job has been cancelled or timed out
@@ -279,6 +348,12 @@ func (e *Engine) processJob(ctx context.Context, jobID string) error {
 			return fmt.Errorf("job was cancelled")
 		}
 
+		// Enforce the input size limit before the input reaches the step
+		stepInput, inputLimitRecord, inputLimitErr := applyInputLimit(stepOutput, stepInputLimit(step, inputLimitDefaults))
+		if inputLimitErr != nil {
+			stepInput = stepOutput
+		}
+
 		// Create job step record
 		jobStep := &job.JobStep{
 			ID:             uuid.New().String(),
@@ -286,7 +361,7 @@ func (e *Engine) processJob(ctx context.Context, jobID string) error {
 			WorkflowStepID: step.ID,
 			StepOrder:      step.StepOrder,
 			Status:         "queued",
-			InputData:      stepOutput,
+			InputData:      stepInput,
 		}
 
 		if err := e.jobStore.CreateJobStep(jobStep); err != nil {
@@ -296,8 +371,34 @@ func (e *Engine) processJob(ctx context.Context, jobID string) error {
 			return fmt.Errorf("failed to create job step: %w", err)
 		}
 
+		// Steps with a condition only run when it holds for earlier output
+		runStep, conditionErr := shouldRunStep(step, stepOutput, stepOutputs)
+		if conditionErr != nil {
+			jobStep.Status = "failed"
+			jobStep.ErrorMessage = conditionErr.Error()
+			if updateErr := e.jobStore.UpdateJobStep(jobStep); updateErr != nil {
+				log.Printf("Warning: failed to update failed job step: %v", updateErr)
+			}
+			if markErr := e.jobStore.MarkJobFailed(jobID, fmt.Errorf("step %d failed: %w", step.StepOrder, conditionErr)); markErr != nil {
+				log.Printf("Warning: failed to mark job %s as failed: %v", jobID, markErr)
+			}
+			return fmt.Errorf("step %d failed: %w", step.StepOrder, conditionErr)
+		}
+		if !runStep {
+			// The previous output passes through to the next step unchanged
+			jobStep.Status = job.StatusSkipped
+			if err := e.jobStore.UpdateJobStep(jobStep); err != nil {
+				log.Printf("Warning: failed to update skipped job step: %v", err)
+			}
+			stepOutputs[step.StepOrder] = nil
+			log.Printf("Skipped step %d of job %s: condition not met", step.StepOrder, jobID)
+			continue
+		}
+
 		// Mark step as running
 		jobStep.Status = "running"
+		stepStartedAt := time.Now()
+		jobStep.StartedAt = &stepStartedAt
 		if err := e.jobStore.UpdateJobStep(jobStep); err != nil {
 			log.Printf("Warning: failed to update job step status to running: %v", err)
 		}
@@ -326,13 +427,43 @@ func (e *Engine) processJob(ctx context.Context, jobID string) error {
 		default:
 		}
 
-		stepResult, err := e.processStepWithWorkingDir(jobCtx, step, stepOutput, updatedJob.WorkingDirectory)
+		if inputLimitErr != nil {
+			jobStep.Status = "failed"
+			jobStep.ErrorMessage = inputLimitErr.Error()
+			jobStep.OutputData = map[string]interface{}{"input_limit": inputLimitRecord}
+			if updateErr := e.jobStore.UpdateJobStep(jobStep); updateErr != nil {
+				log.Printf("Warning: failed to update failed job step: %v", updateErr)
+			}
+			if markErr := e.jobStore.MarkJobFailed(jobID, fmt.Errorf("step %d failed: %w", step.StepOrder, inputLimitErr)); markErr != nil {
+				log.Printf("Warning: failed to mark job %s as failed: %v", jobID, markErr)
+			}
+			return fmt.Errorf("step %d failed: %w", step.StepOrder, inputLimitErr)
+		}
+		if inputLimitRecord != nil {
+			log.Printf("Truncated input for step %d of job %s: %v", step.StepOrder, jobID, inputLimitRecord)
+		}
+
+		// Failed steps are retried when the step config sets max_retries
+		retryPolicy := loadStepRetryPolicy(step)
+		stepResult, attempts, err := runWithRetries(jobCtx, retryPolicy, func() (map[string]interface{}, error) {
+			return e.processStepWithWorkingDir(jobCtx, step, stepInput, updatedJob.WorkingDirectory)
+		})
+		stepCompletedAt := time.Now()
+		jobStep.CompletedAt = &stepCompletedAt
 		if err != nil {
 			jobStep.Status = "failed"
 			jobStep.ErrorMessage = err.Error()
+			if retryPolicy.MaxRetries > 0 {
+				jobStep.OutputData = map[string]interface{}{"attempts": attempts}
+			}
 			if updateErr := e.jobStore.UpdateJobStep(jobStep); updateErr != nil {
 				log.Printf("Warning: failed to update failed job step: %v", updateErr)
 			}
+			// A step stopped by CancelJob leaves the job cancelled, not failed.
+			// Any other interruption, such as the engine stopping, fails it.
+			if errors.Is(context.Cause(jobCtx), errJobCancelled) {
+				return fmt.Errorf("job was cancelled")
+			}
 			if markErr := e.jobStore.MarkJobFailed(jobID, fmt.Errorf("step %d failed: %w", step.StepOrder, err)); markErr != nil {
 				log.Printf("Warning: failed to mark job %s as failed: %v", jobID, markErr)
 			}
@@ -354,32 +485,108 @@ func (e *Engine) processJob(ctx context.Context, jobID string) error {
 			}
 		}
 
-		// Mark step as completed
+		// Token usage is recorded on the job step and totalled for the job,
+		// but not passed on to the next step
+		stepUsage, stepHasUsage := extractUsage(stepResult)
+		if stepHasUsage {
+			totalUsage = totalUsage.Add(stepUsage)
+			hasUsage = true
+		}
+
+		// Mark step as completed, recording any input limit action, usage and
+		// attempt count alongside the result without passing them on to the
+		// next step
 		jobStep.Status = "completed"
 		jobStep.OutputData = stepResult
+		if inputLimitRecord != nil || stepHasUsage || retryPolicy.MaxRetries > 0 {
+			jobStep.OutputData = make(map[string]interface{}, len(stepResult)+3)
+			for k, v := range stepResult {
+				jobStep.OutputData[k] = v
+			}
+			if inputLimitRecord != nil {
+				jobStep.OutputData["input_limit"] = inputLimitRecord
+			}
+			if stepHasUsage {
+				jobStep.OutputData["usage"] = stepUsage.ToMap()
+			}
+			if retryPolicy.MaxRetries > 0 {
+				jobStep.OutputData["attempts"] = attempts
+			}
+		}
 		if err := e.jobStore.UpdateJobStep(jobStep); err != nil {
 			log.Printf("Warning: failed to update completed job step: %v", err)
 		}
 
+		if archiveCfg.Enabled && archiveCfg.IncludeSteps {
+			archivedSteps = append(archivedSteps, archivedStep{
+				StepOrder:      step.StepOrder,
+				WorkflowStepID: step.ID,
+				StepType:       step.StepType,
+				Output:         stepResult,
+			})
+		}
+
+		stepOutputs[step.StepOrder] = stepResult
 		stepOutput = stepResult
 	}
 
+	// Include the total token usage of all steps in the job result, and the
+	// budget consumption when the workflow has a budget
+	if hasUsage || !budgetLimits.IsZero() {
+		jobOutput := make(map[string]interface{}, len(stepOutput)+2)
+		for k, v := range stepOutput {
+			jobOutput[k] = v
+		}
+		if hasUsage {
+			jobOutput["usage"] = totalUsage.ToMap()
+		}
+		if !budgetLimits.IsZero() {
+			jobOutput["budget"] = budget.Usage().ToMap()
+		}
+		stepOutput = jobOutput
+	}
+
 	// Mark job as completed
 	if err := e.jobStore.MarkJobCompleted(jobID, stepOutput); err != nil {
 		return fmt.Errorf("failed to mark job as completed: %w", err)
 	}
 
+	// Archive the result; failures are logged but do not fail the job
+	if archiveCfg.Enabled {
+		path, err := writeArchive(archiveCfg.Directory, &archivedResult{
+			JobID:        jobID,
+			WorkflowID:   workflow.ID,
+			WorkflowName: workflow.Name,
+			CompletedAt:  time.Now(),
+			Result:       stepOutput,
+			Steps:        archivedSteps,
+		})
+		if err != nil {
+			log.Printf("Warning: failed to archive result of job %s: %v", jobID, err)
+		} else {
+			log.Printf("Archived result of job %s to %s", jobID, path)
+		}
+	}
+
 	log.Printf("Job %s completed successfully", jobID)
 	return nil
 }
 
 // processStepWithWorkingDir processes a single workflow step with working directory context
 func (e *Engine) processStepWithWorkingDir(ctx context.Context, step *primitive.WorkflowStep, inputData map[string]interface{}, workingDir string) (map[string]interface{}, error) {
+	if err := consumeStep(ctx); err != nil {
+		return nil, err
+	}
+
 	switch step.StepType {
 	case "agent":
 		return e.processAgentStepWithWorkingDir(ctx, step, inputData, workingDir)
 	case "wasm_module":
 		return e.processWASMStepWithWorkingDir(ctx, step, inputData, workingDir)
+	case "subworkflow":
+		return e.processSubworkflowStep(ctx, step, inputData, workingDir)
+	case "broadcast":
+		return e.processBroadcastStep(ctx, step, inputData, workingDir)
 	default:
 		return nil, fmt.Errorf("unknown step type: %s", step.StepType)
 	}
@@ -431,9 +638,10 @@ func (e *Engine) processAgentStepWithWorkingDir(ctx context.Context, step *primi
 		return nil, fmt.Errorf("failed to execute agent: %w", err)
 	}
 
-	// Return response as prompt for next step
+	// Return response as prompt for next step, along with the token usage
 	return map[string]interface{}{
 		"prompt": resp.Choices[0].Message.Content,
+		"usage":  resp.Usage.ToMap(),
 	}, nil
 }
 
@@ -455,6 +663,11 @@ func (e *Engine) processWASMStepWithWorkingDir(ctx context.Context, step *primit
 		return nil, fmt.Errorf("wasm_module_id not found in step")
 	}
 
+	mapping, err := loadOutputMapping(step)
+	if err != nil {
+		return nil, fmt.Errorf("invalid output_mapping: %w", err)
+	}
+
 	log.Printf("WASM step processing with inputData: %+v, workingDir: %s", inputData, workingDir)
 
 	// Execute WASM module with working directory
@@ -463,14 +676,31 @@ func (e *Engine) processWASMStepWithWorkingDir(ctx context.Context, step *primit
 		return nil, fmt.Errorf("failed to execute WASM module: %w", err)
 	}
 
+	// Module output fields are renamed from the raw stdout
+	stdout, _ := result["stdout"].(string)
+
 	// Check if the WASM module execution was successful
 	if successField, ok := result["success"]; ok {
 		if successBool, ok := successField.(bool); ok && !successBool {
 			// WASM module explicitly indicated failure
 			stderr, _ := result["stderr"].(string)
 			stdout, _ := result["stdout"].(string)
-			return nil, fmt.Errorf("WASM module execution failed: stdout='%s', stderr='%s'", stdout, stderr)
+			return nil, fmt.Errorf("WASM module execution failed: stdout='%s', stderr='%s': %w", stdout, stderr, errStepReportedFailure)
+		}
+	}
+
+	// Output set with set_job_output becomes the step output as is
+	if jobOutput, ok := result["job_output"].(map[string]interface{}); ok {
+		finalResult := make(map[string]interface{}, len(jobOutput)+1)
+		for k, v := range jobOutput {
+			finalResult[k] = v
 		}
+		if newWorkingDir, ok := result["new_working_directory"]; ok {
+			finalResult["working_directory"] = newWorkingDir
+		}
+		// Mapped fields come from the job output too, never from stdout
+		mapping.applyFields(jobOutput, finalResult)
+		return finalResult, nil
 	}
 
 	// Check if the WASM module set a new working directory
@@ -493,6 +723,7 @@ func (e *Engine) processWASMStepWithWorkingDir(ctx context.Context, step *primit
 
 		// Add the new working directory to the result
 		finalResult["working_directory"] = newWorkingDir
+		mapping.apply(stdout, finalResult)
 		return finalResult, nil
 	}
 
@@ -500,12 +731,15 @@ func (e *Engine) processWASMStepWithWorkingDir(ctx context.Context, step *primit
 	// The WASM executor returns a map with "output", "stdout", "stderr", etc.
 	// We only want the "output" field to pass to the next step
 	if output, ok := result["output"]; ok {
-		return map[string]interface{}{
+		finalResult := map[string]interface{}{
 			"prompt": output,
-		}, nil
+		}
+		mapping.apply(stdout, finalResult)
+		return finalResult, nil
 	}
 
 	// If no output field, return the whole result (backward compatibility)
+	mapping.apply(stdout, result)
 	return result, nil
 }
 
@@ -8,6 +8,7 @@ import (
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	"github.com/mule-ai/mule/internal/agent"
 	"github.com/mule-ai/mule/internal/primitive"
@@ -120,6 +121,7 @@ type MockPrimitiveStore struct {
 	Agents        []*primitive.Agent
 	Providers     []*primitive.Provider
 	WasmModules   []*primitive.WasmModuleListItem
+	Settings      []*primitive.Setting
 }
 
 func (m *MockPrimitiveStore) CreateProvider(ctx context.Context, p *primitive.Provider) error {
@@ -271,8 +273,11 @@ func (m *MockPrimitiveStore) GetSetting(ctx context.Context, key string) (*primi
 }
 
 func (m *MockPrimitiveStore) ListSettings(ctx context.Context) ([]*primitive.Setting, error) {
-	// Return empty settings for testing
-	return []*primitive.Setting{}, nil
+	// Return configured settings, empty by default
+	if m.Settings == nil {
+		return []*primitive.Setting{}, nil
+	}
+	return m.Settings, nil
 }
 
 func (m *MockPrimitiveStore) UpdateSetting(ctx context.Context, setting *primitive.Setting) error {
@@ -291,8 +296,9 @@ func (m *MockPrimitiveStore) GetWasmModule(ctx context.Context, id string) (*pri
 		if w.ID == id {
 			// Return a mock WasmModule with the ID
 			return &primitive.WasmModule{
-				ID:   w.ID,
-				Name: w.Name,
+				ID:     w.ID,
+				Name:   w.Name,
+				Config: w.Config,
 			}, nil
 		}
 	}
@@ -350,7 +356,8 @@ func (m *MockPrimitiveStore) SetAgentSkills(ctx context.Context, agentID string,
 
 // MockJobStore implements job.JobStore for testing
 type MockJobStore struct {
-	Jobs map[string]*job.Job
+	Jobs  map[string]*job.Job
+	Steps []*job.JobStep
 }
 
 func (m *MockJobStore) CreateJob(j *job.Job) error {
@@ -435,6 +442,7 @@ func (m *MockJobStore) DeleteJob(id string) error {
 }
 
 func (m *MockJobStore) CreateJobStep(s *job.JobStep) error {
+	m.Steps = append(m.Steps, s)
 	return nil
 }
 
@@ -443,7 +451,13 @@ func (m *MockJobStore) GetJobStep(id string) (*job.JobStep, error) {
 }
 
 func (m *MockJobStore) ListJobSteps(jobID string) ([]*job.JobStep, error) {
-	return nil, nil
+	var steps []*job.JobStep
+	for _, s := range m.Steps {
+		if s.JobID == jobID {
+			steps = append(steps, s)
+		}
+	}
+	return steps, nil
 }
 
 func (m *MockJobStore) UpdateJobStep(s *job.JobStep) error {
@@ -800,3 +814,21 @@ func TestProcessWASMStepWithWorkingDir_Cancellation(t *testing.T) {
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "cancelled")
 }
+
+func TestSubmitJobTagsWorkflowAndTrigger(t *testing.T) {
+	mockStore := &MockPrimitiveStore{
+		Workflows: []*primitive.Workflow{{ID: "workflow-1", Name: "review"}},
+	}
+	mockJobStore := &MockJobStore{Jobs: make(map[string]*job.Job)}
+	engine := NewEngine(mockStore, mockJobStore, nil, nil, Config{Workers: 1})
+
+	ctx := job.WithTrigger(context.Background(), job.TriggerSchedule)
+	submitted, err := engine.SubmitJob(ctx, "workflow-1", map[string]interface{}{"prompt": "hi"})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"workflow": "review", "trigger": "schedule"}, submitted.Tags)
+
+	// Without a recorded trigger only the workflow tag is set
+	submitted, err = engine.SubmitJob(context.Background(), "workflow-1", nil)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"workflow": "review"}, submitted.Tags)
+}
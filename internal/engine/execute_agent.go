@@ -0,0 +1,114 @@
+package engine
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+
+	"github.com/tetratelabs/wazero/api"
+
+	"github.com/mule-ai/mule/internal/agent"
+)
+
+// executeAgentOptions are the optional overrides a module can pass to
+// execute_agent
+type executeAgentOptions struct {
+	SystemPrompt string   `json:"system_prompt"`
+	Tools        []string `json:"tools"`
+}
+
+// executeAgent implements the execute_agent host function. It runs an agent,
+// found by ID or name, on a prompt with an optional JSON options object that
+// can override the agent's system prompt and tools. The response is stored for
+// get_last_operation_result, as with execute_target.
+func (e *WASMExecutor) executeAgent(ctx context.Context, module api.Module, agentIDPtr, agentIDSize, promptPtr, promptSize, optionsPtr, optionsSize uint32) uint32 {
+	// Check for context cancellation before processing
+	select {
+	case <-ctx.Done():
+		// Return error code for cancellation
+		return 0xFFFFFFFA
+	default:
+	}
+
+	mem := module.Memory()
+
+	// Read agent ID from WASM memory
+	agentID, err := readStringFromMemory(ctx, mem, agentIDPtr, agentIDSize)
+	if err != nil || agentID == "" {
+		log.Printf("Failed to read agent ID from WASM memory: %v", err)
+		// Return error code (0xFFFFFFF0)
+		return 0xFFFFFFF0
+	}
+
+	// Read prompt from WASM memory
+	prompt, err := readStringFromMemory(ctx, mem, promptPtr, promptSize)
+	if err != nil {
+		log.Printf("Failed to read prompt from WASM memory: %v", err)
+		// Return error code (0xFFFFFFF1)
+		return 0xFFFFFFF1
+	}
+
+	// Read options from WASM memory (may be empty)
+	var options executeAgentOptions
+	if optionsSize > 0 {
+		optionsJSON, err := readStringFromMemory(ctx, mem, optionsPtr, optionsSize)
+		if err != nil {
+			log.Printf("Failed to read agent options from WASM memory: %v", err)
+			// Return error code (0xFFFFFFF2)
+			return 0xFFFFFFF2
+		}
+		if err := json.Unmarshal([]byte(optionsJSON), &options); err != nil {
+			log.Printf("Failed to parse agent options JSON: %v", err)
+			// Return error code (0xFFFFFFF3)
+			return 0xFFFFFFF3
+		}
+	}
+
+	if e.agentRuntime == nil {
+		log.Printf("Agent runtime not available to execute agent %s", agentID)
+		// Return error code (0xFFFFFFF4)
+		return 0xFFFFFFF4
+	}
+
+	// Use a unique key for this execution context
+	key := moduleKey(module)
+
+	result, err := e.runAgent(ctx, agentID, prompt, options)
+	if err != nil {
+		log.Printf("Failed to execute agent %s: %v", agentID, err)
+		e.setOperationError(key, 0xFFFFFFF5, err)
+		// Return error code (0xFFFFFFF5)
+		return 0xFFFFFFF5
+	}
+
+	// Store result for retrieval by the module
+	e.lastOperationResult[key] = result
+	e.lastOperationStatus[key] = 0 // Success
+	delete(e.lastOperationError, key)
+
+	// Return 0 for success
+	return 0
+}
+
+// runAgent resolves agentID and executes it with the given overrides,
+// returning the JSON-encoded chat completion response
+func (e *WASMExecutor) runAgent(ctx context.Context, agentID, prompt string, options executeAgentOptions) ([]byte, error) {
+	agentModel, err := e.resolveAgent(ctx, agentID)
+	if err != nil {
+		return nil, err
+	}
+
+	req := &agent.ChatCompletionRequest{
+		Model:        fmt.Sprintf("agent/%s", agentModel.Name),
+		Messages:     []agent.ChatCompletionMessage{{Role: "user", Content: prompt}},
+		SystemPrompt: options.SystemPrompt,
+		Tools:        options.Tools,
+	}
+
+	resp, err := e.agentRuntime.ExecuteAgent(ctx, req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute agent: %w", err)
+	}
+	return json.Marshal(resp)
+}
@@ -0,0 +1,88 @@
+package engine
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mule-ai/mule/internal/agent"
+	"github.com/mule-ai/mule/internal/primitive"
+)
+
+// stubPI stands in for the pi binary. It answers the first prompt with the
+// system prompt and tools it was started with, then idles until stopped.
+const stubPI = `#!/bin/sh
+while [ $# -gt 0 ]; do
+  case "$1" in
+    --system-prompt) prompt="$2"; shift ;;
+    --tools) tools="$2"; shift ;;
+  esac
+  shift
+done
+read -r _
+printf '{"type":"agent_end","messages":[{"role":"assistant","content":[{"type":"text","text":"%s|%s"}]}]}\n' "$prompt" "$tools"
+exec cat >/dev/null
+`
+
+// installStubPI puts script first on PATH as the pi binary for the rest of
+// the test
+func installStubPI(t *testing.T, script string) {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("stub pi is a shell script")
+	}
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "pi"), []byte(script), 0o755))
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+}
+
+func TestExecuteAgentHostFunction(t *testing.T) {
+	installStubPI(t, stubPI)
+
+	store := &MockPrimitiveStore{
+		Agents: []*primitive.Agent{{ID: "agent-1", Name: "Reviewer", SystemPrompt: "You review code"}},
+	}
+	executor := NewWASMExecutor(nil, store, agent.NewRuntime(store, nil), nil)
+
+	mem := newFakeMemory(1024)
+	module := &fakeModule{mem: mem}
+	execute := func(agentID, prompt, options string) uint32 {
+		idPtr, idSize := mem.put(0, agentID)
+		promptPtr, promptSize := mem.put(64, prompt)
+		optionsPtr, optionsSize := mem.put(128, options)
+		return executor.executeAgent(context.Background(), module, idPtr, idSize, promptPtr, promptSize, optionsPtr, optionsSize)
+	}
+	reply := func() string {
+		var resp agent.ChatCompletionResponse
+		require.NoError(t, json.Unmarshal(executor.lastOperationResult[moduleKey(module)], &resp))
+		require.Len(t, resp.Choices, 1)
+		return resp.Choices[0].Message.Content
+	}
+
+	t.Run("overrides system prompt and tools", func(t *testing.T) {
+		require.Equal(t, uint32(0), execute("agent-1", "review this", `{"system_prompt": "You write tests", "tools": ["read", "grep"]}`))
+		assert.Equal(t, "You write tests|read,grep", reply())
+		assert.Equal(t, "You review code", store.Agents[0].SystemPrompt, "stored agent is unchanged")
+	})
+
+	t.Run("uses the agent's own prompt without options", func(t *testing.T) {
+		require.Equal(t, uint32(0), execute("reviewer", "review this", ""))
+		assert.Equal(t, "You review code|", reply())
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		assert.Equal(t, uint32(0xFFFFFFF0), execute("", "review this", ""))
+		assert.Equal(t, uint32(0xFFFFFFF3), execute("agent-1", "review this", "{not json"))
+		assert.Equal(t, uint32(0xFFFFFFF5), execute("missing", "review this", ""))
+
+		noRuntime := NewWASMExecutor(nil, store, nil, nil)
+		idPtr, idSize := mem.put(0, "agent-1")
+		assert.Equal(t, uint32(0xFFFFFFF4), noRuntime.executeAgent(context.Background(), module, idPtr, idSize, 0, 0, 0, 0))
+	})
+}
@@ -0,0 +1,94 @@
+package engine
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"strings"
+
+	"github.com/tetratelabs/wazero/api"
+)
+
+// executeTarget implements the execute_target host function. It triggers a
+// workflow or calls an agent and stores the result for
+// get_last_operation_result; on failure the error is stored for
+// get_last_operation_error.
+func (e *WASMExecutor) executeTarget(ctx context.Context, module api.Module, targetTypePtr, targetTypeSize, targetIDPtr, targetIDSize, paramsPtr, paramsSize uint32) uint32 {
+	// Check for context cancellation before processing
+	select {
+	case <-ctx.Done():
+		// Return error code for cancellation
+		return 0xFFFFFFFA
+	default:
+	}
+
+	// Get memory from the module
+	mem := module.Memory()
+
+	// Read target type from WASM memory
+	targetType, err := readStringFromMemory(ctx, mem, targetTypePtr, targetTypeSize)
+	if err != nil {
+		log.Printf("Failed to read target type from WASM memory: %v", err)
+		// Return error code (0xFFFFFFF0)
+		return 0xFFFFFFF0
+	}
+
+	// Read target ID from WASM memory
+	targetID, err := readStringFromMemory(ctx, mem, targetIDPtr, targetIDSize)
+	if err != nil {
+		log.Printf("Failed to read target ID from WASM memory: %v", err)
+		// Return error code (0xFFFFFFF1)
+		return 0xFFFFFFF1
+	}
+
+	// Read params from WASM memory
+	paramsJSON, err := readStringFromMemory(ctx, mem, paramsPtr, paramsSize)
+	if err != nil {
+		log.Printf("Failed to read params from WASM memory: %v", err)
+		// Return error code (0xFFFFFFF2)
+		return 0xFFFFFFF2
+	}
+
+	// Parse params JSON
+	var params map[string]interface{}
+	if paramsJSON != "" {
+		if err := json.Unmarshal([]byte(paramsJSON), &params); err != nil {
+			log.Printf("Failed to parse params JSON: %v", err)
+			// Return error code (0xFFFFFFF3)
+			return 0xFFFFFFF3
+		}
+	} else {
+		params = make(map[string]interface{})
+	}
+
+	// Execute based on target type
+	var result []byte
+	switch strings.ToLower(targetType) {
+	case "workflow":
+		result, err = e.triggerWorkflow(ctx, targetID, params)
+	case "agent":
+		result, err = e.callAgent(ctx, targetID, params)
+	default:
+		log.Printf("Invalid target type: %s", targetType)
+		// Return error code (0xFFFFFFF4)
+		return 0xFFFFFFF4
+	}
+
+	// Use a unique key for this execution context
+	key := moduleKey(module)
+
+	if err != nil {
+		log.Printf("Failed to execute %s %s: %v", targetType, targetID, err)
+		e.setOperationError(key, 0xFFFFFFF5, err)
+		// Return error code (0xFFFFFFF5)
+		return 0xFFFFFFF5
+	}
+
+	// Store result for retrieval by the module
+	e.lastOperationResult[key] = result
+	e.lastOperationStatus[key] = 0 // Success
+	delete(e.lastOperationError, key)
+
+	// Return 0 for success
+	return 0
+}
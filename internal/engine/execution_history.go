@@ -0,0 +1,172 @@
+package engine
+
+import (
+	"encoding/json"
+	"strings"
+	"sync"
+	"time"
+)
+
+// defaultExecutionHistorySize is the number of executions kept per module
+const defaultExecutionHistorySize = 10
+
+// redactedValue replaces secret values in recorded executions
+const redactedValue = "[REDACTED]"
+
+// sensitiveKeyParts marks input fields whose values are redacted from recorded stdin
+var sensitiveKeyParts = []string{"token", "secret", "password", "api_key", "apikey", "authorization"}
+
+// ExecutionRecord captures the I/O and outcome of one WASM module execution for debugging
+type ExecutionRecord struct {
+	ModuleID   string    `json:"module_id"`
+	StartedAt  time.Time `json:"started_at"`
+	DurationMs int64     `json:"duration_ms"`
+	Stdin      string    `json:"stdin"`
+	Stdout     string    `json:"stdout"`
+	Stderr     string    `json:"stderr"`
+	Outcome    string    `json:"outcome"`
+	Error      string    `json:"error,omitempty"`
+}
+
+// executionHistory keeps a bounded ring buffer of recent executions per module
+type executionHistory struct {
+	mu      sync.Mutex
+	size    int
+	records map[string][]ExecutionRecord
+}
+
+func newExecutionHistory(size int) *executionHistory {
+	return &executionHistory{size: size, records: make(map[string][]ExecutionRecord)}
+}
+
+// add appends a record, dropping the oldest once the module's buffer is full
+func (h *executionHistory) add(record ExecutionRecord) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.size <= 0 {
+		return
+	}
+	records := append(h.records[record.ModuleID], record)
+	if len(records) > h.size {
+		records = records[len(records)-h.size:]
+	}
+	h.records[record.ModuleID] = records
+}
+
+// recent returns up to limit records for a module, newest first
+func (h *executionHistory) recent(moduleID string, limit int) []ExecutionRecord {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	records := h.records[moduleID]
+	if limit <= 0 || limit > len(records) {
+		limit = len(records)
+	}
+	result := make([]ExecutionRecord, 0, limit)
+	for i := len(records) - 1; i >= 0 && len(result) < limit; i-- {
+		result = append(result, records[i])
+	}
+	return result
+}
+
+// setSize changes the number of executions kept per module, trimming existing buffers
+func (h *executionHistory) setSize(size int) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.size = size
+	for moduleID, records := range h.records {
+		if size <= 0 {
+			delete(h.records, moduleID)
+		} else if len(records) > size {
+			h.records[moduleID] = records[len(records)-size:]
+		}
+	}
+}
+
+// RecentExecutions returns up to limit recorded executions of a module, newest
+// first. A limit of 0 returns every recorded execution.
+func (e *WASMExecutor) RecentExecutions(moduleID string, limit int) []ExecutionRecord {
+	return e.history.recent(moduleID, limit)
+}
+
+// SetExecutionHistorySize sets how many executions are kept per module; 0 disables recording
+func (e *WASMExecutor) SetExecutionHistorySize(size int) {
+	e.history.setSize(size)
+}
+
+// recordExecution stores an execution in the history with secrets redacted
+func (e *WASMExecutor) recordExecution(moduleID string, startedAt time.Time, stdin []byte, stdout, stderr string, result map[string]interface{}, err error) {
+	record := ExecutionRecord{
+		ModuleID:   moduleID,
+		StartedAt:  startedAt,
+		DurationMs: time.Since(startedAt).Milliseconds(),
+		Stdin:      e.redact(redactSensitiveFields(stdin)),
+		Stdout:     e.redact(stdout),
+		Stderr:     e.redact(stderr),
+		Outcome:    "success",
+	}
+
+	switch {
+	case err != nil:
+		record.Outcome = "error"
+		record.Error = e.redact(err.Error())
+	case result == nil:
+		record.Outcome = "error"
+	case result["success"] == false:
+		record.Outcome = "failure"
+	}
+
+	e.history.add(record)
+}
+
+// redact replaces the value of every known secret with a placeholder
+func (e *WASMExecutor) redact(s string) string {
+	if e.secrets == nil || s == "" {
+		return s
+	}
+	for _, name := range e.secrets.Names() {
+		if value, ok := e.secrets.Get(name); ok && value != "" {
+			s = strings.ReplaceAll(s, value, redactedValue)
+		}
+	}
+	return s
+}
+
+// redactSensitiveFields replaces the values of credential-like fields in a JSON
+// document. Input that is not a JSON object is returned unchanged.
+func redactSensitiveFields(data []byte) string {
+	var doc map[string]interface{}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return string(data)
+	}
+	redactMap(doc)
+	redacted, err := json.Marshal(doc)
+	if err != nil {
+		return string(data)
+	}
+	return string(redacted)
+}
+
+func redactMap(m map[string]interface{}) {
+	for key, value := range m {
+		if isSensitiveKey(key) {
+			m[key] = redactedValue
+			continue
+		}
+		if nested, ok := value.(map[string]interface{}); ok {
+			redactMap(nested)
+		}
+	}
+}
+
+func isSensitiveKey(key string) bool {
+	lower := strings.ToLower(key)
+	for _, part := range sensitiveKeyParts {
+		if strings.Contains(lower, part) {
+			return true
+		}
+	}
+	return false
+}
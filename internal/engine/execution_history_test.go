@@ -0,0 +1,50 @@
+package engine
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExecutionHistoryRingBuffer(t *testing.T) {
+	h := newExecutionHistory(2)
+	for _, stdin := range []string{"one", "two", "three"} {
+		h.add(ExecutionRecord{ModuleID: "m", Stdin: stdin})
+	}
+	h.add(ExecutionRecord{ModuleID: "other", Stdin: "x"})
+
+	recent := h.recent("m", 0)
+	assert.Len(t, recent, 2)
+	assert.Equal(t, "three", recent[0].Stdin)
+	assert.Equal(t, "two", recent[1].Stdin)
+	assert.Len(t, h.recent("m", 1), 1)
+	assert.Empty(t, h.recent("missing", 0))
+
+	h.setSize(1)
+	assert.Equal(t, "three", h.recent("m", 0)[0].Stdin)
+
+	h.setSize(0)
+	h.add(ExecutionRecord{ModuleID: "m"})
+	assert.Empty(t, h.recent("m", 0))
+}
+
+func TestRecordExecutionRedactsSecrets(t *testing.T) {
+	executor := NewWASMExecutor(nil, nil, nil, nil)
+	executor.secrets.Set("API_KEY", "s3cr3t-value")
+
+	stdin := []byte(`{"prompt":"call with s3cr3t-value","auth":{"password":"hunter2"},"name":"ok"}`)
+	executor.recordExecution("m", time.Now(), stdin, "out s3cr3t-value", "", nil, errors.New("failed using s3cr3t-value"))
+
+	record := executor.RecentExecutions("m", 1)[0]
+	assert.Equal(t, "error", record.Outcome)
+	assert.Equal(t, "failed using [REDACTED]", record.Error)
+	assert.Equal(t, "out [REDACTED]", record.Stdout)
+	assert.NotContains(t, record.Stdin, "s3cr3t-value")
+	assert.NotContains(t, record.Stdin, "hunter2")
+	assert.Contains(t, record.Stdin, `"name":"ok"`)
+
+	executor.recordExecution("m", time.Now(), nil, "", "", map[string]interface{}{"success": false}, nil)
+	assert.Equal(t, "failure", executor.RecentExecutions("m", 1)[0].Outcome)
+}
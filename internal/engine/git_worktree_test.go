@@ -0,0 +1,118 @@
+package engine
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// runGit runs a git command in dir and returns its trimmed output
+func runGit(t *testing.T, dir string, args ...string) string {
+	t.Helper()
+	cmd := exec.Command("git", append([]string{"-c", "user.name=test", "-c", "user.email=test@example.com"}, args...)...)
+	cmd.Dir = dir
+	output, err := cmd.CombinedOutput()
+	require.NoError(t, err, string(output))
+	return strings.TrimSpace(string(output))
+}
+
+func TestCreateGitWorktreeFromRef(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+
+	repo := filepath.Join(t.TempDir(), "repo")
+	require.NoError(t, os.MkdirAll(repo, 0755))
+	runGit(t, repo, "init", "-q")
+	require.NoError(t, os.WriteFile(filepath.Join(repo, "file.txt"), []byte("one"), 0644))
+	runGit(t, repo, "add", ".")
+	runGit(t, repo, "commit", "-q", "-m", "first")
+	firstCommit := runGit(t, repo, "rev-parse", "HEAD")
+	runGit(t, repo, "tag", "v1")
+	require.NoError(t, os.WriteFile(filepath.Join(repo, "file.txt"), []byte("two"), 0644))
+	runGit(t, repo, "commit", "-q", "-am", "second")
+
+	executor := NewWASMExecutor(nil, &MockPrimitiveStore{}, nil, nil)
+	executor.workingDir = repo
+
+	mem := newFakeMemory(1024)
+	module := &fakeModule{mem: mem}
+	ctx := context.Background()
+
+	create := func(name, ref, branch string) uint32 {
+		namePtr, nameSize := mem.put(0, name)
+		refPtr, refSize := mem.put(256, ref)
+		branchPtr, branchSize := mem.put(512, branch)
+		return executor.createGitWorktree(ctx, module, namePtr, nameSize, 0, 0, refPtr, refSize, branchPtr, branchSize)
+	}
+
+	t.Run("checks out commit on new branch", func(t *testing.T) {
+		require.Equal(t, uint32(0), create("from-commit", firstCommit, "feature/from-commit"))
+
+		worktree := filepath.Join(filepath.Dir(repo), "from-commit")
+		assert.Equal(t, firstCommit, runGit(t, worktree, "rev-parse", "HEAD"))
+		assert.Equal(t, "feature/from-commit", runGit(t, worktree, "rev-parse", "--abbrev-ref", "HEAD"))
+		assert.Equal(t, worktree, executor.currentNewWorkingDir)
+	})
+
+	t.Run("branch defaults to worktree name", func(t *testing.T) {
+		require.Equal(t, uint32(0), create("from-tag", "v1", ""))
+
+		worktree := filepath.Join(filepath.Dir(repo), "from-tag")
+		assert.Equal(t, firstCommit, runGit(t, worktree, "rev-parse", "HEAD"))
+		assert.Equal(t, "from-tag", runGit(t, worktree, "rev-parse", "--abbrev-ref", "HEAD"))
+	})
+
+	t.Run("rejects unknown ref", func(t *testing.T) {
+		assert.Equal(t, uint32(0xFFFFFFF5), create("missing-ref", "no-such-ref", ""))
+		assert.Equal(t, uint32(0xFFFFFFF5), create("option-ref", "--orphan", ""))
+		assert.NoDirExists(t, filepath.Join(filepath.Dir(repo), "missing-ref"))
+	})
+
+	t.Run("rejects invalid branch name", func(t *testing.T) {
+		assert.Equal(t, uint32(0xFFFFFFF6), create("bad-branch", "v1", "bad..name"))
+	})
+}
+
+func TestCreateGitWorktreeRejectsUnsafeNames(t *testing.T) {
+	// The working directory is not a git repository, so any name that got
+	// past validation would fail with 0xFFFFFFF3 instead
+	executor := NewWASMExecutor(nil, &MockPrimitiveStore{}, nil, nil)
+	executor.workingDir = t.TempDir()
+
+	mem := newFakeMemory(1024)
+	module := &fakeModule{mem: mem}
+	ctx := context.Background()
+
+	names := []string{
+		"",
+		"../escape",
+		"..",
+		"nested/dir",
+		`back\slash`,
+		"--orphan",
+		"-b",
+		"name;rm -rf ~",
+		"$(touch pwned)",
+		"`id`",
+		"a|b",
+		"with space",
+		"line\nbreak",
+		"HEAD",
+		".",
+	}
+	for _, name := range names {
+		namePtr, nameSize := mem.put(0, name)
+		assert.Equal(t, uint32(0xFFFFFFF8), executor.createGitWorktree(ctx, module, namePtr, nameSize, 0, 0, 0, 0, 0, 0), "name %q", name)
+	}
+
+	// A safe name gets past validation to the repository check
+	namePtr, nameSize := mem.put(0, "feature-xyz_2.0")
+	assert.Equal(t, uint32(0xFFFFFFF3), executor.createGitWorktree(ctx, module, namePtr, nameSize, 0, 0, 0, 0, 0, 0))
+}
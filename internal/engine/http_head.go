@@ -0,0 +1,75 @@
+package engine
+
+import (
+	"context"
+	"log"
+	"net/http"
+
+	"github.com/tetratelabs/wazero/api"
+)
+
+// httpHead implements the http_head host function. It sends a HEAD request so
+// a module can check that a URL is reachable, or look at its Content-Type,
+// without downloading the body. The status and headers are stored for
+// get_last_response_status and the header functions, and the stored body is
+// empty.
+func (e *WASMExecutor) httpHead(ctx context.Context, module api.Module, urlPtr, urlSize uint32) uint32 {
+	// Check for context cancellation before processing
+	select {
+	case <-ctx.Done():
+		// Return error code for cancellation
+		return 0xFFFFFFFA
+	default:
+	}
+
+	if !httpMethodAllowed(ctx, http.MethodHead) {
+		log.Printf("HTTP method HEAD not allowed for this module")
+		// Return error code (0xFFFFFFF9)
+		return 0xFFFFFFF9
+	}
+
+	// Read URL from WASM memory
+	urlStr, err := readStringFromMemory(ctx, module.Memory(), urlPtr, urlSize)
+	if err != nil {
+		log.Printf("Failed to read URL from WASM memory: %v", err)
+		// Return error code (0xFFFFFFFF)
+		return 0xFFFFFFFF
+	}
+
+	// Validate URL
+	if !e.isURLAllowed(urlStr) || !moduleURLAllowed(ctx, urlStr) {
+		log.Printf("URL not allowed: %s", urlStr)
+		// Return error code (0xFFFFFFFE)
+		return 0xFFFFFFFE
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, urlStr, nil)
+	if err != nil {
+		log.Printf("Failed to create HTTP request for URL %s: %v", urlStr, err)
+		// Return error code (0xFFFFFFFD)
+		return 0xFFFFFFFD
+	}
+
+	client := &http.Client{
+		Timeout: e.requestTimeout(0),
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		log.Printf("Failed to make HTTP request to %s: %v", urlStr, err)
+		// Return error code (0xFFFFFFFC)
+		return 0xFFFFFFFC
+	}
+	if err := resp.Body.Close(); err != nil {
+		log.Printf("Failed to close response body: %v", err)
+	}
+
+	// Store response data for retrieval by the module
+	key := moduleKey(module)
+	e.lastResponse[key] = resp
+	e.lastResponseBody[key] = []byte{}
+
+	log.Printf("HTTP HEAD request to %s completed with status %d", urlStr, resp.StatusCode)
+
+	// Return 0 for success
+	return 0
+}
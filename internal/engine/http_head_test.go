@@ -0,0 +1,49 @@
+package engine
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHTTPHead(t *testing.T) {
+	var method string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		method = r.Method
+		w.Header().Set("Content-Type", "application/rss+xml")
+		_, _ = w.Write([]byte("<rss></rss>"))
+	}))
+	defer server.Close()
+
+	executor := NewWASMExecutor(nil, &MockPrimitiveStore{}, nil, nil)
+	mem := newFakeMemory(512)
+	module := &fakeModule{mem: mem}
+	head := func(ctx context.Context, url string) uint32 {
+		urlPtr, urlSize := mem.put(0, url)
+		return executor.httpHead(ctx, module, urlPtr, urlSize)
+	}
+
+	require.Equal(t, uint32(0), head(context.Background(), server.URL))
+	assert.Equal(t, http.MethodHead, method)
+
+	key := moduleKey(module)
+	require.Contains(t, executor.lastResponse, key)
+	assert.Equal(t, http.StatusOK, executor.lastResponse[key].StatusCode)
+	assert.Equal(t, "application/rss+xml", executor.lastResponse[key].Header.Get("Content-Type"))
+	assert.Empty(t, executor.lastResponseBody[key])
+
+	t.Run("URL allow-list", func(t *testing.T) {
+		executor.SetURLAllowList([]string{"https://example.com/"})
+		defer executor.SetURLAllowList([]string{"https://", "http://"})
+		assert.Equal(t, uint32(0xFFFFFFFE), head(context.Background(), server.URL))
+	})
+
+	t.Run("HEAD not allowed for module", func(t *testing.T) {
+		ctx := withAllowedHTTPMethods(context.Background(), map[string]interface{}{"allowed_http_methods": "GET"})
+		assert.Equal(t, uint32(0xFFFFFFF9), head(ctx, server.URL))
+	})
+}
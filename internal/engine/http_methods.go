@@ -0,0 +1,82 @@
+package engine
+
+import (
+	"context"
+	"strings"
+)
+
+// supportedHTTPMethods are the verbs WASM modules may use with the HTTP host functions
+var supportedHTTPMethods = map[string]bool{
+	"GET":     true,
+	"HEAD":    true,
+	"POST":    true,
+	"PUT":     true,
+	"PATCH":   true,
+	"DELETE":  true,
+	"OPTIONS": true,
+}
+
+// defaultBodyMethods are the methods whose request bodies default to a JSON
+// Content-Type when the module does not set one
+var defaultBodyMethods = []string{"POST", "PUT", "PATCH"}
+
+// normalizeHTTPMethod upper-cases method and reports whether it is supported
+func normalizeHTTPMethod(method string) (string, bool) {
+	method = strings.ToUpper(strings.TrimSpace(method))
+	return method, supportedHTTPMethods[method]
+}
+
+// newMethodSet builds a set of upper-cased HTTP methods
+func newMethodSet(methods []string) map[string]bool {
+	set := make(map[string]bool, len(methods))
+	for _, method := range methods {
+		set[strings.ToUpper(strings.TrimSpace(method))] = true
+	}
+	return set
+}
+
+// SetBodyMethods sets the HTTP methods whose request bodies default to a JSON
+// Content-Type. Methods are matched case-insensitively.
+func (e *WASMExecutor) SetBodyMethods(methods []string) {
+	e.bodyMethods = newMethodSet(methods)
+}
+
+// defaultsToJSON reports whether a request body sent with method should get
+// the default JSON Content-Type
+func (e *WASMExecutor) defaultsToJSON(method string) bool {
+	return e.bodyMethods[method]
+}
+
+// allowedHTTPMethodsKey is the context key holding the HTTP methods the
+// executing module may use
+type allowedHTTPMethodsKey struct{}
+
+// withAllowedHTTPMethods restricts the HTTP host functions to the methods in
+// the module's allowed_http_methods config, given as a list or a
+// comma-separated string. Without that setting every supported method is
+// allowed. The restriction is read from the stored module config rather than
+// the merged input, so callers cannot lift it.
+func withAllowedHTTPMethods(ctx context.Context, config map[string]interface{}) context.Context {
+	var methods []string
+	switch v := config["allowed_http_methods"].(type) {
+	case []interface{}:
+		for _, m := range v {
+			if s, ok := m.(string); ok {
+				methods = append(methods, s)
+			}
+		}
+	case []string:
+		methods = v
+	case string:
+		methods = strings.Split(v, ",")
+	default:
+		return ctx
+	}
+	return context.WithValue(ctx, allowedHTTPMethodsKey{}, newMethodSet(methods))
+}
+
+// httpMethodAllowed reports whether the executing module may use method
+func httpMethodAllowed(ctx context.Context, method string) bool {
+	allowed, ok := ctx.Value(allowedHTTPMethodsKey{}).(map[string]bool)
+	return !ok || allowed[method]
+}
@@ -0,0 +1,88 @@
+package engine
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalizeHTTPMethod(t *testing.T) {
+	tests := []struct {
+		method string
+		want   string
+		ok     bool
+	}{
+		{"GET", "GET", true},
+		{"patch", "PATCH", true},
+		{" Delete ", "DELETE", true},
+		{"CONNECT", "CONNECT", false},
+		{"FETCH", "FETCH", false},
+		{"", "", false},
+	}
+
+	for _, tt := range tests {
+		got, ok := normalizeHTTPMethod(tt.method)
+		assert.Equal(t, tt.want, got, "method %q", tt.method)
+		assert.Equal(t, tt.ok, ok, "method %q", tt.method)
+	}
+}
+
+func TestWASMExecutorBodyMethods(t *testing.T) {
+	executor := NewWASMExecutor(nil, &MockPrimitiveStore{}, nil, nil)
+
+	// POST, PUT and PATCH bodies default to JSON
+	assert.True(t, executor.defaultsToJSON("POST"))
+	assert.True(t, executor.defaultsToJSON("PUT"))
+	assert.True(t, executor.defaultsToJSON("PATCH"))
+	assert.False(t, executor.defaultsToJSON("DELETE"))
+
+	executor.SetBodyMethods([]string{"post", "delete"})
+
+	assert.True(t, executor.defaultsToJSON("POST"))
+	assert.True(t, executor.defaultsToJSON("DELETE"))
+	assert.False(t, executor.defaultsToJSON("PATCH"))
+}
+
+func TestAllowedHTTPMethods(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(r.Method))
+	}))
+	defer server.Close()
+
+	executor := NewWASMExecutor(nil, &MockPrimitiveStore{}, nil, nil)
+	mem := newFakeMemory(512)
+	module := &fakeModule{mem: mem}
+	urlPtr, urlSize := mem.put(16, server.URL)
+
+	request := func(ctx context.Context, method string) uint32 {
+		methodPtr, methodSize := mem.put(0, method)
+		return executor.httpRequestWithHeaders(ctx, module, methodPtr, methodSize, urlPtr, urlSize, 0, 0, 0, 0, 0)
+	}
+
+	readOnly := withAllowedHTTPMethods(context.Background(), map[string]interface{}{
+		"allowed_http_methods": []interface{}{"get"},
+	})
+
+	t.Run("restricted module cannot DELETE", func(t *testing.T) {
+		assert.Equal(t, uint32(0xFFFFFFF9), request(readOnly, "DELETE"))
+	})
+
+	t.Run("restricted module can GET", func(t *testing.T) {
+		assert.Equal(t, uint32(0), request(readOnly, "GET"))
+		assert.Equal(t, "GET", string(executor.lastResponseBody[moduleKey(module)]))
+	})
+
+	t.Run("unrestricted module can DELETE", func(t *testing.T) {
+		ctx := withAllowedHTTPMethods(context.Background(), map[string]interface{}{"other": true})
+		assert.Equal(t, uint32(0), request(ctx, "DELETE"))
+	})
+
+	t.Run("comma-separated config", func(t *testing.T) {
+		ctx := withAllowedHTTPMethods(context.Background(), map[string]interface{}{"allowed_http_methods": "GET, post"})
+		assert.True(t, httpMethodAllowed(ctx, "POST"))
+		assert.False(t, httpMethodAllowed(ctx, "PATCH"))
+	})
+}
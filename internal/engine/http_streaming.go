@@ -0,0 +1,256 @@
+package engine
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/tetratelabs/wazero/api"
+)
+
+// maxResponseChunkSize caps how much read_response_chunk reads in one call,
+// whatever buffer size the module passes
+const maxResponseChunkSize = 1 << 20
+
+// streamingClient is used for streaming requests. It has no overall timeout,
+// since reading a large body can take a long time, but still bounds the wait
+// for response headers. The request context cancels the body read.
+var streamingClient = func() *http.Client {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.ResponseHeaderTimeout = 30 * time.Second
+	return &http.Client{Transport: transport}
+}()
+
+// responseStreams holds the open response bodies of streaming requests, keyed
+// by module instance
+type responseStreams struct {
+	mu        sync.Mutex
+	responses map[string]*http.Response
+}
+
+func newResponseStreams() *responseStreams {
+	return &responseStreams{responses: make(map[string]*http.Response)}
+}
+
+// get returns the open response for key
+func (s *responseStreams) get(key string) (*http.Response, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	resp, ok := s.responses[key]
+	return resp, ok
+}
+
+// open stores resp for key, closing any response it replaces
+func (s *responseStreams) open(key string, resp *http.Response) {
+	s.mu.Lock()
+	previous := s.responses[key]
+	s.responses[key] = resp
+	s.mu.Unlock()
+
+	if previous != nil {
+		closeResponseBody(previous)
+	}
+}
+
+// close closes and removes the response for key, reporting whether one was open
+func (s *responseStreams) close(key string) bool {
+	s.mu.Lock()
+	resp, ok := s.responses[key]
+	delete(s.responses, key)
+	s.mu.Unlock()
+
+	if ok {
+		closeResponseBody(resp)
+	}
+	return ok
+}
+
+func closeResponseBody(resp *http.Response) {
+	if err := resp.Body.Close(); err != nil {
+		log.Printf("Failed to close response body: %v", err)
+	}
+}
+
+// moduleKey identifies a module instance in the per-module executor state
+func moduleKey(module api.Module) string {
+	return fmt.Sprintf("%p", module)
+}
+
+// httpRequestStreaming implements the http_request_streaming host function.
+// It takes the same arguments and returns the same error codes as
+// http_request_with_headers, but leaves the response body open so the module
+// can pull it with read_response_chunk instead of buffering it in memory. The
+// status and headers are available through get_last_response_status and
+// get_last_response_header.
+func (e *WASMExecutor) httpRequestStreaming(ctx context.Context, module api.Module, methodPtr, methodSize, urlPtr, urlSize, bodyPtr, bodySize, headersPtr, headersSize uint32) uint32 {
+	// Check for context cancellation before processing
+	select {
+	case <-ctx.Done():
+		// Return error code for cancellation
+		return 0xFFFFFFFA
+	default:
+	}
+
+	// Get memory from the module
+	mem := module.Memory()
+
+	// Read method from WASM memory
+	method, err := readStringFromMemory(ctx, mem, methodPtr, methodSize)
+	if err != nil {
+		log.Printf("Failed to read HTTP method from WASM memory: %v", err)
+		// Return error code (0xFFFFFFF0)
+		return 0xFFFFFFF0
+	}
+
+	// Validate method before doing any other work
+	method, ok := normalizeHTTPMethod(method)
+	if !ok {
+		log.Printf("Unsupported HTTP method: %q", method)
+		// Return error code (0xFFFFFFF8)
+		return 0xFFFFFFF8
+	}
+	if !httpMethodAllowed(ctx, method) {
+		log.Printf("HTTP method %s not allowed for this module", method)
+		// Return error code (0xFFFFFFF9)
+		return 0xFFFFFFF9
+	}
+
+	// Read URL from WASM memory
+	urlStr, err := readStringFromMemory(ctx, mem, urlPtr, urlSize)
+	if err != nil {
+		log.Printf("Failed to read URL from WASM memory: %v", err)
+		// Return error code (0xFFFFFFFF)
+		return 0xFFFFFFFF
+	}
+
+	// Validate URL
+	if !e.isURLAllowed(urlStr) || !moduleURLAllowed(ctx, urlStr) {
+		log.Printf("URL not allowed: %s", urlStr)
+		// Return error code (0xFFFFFFFE)
+		return 0xFFFFFFFE
+	}
+
+	// Read body from WASM memory (can be empty for GET requests)
+	var bodyReader io.Reader
+	if bodySize > 0 {
+		bodyStr, err := readStringFromMemory(ctx, mem, bodyPtr, bodySize)
+		if err != nil {
+			log.Printf("Failed to read HTTP body from WASM memory: %v", err)
+			// Return error code (0xFFFFFFF1)
+			return 0xFFFFFFF1
+		}
+		bodyReader = strings.NewReader(bodyStr)
+	}
+
+	// Read headers from WASM memory (can be empty)
+	var headers map[string]string
+	if headersSize > 0 {
+		headersStr, err := readStringFromMemory(ctx, mem, headersPtr, headersSize)
+		if err != nil {
+			log.Printf("Failed to read HTTP headers from WASM memory: %v", err)
+			// Return error code (0xFFFFFFF2)
+			return 0xFFFFFFF2
+		}
+
+		if err := json.Unmarshal([]byte(headersStr), &headers); err != nil {
+			log.Printf("Failed to parse HTTP headers JSON: %v", err)
+			// Return error code (0xFFFFFFF3)
+			return 0xFFFFFFF3
+		}
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, urlStr, bodyReader)
+	if err != nil {
+		log.Printf("Failed to create HTTP request for URL %s: %v", urlStr, err)
+		// Return error code (0xFFFFFFFD)
+		return 0xFFFFFFFD
+	}
+
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+
+	// Set Content-Type header for requests with a body if not already set
+	if bodyReader != nil && e.defaultsToJSON(method) && req.Header.Get("Content-Type") == "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := streamingClient.Do(req)
+	if err != nil {
+		log.Printf("Failed to make HTTP request to %s: %v", urlStr, err)
+		// Return error code (0xFFFFFFFC)
+		return 0xFFFFFFFC
+	}
+
+	// Keep the body open for read_response_chunk. The buffered body of any
+	// earlier request is dropped so get_last_response_body does not return it.
+	key := moduleKey(module)
+	e.streams.open(key, resp)
+	e.lastResponse[key] = resp
+	delete(e.lastResponseBody, key)
+
+	log.Printf("HTTP %s streaming request to %s started with status %d", method, urlStr, resp.StatusCode)
+
+	// Return 0 for success
+	return 0
+}
+
+// readResponseChunk implements the read_response_chunk host function. It
+// copies the next part of the streaming response body into the module's
+// buffer and returns the number of bytes written, or 0 once the body is
+// exhausted. At most maxResponseChunkSize bytes are read per call.
+func (e *WASMExecutor) readResponseChunk(ctx context.Context, module api.Module, bufferPtr, bufferSize uint32) uint32 {
+	// Check for context cancellation before processing
+	select {
+	case <-ctx.Done():
+		// Return error code for cancellation
+		return 0xFFFFFFFA
+	default:
+	}
+
+	resp, ok := e.streams.get(moduleKey(module))
+	if !ok {
+		log.Printf("No streaming response available for module %s", moduleKey(module))
+		// Return error code (0xFFFFFFF4)
+		return 0xFFFFFFF4
+	}
+
+	if bufferSize == 0 {
+		log.Printf("Buffer too small for response chunk")
+		// Return error code (0xFFFFFFF5)
+		return 0xFFFFFFF5
+	}
+
+	chunk := make([]byte, min(bufferSize, maxResponseChunkSize))
+	n, err := io.ReadFull(resp.Body, chunk)
+	if err != nil && !errors.Is(err, io.EOF) && !errors.Is(err, io.ErrUnexpectedEOF) {
+		log.Printf("Failed to read response chunk: %v", err)
+		// Return error code (0xFFFFFFFB)
+		return 0xFFFFFFFB
+	}
+
+	if n > 0 && !module.Memory().Write(bufferPtr, chunk[:n]) {
+		log.Printf("Failed to write response chunk to WASM memory")
+		// Return error code (0xFFFFFFF6)
+		return 0xFFFFFFF6
+	}
+
+	return uint32(n)
+}
+
+// closeResponse implements the close_response host function, releasing the
+// module's streaming response before the module finishes
+func (e *WASMExecutor) closeResponse(ctx context.Context, module api.Module) uint32 {
+	if !e.streams.close(moduleKey(module)) {
+		// Return error code (0xFFFFFFF4) - No streaming response
+		return 0xFFFFFFF4
+	}
+	return 0
+}
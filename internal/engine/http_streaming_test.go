@@ -0,0 +1,80 @@
+package engine
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHTTPRequestStreaming(t *testing.T) {
+	const payloadSize = 5 << 20
+	payload := bytes.Repeat([]byte("0123456789abcdef"), payloadSize/16)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "token", r.Header.Get("Authorization"))
+		flusher := w.(http.Flusher)
+		// Write in pieces so the body arrives in several reads
+		for offset := 0; offset < len(payload); offset += 256 << 10 {
+			_, _ = w.Write(payload[offset:min(offset+256<<10, len(payload))])
+			flusher.Flush()
+		}
+	}))
+	defer server.Close()
+
+	executor := NewWASMExecutor(nil, &MockPrimitiveStore{}, nil, nil)
+	mem := newFakeMemory(128 << 10)
+	module := &fakeModule{mem: mem}
+	ctx := context.Background()
+
+	methodPtr, methodSize := mem.put(0, "get")
+	urlPtr, urlSize := mem.put(16, server.URL)
+	headersPtr, headersSize := mem.put(256, `{"Authorization": "token"}`)
+
+	result := executor.httpRequestStreaming(ctx, module, methodPtr, methodSize, urlPtr, urlSize, 0, 0, headersPtr, headersSize)
+	require.Equal(t, uint32(0), result)
+
+	const bufferPtr, bufferSize = 1024, 64 << 10
+	var received bytes.Buffer
+	for {
+		n := executor.readResponseChunk(ctx, module, bufferPtr, bufferSize)
+		require.Less(t, n, uint32(0xFFFFFFF0), "unexpected error code %#x", n)
+		if n == 0 {
+			break
+		}
+		require.LessOrEqual(t, n, uint32(bufferSize))
+		received.Write(mem.buf[bufferPtr : bufferPtr+n])
+	}
+
+	require.Equal(t, len(payload), received.Len())
+	assert.Equal(t, sha256.Sum256(payload), sha256.Sum256(received.Bytes()))
+
+	assert.Equal(t, uint32(0), executor.closeResponse(ctx, module))
+	assert.Equal(t, uint32(0xFFFFFFF4), executor.readResponseChunk(ctx, module, bufferPtr, bufferSize))
+	assert.Equal(t, uint32(0xFFFFFFF4), executor.closeResponse(ctx, module))
+}
+
+func TestHTTPRequestStreamingRejectsInvalidRequests(t *testing.T) {
+	executor := NewWASMExecutor(nil, &MockPrimitiveStore{}, nil, nil)
+	executor.SetURLAllowList([]string{"https://api.example.com/"})
+	mem := newFakeMemory(256)
+	module := &fakeModule{mem: mem}
+	ctx := context.Background()
+
+	urlPtr, urlSize := mem.put(16, "https://api.example.com/data")
+
+	methodPtr, methodSize := mem.put(0, "TRACE")
+	assert.Equal(t, uint32(0xFFFFFFF8), executor.httpRequestStreaming(ctx, module, methodPtr, methodSize, urlPtr, urlSize, 0, 0, 0, 0))
+
+	methodPtr, methodSize = mem.put(0, "GET")
+	urlPtr, urlSize = mem.put(16, "https://other.example.com/data")
+	assert.Equal(t, uint32(0xFFFFFFFE), executor.httpRequestStreaming(ctx, module, methodPtr, methodSize, urlPtr, urlSize, 0, 0, 0, 0))
+
+	// No request has been streamed
+	assert.Equal(t, uint32(0xFFFFFFF4), executor.readResponseChunk(ctx, module, 0, 16))
+}
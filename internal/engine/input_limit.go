@@ -0,0 +1,174 @@
+package engine
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/mule-ai/mule/internal/primitive"
+)
+
+// Input size policies applied when a step input exceeds its limit
+const (
+	InputSizePolicyTruncate = "truncate"
+	InputSizePolicyFail     = "fail"
+)
+
+// Default step input limit, used when the settings are missing or invalid
+const defaultStepMaxInputBytes = 1024 * 1024
+
+// inputLimit is the maximum step input size and the policy for oversized input.
+// A MaxBytes of zero or less disables the limit.
+type inputLimit struct {
+	MaxBytes int
+	Policy   string
+}
+
+// loadInputLimit reads the global step input limit from the settings
+func loadInputLimit(settings []*primitive.Setting) inputLimit {
+	limit := inputLimit{MaxBytes: defaultStepMaxInputBytes, Policy: InputSizePolicyTruncate}
+
+	for _, setting := range settings {
+		switch setting.Key {
+		case "step_max_input_bytes":
+			if val, err := strconv.Atoi(setting.Value); err == nil {
+				limit.MaxBytes = val
+			}
+		case "step_input_size_policy":
+			if policy := strings.ToLower(strings.TrimSpace(setting.Value)); isValidInputSizePolicy(policy) {
+				limit.Policy = policy
+			}
+		}
+	}
+
+	return limit
+}
+
+// stepInputLimit applies the per-step overrides from the step config
+// (max_input_bytes and input_size_policy) on top of the global defaults
+func stepInputLimit(step *primitive.WorkflowStep, defaults inputLimit) inputLimit {
+	limit := defaults
+	if step.Config == nil {
+		return limit
+	}
+
+	switch v := step.Config["max_input_bytes"].(type) {
+	case float64:
+		limit.MaxBytes = int(v)
+	case int:
+		limit.MaxBytes = v
+	case string:
+		if val, err := strconv.Atoi(v); err == nil {
+			limit.MaxBytes = val
+		}
+	}
+
+	if policy, ok := step.Config["input_size_policy"].(string); ok {
+		if policy = strings.ToLower(strings.TrimSpace(policy)); isValidInputSizePolicy(policy) {
+			limit.Policy = policy
+		}
+	}
+
+	return limit
+}
+
+func isValidInputSizePolicy(policy string) bool {
+	return policy == InputSizePolicyTruncate || policy == InputSizePolicyFail
+}
+
+// applyInputLimit enforces the limit on a step input, measured as the size of
+// the whole input encoded as JSON. When the input is within the limit it is
+// returned unchanged with a nil record. Otherwise the returned record describes
+// the action taken, for storing in the step result.
+//
+// Only a string prompt can be truncated, and only when shortening it brings
+// the whole input within the limit; any other oversized input fails regardless
+// of policy since there is no meaningful way to shorten it.
+func applyInputLimit(input map[string]interface{}, limit inputLimit) (map[string]interface{}, map[string]interface{}, error) {
+	if limit.MaxBytes <= 0 {
+		return input, nil, nil
+	}
+
+	encoded, err := json.Marshal(input)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to measure step input: %w", err)
+	}
+	size := len(encoded)
+
+	if size <= limit.MaxBytes {
+		return input, nil, nil
+	}
+
+	record := map[string]interface{}{
+		"policy":         limit.Policy,
+		"original_bytes": size,
+		"max_bytes":      limit.MaxBytes,
+	}
+
+	if prompt, isString := input["prompt"].(string); isString && limit.Policy == InputSizePolicyTruncate {
+		if limited, limitedSize, ok := truncatePromptToFit(input, prompt, size, limit.MaxBytes); ok {
+			record["action"] = "truncated"
+			record["truncated_bytes"] = limitedSize
+			return limited, record, nil
+		}
+	}
+
+	record["action"] = "failed"
+	return nil, record, fmt.Errorf("step input is %d bytes, exceeding the limit of %d bytes", size, limit.MaxBytes)
+}
+
+// truncatePromptToFit shortens the prompt so the whole input encodes to at most
+// maxBytes, returning the shortened copy and its encoded size. It reports false
+// when the rest of the input is too large on its own.
+func truncatePromptToFit(input map[string]interface{}, prompt string, size, maxBytes int) (map[string]interface{}, int, bool) {
+	promptJSON, err := json.Marshal(prompt)
+	if err != nil {
+		return nil, 0, false
+	}
+
+	// Room left for the prompt's contents between its quotes. Escaping can
+	// make the encoded prompt longer than its raw bytes, so shrink the room
+	// by any overshoot until the input fits.
+	room := maxBytes - (size - len(promptJSON)) - 2
+	for room > 0 {
+		limited := make(map[string]interface{}, len(input))
+		for k, v := range input {
+			limited[k] = v
+		}
+		limited["prompt"] = truncateWithNotice(prompt, room)
+
+		encoded, err := json.Marshal(limited)
+		if err != nil {
+			return nil, 0, false
+		}
+		if len(encoded) <= maxBytes {
+			return limited, len(encoded), true
+		}
+		room -= len(encoded) - maxBytes
+	}
+
+	return nil, 0, false
+}
+
+// truncateWithNotice shortens s to at most maxBytes, ending on a UTF-8 boundary
+// and with a notice appended when there is room for one
+func truncateWithNotice(s string, maxBytes int) string {
+	if len(s) <= maxBytes {
+		return s
+	}
+
+	notice := fmt.Sprintf("\n\n[input truncated from %d bytes]", len(s))
+	keep := maxBytes - len(notice)
+	if keep <= 0 {
+		notice = ""
+		keep = maxBytes
+	}
+
+	for keep > 0 && !utf8.RuneStart(s[keep]) {
+		keep--
+	}
+
+	return s[:keep] + notice
+}
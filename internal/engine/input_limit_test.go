@@ -0,0 +1,144 @@
+package engine
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mule-ai/mule/internal/primitive"
+)
+
+func TestLoadInputLimit(t *testing.T) {
+	t.Run("defaults when settings missing", func(t *testing.T) {
+		limit := loadInputLimit(nil)
+		assert.Equal(t, defaultStepMaxInputBytes, limit.MaxBytes)
+		assert.Equal(t, InputSizePolicyTruncate, limit.Policy)
+	})
+
+	t.Run("reads settings and ignores invalid policy", func(t *testing.T) {
+		limit := loadInputLimit([]*primitive.Setting{
+			{Key: "step_max_input_bytes", Value: "2048"},
+			{Key: "step_input_size_policy", Value: "explode"},
+		})
+		assert.Equal(t, 2048, limit.MaxBytes)
+		assert.Equal(t, InputSizePolicyTruncate, limit.Policy)
+	})
+}
+
+func TestStepInputLimit(t *testing.T) {
+	defaults := inputLimit{MaxBytes: 1000, Policy: InputSizePolicyTruncate}
+
+	step := &primitive.WorkflowStep{Config: map[string]interface{}{
+		"max_input_bytes":   float64(10), // JSON numbers decode as float64
+		"input_size_policy": "FAIL",
+	}}
+	limit := stepInputLimit(step, defaults)
+	assert.Equal(t, 10, limit.MaxBytes)
+	assert.Equal(t, InputSizePolicyFail, limit.Policy)
+
+	assert.Equal(t, defaults, stepInputLimit(&primitive.WorkflowStep{}, defaults))
+}
+
+func TestApplyInputLimit(t *testing.T) {
+	oversized := map[string]interface{}{
+		"prompt": strings.Repeat("a", 500),
+		"extra":  "kept",
+	}
+
+	t.Run("passes input within limit unchanged", func(t *testing.T) {
+		input := map[string]interface{}{"prompt": "short"}
+		out, record, err := applyInputLimit(input, inputLimit{MaxBytes: 100, Policy: InputSizePolicyFail})
+		require.NoError(t, err)
+		assert.Nil(t, record)
+		assert.Equal(t, input, out)
+	})
+
+	t.Run("truncates oversized prompt with notice", func(t *testing.T) {
+		out, record, err := applyInputLimit(oversized, inputLimit{MaxBytes: 100, Policy: InputSizePolicyTruncate})
+		require.NoError(t, err)
+
+		prompt := out["prompt"].(string)
+		assert.Contains(t, prompt, "[input truncated from 500 bytes]")
+		assert.Equal(t, "kept", out["extra"])
+		assert.LessOrEqual(t, encodedSize(t, out), 100, "the whole input fits")
+
+		assert.Equal(t, "truncated", record["action"])
+		assert.Equal(t, encodedSize(t, oversized), record["original_bytes"])
+		assert.Equal(t, encodedSize(t, out), record["truncated_bytes"])
+		assert.Equal(t, 100, record["max_bytes"])
+
+		// The original input must not be modified
+		assert.Len(t, oversized["prompt"], 500)
+	})
+
+	t.Run("fails oversized prompt under fail policy", func(t *testing.T) {
+		out, record, err := applyInputLimit(oversized, inputLimit{MaxBytes: 100, Policy: InputSizePolicyFail})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "exceeding the limit of 100 bytes")
+		assert.Nil(t, out)
+		assert.Equal(t, "failed", record["action"])
+	})
+
+	t.Run("fails oversized structured input even when truncating", func(t *testing.T) {
+		input := map[string]interface{}{"items": []string{strings.Repeat("b", 200)}}
+		_, record, err := applyInputLimit(input, inputLimit{MaxBytes: 100, Policy: InputSizePolicyTruncate})
+		require.Error(t, err)
+		assert.Equal(t, "failed", record["action"])
+	})
+
+	t.Run("measures fields other than the prompt", func(t *testing.T) {
+		input := map[string]interface{}{"prompt": "short", "context": strings.Repeat("c", 500)}
+		for _, policy := range []string{InputSizePolicyTruncate, InputSizePolicyFail} {
+			out, record, err := applyInputLimit(input, inputLimit{MaxBytes: 100, Policy: policy})
+			require.Error(t, err, policy)
+			assert.Nil(t, out)
+			assert.Equal(t, "failed", record["action"])
+			assert.Equal(t, encodedSize(t, input), record["original_bytes"])
+		}
+	})
+
+	t.Run("fails when truncating the prompt is not enough", func(t *testing.T) {
+		input := map[string]interface{}{"prompt": strings.Repeat("a", 500), "context": strings.Repeat("c", 150)}
+		_, record, err := applyInputLimit(input, inputLimit{MaxBytes: 100, Policy: InputSizePolicyTruncate})
+		require.Error(t, err)
+		assert.Equal(t, "failed", record["action"])
+	})
+
+	t.Run("accounts for escaping in the prompt", func(t *testing.T) {
+		input := map[string]interface{}{"prompt": strings.Repeat(`"`, 300)}
+		out, _, err := applyInputLimit(input, inputLimit{MaxBytes: 100, Policy: InputSizePolicyTruncate})
+		require.NoError(t, err)
+		assert.LessOrEqual(t, encodedSize(t, out), 100)
+	})
+
+	t.Run("zero disables the limit", func(t *testing.T) {
+		out, record, err := applyInputLimit(oversized, inputLimit{MaxBytes: 0, Policy: InputSizePolicyFail})
+		require.NoError(t, err)
+		assert.Nil(t, record)
+		assert.Equal(t, oversized, out)
+	})
+}
+
+func TestTruncateWithNoticeKeepsValidUTF8(t *testing.T) {
+	s := strings.Repeat("é", 100) // 2 bytes per rune
+	out := truncateWithNotice(s, 51)
+	assert.True(t, utf8.ValidString(out))
+	assert.LessOrEqual(t, len(out), 51)
+
+	// Too small for the notice: plain truncation
+	out = truncateWithNotice(s, 5)
+	assert.True(t, utf8.ValidString(out))
+	assert.Equal(t, "éé", out)
+}
+
+// encodedSize returns the size of v encoded as JSON
+func encodedSize(t *testing.T, v interface{}) int {
+	t.Helper()
+	data, err := json.Marshal(v)
+	require.NoError(t, err)
+	return len(data)
+}
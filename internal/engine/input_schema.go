@@ -0,0 +1,75 @@
+package engine
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/santhosh-tekuri/jsonschema/v6"
+)
+
+// inputSchemaConfigKey is the module config key holding the optional JSON
+// Schema its input must match
+const inputSchemaConfigKey = "input_schema"
+
+// validateInputSchema checks a module's input against the JSON Schema in its
+// input_schema config, if any. The returned error lists every offending field,
+// for example
+//
+//	{"input_schema": {"type": "object", "required": ["prompt"]}}
+func validateInputSchema(config map[string]interface{}, input map[string]interface{}) error {
+	rawSchema, ok := config[inputSchemaConfigKey]
+	if !ok || rawSchema == nil {
+		return nil
+	}
+
+	// Round-trip both documents through JSON so Go values such as ints and
+	// string slices are seen the way the module will see them
+	schemaDoc, err := toJSONValue(rawSchema)
+	if err != nil {
+		return fmt.Errorf("invalid input_schema: %w", err)
+	}
+	instance, err := toJSONValue(input)
+	if err != nil {
+		return fmt.Errorf("failed to encode input for validation: %w", err)
+	}
+
+	compiler := jsonschema.NewCompiler()
+	if err := compiler.AddResource(inputSchemaConfigKey+".json", schemaDoc); err != nil {
+		return fmt.Errorf("invalid input_schema: %w", err)
+	}
+	schema, err := compiler.Compile(inputSchemaConfigKey + ".json")
+	if err != nil {
+		return fmt.Errorf("invalid input_schema: %w", err)
+	}
+
+	err = schema.Validate(instance)
+	var validationErr *jsonschema.ValidationError
+	if !errors.As(err, &validationErr) {
+		return err
+	}
+
+	var problems []string
+	for _, unit := range validationErr.BasicOutput().Errors {
+		if unit.Error == nil {
+			continue
+		}
+		location := unit.InstanceLocation
+		if location == "" {
+			location = "(root)"
+		}
+		problems = append(problems, fmt.Sprintf("%s: %s", location, unit.Error))
+	}
+	return fmt.Errorf("input does not match the module's input_schema: %s", strings.Join(problems, "; "))
+}
+
+// toJSONValue converts v to the generic form produced by decoding JSON
+func toJSONValue(v interface{}) (interface{}, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	return jsonschema.UnmarshalJSON(bytes.NewReader(data))
+}
@@ -0,0 +1,75 @@
+package engine
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mule-ai/mule/internal/primitive"
+)
+
+func TestValidateInputSchema(t *testing.T) {
+	schema := map[string]interface{}{
+		"type":     "object",
+		"required": []interface{}{"prompt"},
+		"properties": map[string]interface{}{
+			"prompt": map[string]interface{}{"type": "string"},
+			"count":  map[string]interface{}{"type": "integer", "minimum": float64(1)},
+		},
+	}
+	config := map[string]interface{}{inputSchemaConfigKey: schema}
+
+	t.Run("no schema", func(t *testing.T) {
+		assert.NoError(t, validateInputSchema(map[string]interface{}{}, map[string]interface{}{}))
+	})
+
+	t.Run("valid input", func(t *testing.T) {
+		assert.NoError(t, validateInputSchema(config, map[string]interface{}{"prompt": "hi", "count": 2}))
+	})
+
+	t.Run("missing required field", func(t *testing.T) {
+		err := validateInputSchema(config, map[string]interface{}{"token": "x"})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "prompt")
+	})
+
+	t.Run("lists every offending field", func(t *testing.T) {
+		err := validateInputSchema(config, map[string]interface{}{"prompt": 5, "count": 0})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "/prompt")
+		assert.Contains(t, err.Error(), "/count")
+	})
+
+	t.Run("invalid schema", func(t *testing.T) {
+		err := validateInputSchema(map[string]interface{}{inputSchemaConfigKey: map[string]interface{}{"type": 5}}, map[string]interface{}{})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "invalid input_schema")
+	})
+}
+
+func TestExecuteValidatesInputSchema(t *testing.T) {
+	store := &MockPrimitiveStore{WasmModules: []*primitive.WasmModuleListItem{{
+		ID:   "needs-prompt",
+		Name: "needs-prompt",
+		Config: map[string]interface{}{
+			inputSchemaConfigKey: map[string]interface{}{
+				"type":     "object",
+				"required": []interface{}{"prompt"},
+			},
+		},
+	}}}
+	executor := NewWASMExecutor(nil, store, nil, nil)
+	executor.Modules()["needs-prompt"] = stderrModule("ran", 0)
+	ctx := context.Background()
+
+	_, err := executor.Execute(ctx, "needs-prompt", map[string]interface{}{"token": "x"}, "")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "input does not match the module's input_schema")
+	assert.Contains(t, err.Error(), "prompt")
+
+	result, err := executor.Execute(ctx, "needs-prompt", map[string]interface{}{"prompt": "hello"}, "")
+	require.NoError(t, err)
+	assert.Equal(t, "ran", result["stderr"])
+}
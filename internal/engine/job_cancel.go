@@ -0,0 +1,125 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+
+	"github.com/tetratelabs/wazero/api"
+
+	"github.com/mule-ai/mule/pkg/job"
+)
+
+// ErrJobFinished is returned when cancelling a job that has already completed,
+// failed or been cancelled
+var ErrJobFinished = errors.New("job has already finished")
+
+// Causes for cancelling a running job's context. Only errJobCancelled means
+// the job was cancelled on request and has already been marked so.
+var (
+	errJobCancelled  = errors.New("job was cancelled")
+	errEngineStopped = errors.New("workflow engine stopped")
+)
+
+// trackJob records the cancel function of a running job's context so that
+// CancelJob can stop it mid-step. The returned function forgets it again.
+func (e *Engine) trackJob(jobID string, cancel context.CancelCauseFunc) func() {
+	e.jobsMu.Lock()
+	if e.runningJobs == nil {
+		e.runningJobs = make(map[string]context.CancelCauseFunc)
+	}
+	e.runningJobs[jobID] = cancel
+	e.jobsMu.Unlock()
+
+	return func() {
+		e.jobsMu.Lock()
+		delete(e.runningJobs, jobID)
+		e.jobsMu.Unlock()
+	}
+}
+
+// CancelJob cancels a queued or running job. The job is marked cancelled and,
+// if it is running, its context is cancelled so the current step and any
+// in-flight model or HTTP calls stop. It returns job.ErrJobNotFound for an
+// unknown job and ErrJobFinished for one that has already finished.
+func (e *Engine) CancelJob(jobID string) error {
+	current, err := e.jobStore.GetJob(jobID)
+	if err != nil {
+		return err
+	}
+	if current.Status.IsTerminal() {
+		return ErrJobFinished
+	}
+
+	if err := e.jobStore.CancelJob(jobID); err != nil {
+		return fmt.Errorf("failed to cancel job: %w", err)
+	}
+
+	e.jobsMu.Lock()
+	cancel, running := e.runningJobs[jobID]
+	e.jobsMu.Unlock()
+	if running {
+		cancel(errJobCancelled)
+	} else {
+		// A queued job will never run, so it no longer holds its step budget
+		e.releaseStepBudget(e.rootJobID(jobID), jobID)
+	}
+
+	log.Printf("Cancelled job %s", jobID)
+	return nil
+}
+
+// cancelRunningJobs cancels the context of every running job with cause
+func (e *Engine) cancelRunningJobs(cause error) {
+	e.jobsMu.Lock()
+	defer e.jobsMu.Unlock()
+	for _, cancel := range e.runningJobs {
+		cancel(cause)
+	}
+}
+
+// cancelJob implements the cancel_job host function, letting a module cancel
+// a job it started with execute_target
+func (e *WASMExecutor) cancelJob(ctx context.Context, module api.Module, jobIDPtr, jobIDSize uint32) uint32 {
+	// Check for context cancellation before processing
+	select {
+	case <-ctx.Done():
+		// Return error code for cancellation
+		return 0xFFFFFFFA
+	default:
+	}
+
+	// Read job ID from WASM memory
+	jobID, err := readStringFromMemory(ctx, module.Memory(), jobIDPtr, jobIDSize)
+	if err != nil || jobID == "" {
+		log.Printf("Failed to read job ID from WASM memory: %v", err)
+		// Return error code (0xFFFFFFF0)
+		return 0xFFFFFFF0
+	}
+
+	if e.WorkflowEngine == nil {
+		log.Printf("Workflow engine not available to cancel job %s", jobID)
+		// Return error code (0xFFFFFFF3)
+		return 0xFFFFFFF3
+	}
+
+	err = e.WorkflowEngine.CancelJob(jobID)
+	switch {
+	case err == nil:
+		// Return 0 for success
+		return 0
+	case errors.Is(err, job.ErrJobNotFound):
+		log.Printf("Job %s not found", jobID)
+		// Return error code (0xFFFFFFF1)
+		return 0xFFFFFFF1
+	case errors.Is(err, ErrJobFinished):
+		log.Printf("Job %s has already finished", jobID)
+		// Return error code (0xFFFFFFF2)
+		return 0xFFFFFFF2
+	default:
+		log.Printf("Failed to cancel job %s: %v", jobID, err)
+		// Return error code (0xFFFFFFF3)
+		return 0xFFFFFFF3
+	}
+}
@@ -0,0 +1,210 @@
+package engine
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mule-ai/mule/internal/primitive"
+	"github.com/mule-ai/mule/pkg/job"
+)
+
+// syncJobStore guards a MockJobStore so a job can be cancelled while
+// processJob runs it. GetJob returns copies for the same reason.
+type syncJobStore struct {
+	*MockJobStore
+	mu sync.Mutex
+}
+
+func (s *syncJobStore) GetJob(id string) (*job.Job, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	j, err := s.MockJobStore.GetJob(id)
+	if err != nil {
+		return nil, err
+	}
+	copied := *j
+	return &copied, nil
+}
+
+func (s *syncJobStore) UpdateJob(j *job.Job) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.MockJobStore.UpdateJob(j)
+}
+
+func (s *syncJobStore) CreateJobStep(step *job.JobStep) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.MockJobStore.CreateJobStep(step)
+}
+
+func (s *syncJobStore) GetNextQueuedJob() (*job.Job, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.MockJobStore.GetNextQueuedJob()
+}
+
+func (s *syncJobStore) MarkJobRunning(jobID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.MockJobStore.MarkJobRunning(jobID)
+}
+
+func (s *syncJobStore) MarkJobCompleted(jobID string, outputData map[string]interface{}) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.MockJobStore.MarkJobCompleted(jobID, outputData)
+}
+
+func (s *syncJobStore) MarkJobFailed(jobID string, err error) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.MockJobStore.MarkJobFailed(jobID, err)
+}
+
+func (s *syncJobStore) CancelJob(jobID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.MockJobStore.CancelJob(jobID)
+}
+
+func (s *syncJobStore) stepCount() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.Steps)
+}
+
+func TestEngineCancelJobStopsRunningJob(t *testing.T) {
+	// The step's module does not exist, so it fails and then waits an hour to
+	// retry, standing in for a long-running step
+	moduleID := "missing-module"
+	mockStore := &MockPrimitiveStore{
+		Workflows: []*primitive.Workflow{{ID: "workflow-slow", Name: "Slow"}},
+		WorkflowSteps: []*primitive.WorkflowStep{
+			{ID: "step-1", WorkflowID: "workflow-slow", StepOrder: 1, StepType: "wasm_module", WasmModuleID: &moduleID, Config: map[string]interface{}{"max_retries": float64(3), "retry_backoff": "1h"}},
+		},
+	}
+	jobStore := &syncJobStore{MockJobStore: &MockJobStore{
+		Jobs: map[string]*job.Job{
+			"job-slow": {ID: "job-slow", WorkflowID: "workflow-slow", Status: job.StatusQueued, CreatedAt: time.Now()},
+		},
+	}}
+	engine := NewEngine(mockStore, jobStore, nil, NewWASMExecutor(nil, mockStore, nil, nil), Config{Workers: 1})
+
+	done := make(chan error, 1)
+	go func() { done <- engine.processJob(context.Background(), "job-slow") }()
+
+	require.Eventually(t, func() bool { return jobStore.stepCount() == 1 }, 5*time.Second, 10*time.Millisecond)
+	require.NoError(t, engine.CancelJob("job-slow"))
+
+	select {
+	case err := <-done:
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "cancelled")
+	case <-time.After(5 * time.Second):
+		t.Fatal("job did not stop after being cancelled")
+	}
+
+	cancelled, err := jobStore.GetJob("job-slow")
+	require.NoError(t, err)
+	assert.Equal(t, job.StatusCancelled, cancelled.Status)
+}
+
+func TestEngineStopEndsRunningJob(t *testing.T) {
+	// As above, the failing step's retry backoff stands in for a long step
+	moduleID := "missing-module"
+	mockStore := &MockPrimitiveStore{
+		Workflows: []*primitive.Workflow{{ID: "workflow-slow", Name: "Slow"}},
+		WorkflowSteps: []*primitive.WorkflowStep{
+			{ID: "step-1", WorkflowID: "workflow-slow", StepOrder: 1, StepType: "wasm_module", WasmModuleID: &moduleID, Config: map[string]interface{}{"max_retries": float64(3), "retry_backoff": "1h"}},
+		},
+	}
+	newJobStore := func() *syncJobStore {
+		return &syncJobStore{MockJobStore: &MockJobStore{
+			Jobs: map[string]*job.Job{
+				"job-slow": {ID: "job-slow", WorkflowID: "workflow-slow", Status: job.StatusQueued, CreatedAt: time.Now()},
+			},
+		}}
+	}
+	status := func(t *testing.T, jobStore *syncJobStore) job.Status {
+		j, err := jobStore.GetJob("job-slow")
+		require.NoError(t, err)
+		return j.Status
+	}
+
+	t.Run("engine stopped mid-job", func(t *testing.T) {
+		jobStore := newJobStore()
+		engine := NewEngine(mockStore, jobStore, nil, NewWASMExecutor(nil, mockStore, nil, nil), Config{Workers: 1})
+		require.NoError(t, engine.Start(context.Background()))
+
+		require.Eventually(t, func() bool { return jobStore.stepCount() == 1 }, 5*time.Second, 10*time.Millisecond)
+
+		stopped := make(chan struct{})
+		go func() {
+			engine.Stop()
+			close(stopped)
+		}()
+		select {
+		case <-stopped:
+		case <-time.After(5 * time.Second):
+			t.Fatal("engine did not stop while a job was running")
+		}
+
+		assert.Equal(t, job.StatusFailed, status(t, jobStore))
+	})
+
+	t.Run("parent context cancelled mid-job", func(t *testing.T) {
+		jobStore := newJobStore()
+		engine := NewEngine(mockStore, jobStore, nil, NewWASMExecutor(nil, mockStore, nil, nil), Config{Workers: 1})
+
+		ctx, cancel := context.WithCancel(context.Background())
+		done := make(chan error, 1)
+		go func() { done <- engine.processJob(ctx, "job-slow") }()
+
+		require.Eventually(t, func() bool { return jobStore.stepCount() == 1 }, 5*time.Second, 10*time.Millisecond)
+		cancel()
+
+		select {
+		case err := <-done:
+			require.Error(t, err)
+		case <-time.After(5 * time.Second):
+			t.Fatal("job did not stop after its context was cancelled")
+		}
+
+		assert.True(t, status(t, jobStore).IsTerminal(), "job must not be left running")
+	})
+}
+
+func TestCancelJobHostFunction(t *testing.T) {
+	jobStore := &MockJobStore{
+		Jobs: map[string]*job.Job{
+			"job-queued": {ID: "job-queued", Status: job.StatusQueued},
+			"job-done":   {ID: "job-done", Status: job.StatusCompleted},
+		},
+	}
+	store := &MockPrimitiveStore{}
+	executor := NewWASMExecutor(nil, store, nil, nil)
+	executor.WorkflowEngine = NewEngine(store, jobStore, nil, executor, Config{Workers: 1})
+
+	mem := newFakeMemory(256)
+	module := &fakeModule{mem: mem}
+	cancel := func(jobID string) uint32 {
+		ptr, size := mem.put(0, jobID)
+		return executor.cancelJob(context.Background(), module, ptr, size)
+	}
+
+	assert.Equal(t, uint32(0), cancel("job-queued"))
+	assert.Equal(t, job.StatusCancelled, jobStore.Jobs["job-queued"].Status)
+
+	assert.Equal(t, uint32(0xFFFFFFF1), cancel("job-missing"))
+	assert.Equal(t, uint32(0xFFFFFFF2), cancel("job-done"))
+	assert.Equal(t, uint32(0xFFFFFFF2), cancel("job-queued"), "a cancelled job has finished")
+
+	executor.WorkflowEngine = nil
+	assert.Equal(t, uint32(0xFFFFFFF3), cancel("job-queued"))
+}
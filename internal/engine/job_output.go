@@ -0,0 +1,52 @@
+package engine
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+
+	"github.com/tetratelabs/wazero/api"
+)
+
+// setJobOutput implements the set_job_output host function. It records a JSON
+// object as the module's structured output, which the engine uses as the step
+// output in place of whatever the module printed to stdout.
+func (e *WASMExecutor) setJobOutput(ctx context.Context, module api.Module, dataPtr, dataSize uint32) uint32 {
+	// Check for context cancellation before processing
+	select {
+	case <-ctx.Done():
+		// Return error code for cancellation
+		return 0xFFFFFFFA
+	default:
+	}
+
+	// Read output from WASM memory
+	data, ok := module.Memory().Read(dataPtr, dataSize)
+	if !ok || dataSize == 0 {
+		log.Printf("Failed to read job output from WASM memory")
+		// Return error code (0xFFFFFFF0)
+		return 0xFFFFFFF0
+	}
+
+	var output map[string]interface{}
+	if err := json.Unmarshal(data, &output); err != nil || output == nil {
+		log.Printf("Job output set by WASM module is not a JSON object: %v", err)
+		// Return error code (0xFFFFFFF1)
+		return 0xFFFFFFF1
+	}
+
+	e.jobOutputMu.Lock()
+	e.jobOutput[moduleKey(module)] = output
+	e.jobOutputMu.Unlock()
+	return 0
+}
+
+// takeJobOutput returns and forgets the output set by a module instance with
+// set_job_output
+func (e *WASMExecutor) takeJobOutput(key string) (map[string]interface{}, bool) {
+	e.jobOutputMu.Lock()
+	defer e.jobOutputMu.Unlock()
+	output, ok := e.jobOutput[key]
+	delete(e.jobOutput, key)
+	return output, ok
+}
@@ -0,0 +1,134 @@
+package engine
+
+import (
+	"context"
+	"encoding/binary"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mule-ai/mule/internal/primitive"
+	"github.com/mule-ai/mule/pkg/job"
+)
+
+// jobOutputModule assembles a WASM module whose _start passes output to
+// set_job_output and then prints stdout
+func jobOutputModule(output, stdout string) []byte {
+	types := []byte{
+		0x03,
+		0x60, 0x02, 0x7f, 0x7f, 0x01, 0x7f, // set_job_output
+		0x60, 0x04, 0x7f, 0x7f, 0x7f, 0x7f, 0x01, 0x7f, // fd_write
+		0x60, 0x00, 0x00, // _start
+	}
+	imports := concatBytes([]byte{0x02},
+		wasmName("env"), wasmName("set_job_output"), []byte{0x00, 0x00},
+		wasmName("wasi_snapshot_preview1"), wasmName("fd_write"), []byte{0x00, 0x01})
+	exports := concatBytes([]byte{0x02},
+		wasmName("_start"), []byte{0x00, 0x02},
+		wasmName("memory"), []byte{0x02, 0x00})
+
+	// Memory holds an iovec for stdout at 0, stdout at 16 and the output after it
+	outputPtr := 16 + len(stdout)
+	segment := make([]byte, 16)
+	binary.LittleEndian.PutUint32(segment[0:], 16)
+	binary.LittleEndian.PutUint32(segment[4:], uint32(len(stdout)))
+	segment = append(append(segment, stdout...), output...)
+
+	// set_job_output(outputPtr, len(output)); drop; fd_write(1, 0, 1, 8); drop
+	body := concatBytes([]byte{0x00},
+		[]byte{0x41}, wasmSLEB(outputPtr), []byte{0x41}, wasmSLEB(len(output)), []byte{0x10, 0x00, 0x1a},
+		[]byte{0x41, 0x01, 0x41, 0x00, 0x41, 0x01, 0x41, 0x08, 0x10, 0x01, 0x1a, 0x0b})
+
+	return wasmModule(
+		wasmSection(0x01, types...),
+		wasmSection(0x02, imports...),
+		wasmSection(0x03, 0x01, 0x02),
+		wasmSection(0x05, 0x01, 0x00, 0x01),
+		wasmSection(0x07, exports...),
+		wasmSection(0x0a, concatBytes([]byte{0x01}, wasmULEB(len(body)), body)...),
+		wasmSection(0x0b, concatBytes([]byte{0x01, 0x00, 0x41, 0x00, 0x0b}, wasmULEB(len(segment)), segment)...),
+	)
+}
+
+func TestSetJobOutput(t *testing.T) {
+	executor := NewWASMExecutor(nil, &MockPrimitiveStore{}, nil, nil)
+	mem := newFakeMemory(256)
+	module := &fakeModule{mem: mem}
+	ctx := context.Background()
+
+	t.Run("stores a JSON object", func(t *testing.T) {
+		ptr, size := mem.put(0, `{"count":2}`)
+		require.Equal(t, uint32(0), executor.setJobOutput(ctx, module, ptr, size))
+
+		output, ok := executor.takeJobOutput(moduleKey(module))
+		require.True(t, ok)
+		assert.Equal(t, map[string]interface{}{"count": float64(2)}, output)
+
+		_, ok = executor.takeJobOutput(moduleKey(module))
+		assert.False(t, ok, "output is only reported once")
+	})
+
+	t.Run("rejects anything but an object", func(t *testing.T) {
+		for _, data := range []string{`"text"`, `[1,2]`, `null`, `{not json`} {
+			ptr, size := mem.put(0, data)
+			assert.Equal(t, uint32(0xFFFFFFF1), executor.setJobOutput(ctx, module, ptr, size), data)
+		}
+	})
+
+	t.Run("cancelled context", func(t *testing.T) {
+		cancelled, cancel := context.WithCancel(ctx)
+		cancel()
+		assert.Equal(t, uint32(0xFFFFFFFA), executor.setJobOutput(cancelled, module, 0, 1))
+	})
+}
+
+func TestSetJobOutputIsWhatParentJobReceives(t *testing.T) {
+	moduleID := "module-issues"
+	store := &MockPrimitiveStore{
+		Workflows: []*primitive.Workflow{
+			{ID: "workflow-parent", Name: "Parent"},
+			{ID: "workflow-child", Name: "Fetch Issues"},
+		},
+		WorkflowSteps: []*primitive.WorkflowStep{
+			{ID: "step-parent", WorkflowID: "workflow-parent", StepOrder: 1, StepType: "subworkflow", Config: map[string]interface{}{"workflow": "fetch issues"}},
+			{ID: "step-child", WorkflowID: "workflow-child", StepOrder: 1, StepType: "wasm_module", WasmModuleID: &moduleID},
+		},
+		WasmModules: []*primitive.WasmModuleListItem{{ID: moduleID, Name: "issues"}},
+	}
+	jobStore := &MockJobStore{
+		Jobs: map[string]*job.Job{
+			"job-parent": {ID: "job-parent", WorkflowID: "workflow-parent", Status: job.StatusQueued, InputData: map[string]interface{}{"prompt": "go"}, CreatedAt: time.Now()},
+		},
+	}
+	engine := newSubworkflowTestEngine(store, jobStore)
+	engine.wasmExecutor.Modules()[moduleID] = jobOutputModule(`{"issues":[1,2],"count":2}`, `{"message":"stdout is ignored"}`)
+
+	require.NoError(t, engine.processJob(context.Background(), "job-parent"))
+
+	completed := jobStore.Jobs["job-parent"]
+	assert.Equal(t, job.StatusCompleted, completed.Status)
+	assert.Equal(t, map[string]interface{}{
+		"issues": []interface{}{float64(1), float64(2)},
+		"count":  float64(2),
+	}, completed.OutputData)
+}
+
+func TestOutputMappingReadsJobOutputNotStdout(t *testing.T) {
+	moduleID := "module-issues"
+	store := &MockPrimitiveStore{WasmModules: []*primitive.WasmModuleListItem{{ID: moduleID, Name: "issues"}}}
+	engine := newSubworkflowTestEngine(store, &MockJobStore{Jobs: map[string]*job.Job{}})
+	engine.wasmExecutor.Modules()[moduleID] = jobOutputModule(`{"summary":"2 issues","message":"from job output"}`, `{"summary":"from stdout","message":"stdout message"}`)
+	step := &primitive.WorkflowStep{ID: "step-wasm", StepType: "wasm_module", WasmModuleID: &moduleID, Config: map[string]interface{}{
+		"output_mapping": map[string]interface{}{"summary": "summary", "text": "message"},
+	}}
+
+	result, err := engine.processStepWithWorkingDir(context.Background(), step, map[string]interface{}{"prompt": "go"}, "")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{
+		"summary": "2 issues",
+		"message": "from job output",
+		"text":    "from job output",
+	}, result)
+}
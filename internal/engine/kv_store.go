@@ -0,0 +1,177 @@
+package engine
+
+import (
+	"context"
+	"log"
+	"sync"
+
+	"github.com/tetratelabs/wazero/api"
+)
+
+// runKVStore holds the scratch key-value data that WASM modules share during a
+// workflow run through the kv_set and kv_get host functions, keyed by run ID
+type runKVStore struct {
+	mu   sync.Mutex
+	runs map[string]map[string][]byte
+}
+
+// newRunKVStore creates an empty store
+func newRunKVStore() *runKVStore {
+	return &runKVStore{runs: make(map[string]map[string][]byte)}
+}
+
+// set stores value under key for the run, replacing any existing value
+func (s *runKVStore) set(runID, key string, value []byte) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	values, ok := s.runs[runID]
+	if !ok {
+		values = make(map[string][]byte)
+		s.runs[runID] = values
+	}
+	values[key] = value
+}
+
+// get returns the value stored under key for the run
+func (s *runKVStore) get(runID, key string) ([]byte, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	value, ok := s.runs[runID][key]
+	return value, ok
+}
+
+// clear discards all values stored for the run
+func (s *runKVStore) clear(runID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.runs, runID)
+}
+
+// runIDKey is the context key holding the ID of the workflow run
+type runIDKey struct{}
+
+// withRunID attaches the workflow run ID to the context. A context that
+// already carries one keeps it, so subworkflows share the parent run's store.
+func withRunID(ctx context.Context, runID string) context.Context {
+	if ctx.Value(runIDKey{}) != nil {
+		return ctx
+	}
+	return context.WithValue(ctx, runIDKey{}, runID)
+}
+
+// runIDFromContext returns the workflow run ID attached to the context
+func runIDFromContext(ctx context.Context) (string, bool) {
+	runID, ok := ctx.Value(runIDKey{}).(string)
+	return runID, ok && runID != ""
+}
+
+// clearRunKV discards the kv_set data of a finished workflow run
+func (e *WASMExecutor) clearRunKV(runID string) {
+	e.kv.clear(runID)
+}
+
+// kvSet implements the kv_set host function. It stores a value under a key
+// for the current workflow run so a later module in the same run can read it
+// with kv_get.
+func (e *WASMExecutor) kvSet(ctx context.Context, module api.Module, keyPtr, keySize, valuePtr, valueSize uint32) uint32 {
+	// Check for context cancellation before processing
+	select {
+	case <-ctx.Done():
+		// Return error code for cancellation
+		return 0xFFFFFFFA
+	default:
+	}
+
+	// Get memory from the module
+	mem := module.Memory()
+
+	// Read key from WASM memory
+	key, err := readStringFromMemory(ctx, mem, keyPtr, keySize)
+	if err != nil || key == "" {
+		log.Printf("Failed to read kv key from WASM memory: %v", err)
+		// Return error code (0xFFFFFFF0)
+		return 0xFFFFFFF0
+	}
+
+	// Read value from WASM memory (may be empty)
+	value := []byte{}
+	if valueSize > 0 {
+		data, ok := mem.Read(valuePtr, valueSize)
+		if !ok {
+			log.Printf("Failed to read kv value for %q from WASM memory", key)
+			// Return error code (0xFFFFFFF1)
+			return 0xFFFFFFF1
+		}
+		// Copy the value, since data aliases the module's memory
+		value = append(value, data...)
+	}
+
+	runID, ok := runIDFromContext(ctx)
+	if !ok {
+		log.Printf("kv_set called for %q outside a workflow run", key)
+		// Return error code (0xFFFFFFF2)
+		return 0xFFFFFFF2
+	}
+
+	e.kv.set(runID, key, value)
+	return 0
+}
+
+// kvGet implements the kv_get host function. It copies the value stored under
+// a key for the current workflow run into the module's buffer; a bufferSize of
+// 0 returns the required size.
+func (e *WASMExecutor) kvGet(ctx context.Context, module api.Module, keyPtr, keySize, bufferPtr, bufferSize uint32) uint32 {
+	// Check for context cancellation before processing
+	select {
+	case <-ctx.Done():
+		// Return error code for cancellation
+		return 0xFFFFFFFA
+	default:
+	}
+
+	// Get memory from the module
+	mem := module.Memory()
+
+	// Read key from WASM memory
+	key, err := readStringFromMemory(ctx, mem, keyPtr, keySize)
+	if err != nil || key == "" {
+		log.Printf("Failed to read kv key from WASM memory: %v", err)
+		// Return error code (0xFFFFFFF0)
+		return 0xFFFFFFF0
+	}
+
+	runID, ok := runIDFromContext(ctx)
+	if !ok {
+		log.Printf("kv_get called for %q outside a workflow run", key)
+		// Return error code (0xFFFFFFF2)
+		return 0xFFFFFFF2
+	}
+
+	value, ok := e.kv.get(runID, key)
+	if !ok {
+		// Return error code (0xFFFFFFF1)
+		return 0xFFFFFFF1
+	}
+
+	// If buffer size is 0, return the required size without writing data
+	if bufferSize == 0 {
+		return uint32(len(value))
+	}
+
+	// Check if buffer is large enough
+	if bufferSize < uint32(len(value)) {
+		log.Printf("Buffer too small for kv value %q: %d < %d", key, bufferSize, len(value))
+		// Return error code (0xFFFFFFF3)
+		return 0xFFFFFFF3
+	}
+
+	// Write value to WASM memory
+	if !mem.Write(bufferPtr, value) {
+		log.Printf("Failed to write kv value %q to WASM memory", key)
+		// Return error code (0xFFFFFFF4)
+		return 0xFFFFFFF4
+	}
+
+	// Return the size of the value
+	return uint32(len(value))
+}
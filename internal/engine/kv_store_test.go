@@ -0,0 +1,67 @@
+package engine
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestKVSetAndGetWithinRun(t *testing.T) {
+	executor := NewWASMExecutor(nil, &MockPrimitiveStore{}, nil, nil)
+	runCtx := withRunID(context.Background(), "job-1")
+
+	// A fan-out module records metadata for the run
+	launcherMem := newFakeMemory(256)
+	launcher := &fakeModule{mem: launcherMem}
+	keyPtr, keySize := launcherMem.put(0, "batch")
+	valuePtr, valueSize := launcherMem.put(32, `{"items":3}`)
+	require.Equal(t, uint32(0), executor.kvSet(runCtx, launcher, keyPtr, keySize, valuePtr, valueSize))
+
+	// A later aggregate module in the same run reads it back
+	aggregatorMem := newFakeMemory(256)
+	aggregator := &fakeModule{mem: aggregatorMem}
+	keyPtr, keySize = aggregatorMem.put(0, "batch")
+
+	size := executor.kvGet(runCtx, aggregator, keyPtr, keySize, 0, 0)
+	require.Equal(t, uint32(len(`{"items":3}`)), size)
+	require.Equal(t, size, executor.kvGet(runCtx, aggregator, keyPtr, keySize, 64, size))
+	assert.Equal(t, `{"items":3}`, string(aggregatorMem.buf[64:64+size]))
+
+	t.Run("buffer too small", func(t *testing.T) {
+		assert.Equal(t, uint32(0xFFFFFFF3), executor.kvGet(runCtx, aggregator, keyPtr, keySize, 64, 2))
+	})
+
+	t.Run("unknown key", func(t *testing.T) {
+		ptr, size := aggregatorMem.put(128, "missing")
+		assert.Equal(t, uint32(0xFFFFFFF1), executor.kvGet(runCtx, aggregator, ptr, size, 0, 0))
+	})
+
+	t.Run("other runs do not see the value", func(t *testing.T) {
+		otherCtx := withRunID(context.Background(), "job-2")
+		assert.Equal(t, uint32(0xFFFFFFF1), executor.kvGet(otherCtx, aggregator, keyPtr, keySize, 0, 0))
+	})
+
+	t.Run("nested run keeps the parent's store", func(t *testing.T) {
+		nestedCtx := withRunID(runCtx, "job-child")
+		assert.Equal(t, size, executor.kvGet(nestedCtx, aggregator, keyPtr, keySize, 0, 0))
+	})
+
+	t.Run("outside a run", func(t *testing.T) {
+		assert.Equal(t, uint32(0xFFFFFFF2), executor.kvGet(context.Background(), aggregator, keyPtr, keySize, 0, 0))
+		assert.Equal(t, uint32(0xFFFFFFF2), executor.kvSet(context.Background(), launcher, keyPtr, keySize, valuePtr, valueSize))
+	})
+
+	t.Run("cleared when the run ends", func(t *testing.T) {
+		executor.clearRunKV("job-1")
+		assert.Equal(t, uint32(0xFFFFFFF1), executor.kvGet(runCtx, aggregator, keyPtr, keySize, 0, 0))
+	})
+
+	t.Run("cancelled context", func(t *testing.T) {
+		cancelled, cancel := context.WithCancel(runCtx)
+		cancel()
+		assert.Equal(t, uint32(0xFFFFFFFA), executor.kvSet(cancelled, launcher, keyPtr, keySize, valuePtr, valueSize))
+		assert.Equal(t, uint32(0xFFFFFFFA), executor.kvGet(cancelled, aggregator, keyPtr, keySize, 0, 0))
+	})
+}
@@ -0,0 +1,32 @@
+package engine
+
+import (
+	"fmt"
+	"unicode/utf8"
+)
+
+// defaultMaxStderrBytes caps how much of a module's stderr is returned in its
+// result and errors unless changed with SetMaxStderrBytes
+const defaultMaxStderrBytes = 64 * 1024
+
+// SetMaxStderrBytes sets how much of a module's stderr is returned in its
+// result and errors. A zero or negative size restores the 64KB default.
+func (e *WASMExecutor) SetMaxStderrBytes(n int) {
+	if n <= 0 {
+		n = defaultMaxStderrBytes
+	}
+	e.maxStderrBytes = n
+}
+
+// truncateStderr keeps the end of stderr, where a failing module's last
+// messages are, when it is longer than the configured maximum
+func (e *WASMExecutor) truncateStderr(stderr string) string {
+	if len(stderr) <= e.maxStderrBytes {
+		return stderr
+	}
+	dropped := len(stderr) - e.maxStderrBytes
+	for dropped < len(stderr) && !utf8.RuneStart(stderr[dropped]) {
+		dropped++
+	}
+	return fmt.Sprintf("[%d bytes truncated]\n%s", dropped, stderr[dropped:])
+}
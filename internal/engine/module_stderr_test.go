@@ -0,0 +1,88 @@
+package engine
+
+import (
+	"context"
+	"encoding/binary"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mule-ai/mule/internal/primitive"
+)
+
+// stderrModule assembles a minimal WASI module whose _start writes message to
+// stderr and then calls proc_exit with exitCode (0-63)
+func stderrModule(message string, exitCode byte) []byte {
+	types := []byte{
+		0x03,
+		0x60, 0x04, 0x7f, 0x7f, 0x7f, 0x7f, 0x01, 0x7f, // fd_write
+		0x60, 0x01, 0x7f, 0x00, // proc_exit
+		0x60, 0x00, 0x00, // _start
+	}
+	imports := concatBytes([]byte{0x02},
+		wasmName("wasi_snapshot_preview1"), wasmName("fd_write"), []byte{0x00, 0x00},
+		wasmName("wasi_snapshot_preview1"), wasmName("proc_exit"), []byte{0x00, 0x01})
+	exports := concatBytes([]byte{0x02},
+		wasmName("_start"), []byte{0x00, 0x02},
+		wasmName("memory"), []byte{0x02, 0x00})
+
+	// fd_write(2, iovs=0, 1, nwritten=8); drop; proc_exit(exitCode)
+	body := []byte{0x00,
+		0x41, 0x02, 0x41, 0x00, 0x41, 0x01, 0x41, 0x08, 0x10, 0x00, 0x1a,
+		0x41, exitCode, 0x10, 0x01, 0x0b}
+	code := concatBytes([]byte{0x01}, wasmULEB(len(body)), body)
+
+	// A single iovec at 0 pointing at the message stored at 16
+	segment := make([]byte, 16)
+	binary.LittleEndian.PutUint32(segment[0:], 16)
+	binary.LittleEndian.PutUint32(segment[4:], uint32(len(message)))
+	segment = append(segment, message...)
+	data := concatBytes([]byte{0x01, 0x00, 0x41, 0x00, 0x0b}, wasmULEB(len(segment)), segment)
+
+	return wasmModule(
+		wasmSection(0x01, types...),
+		wasmSection(0x02, imports...),
+		wasmSection(0x03, 0x01, 0x02),
+		wasmSection(0x05, 0x01, 0x00, 0x01),
+		wasmSection(0x07, exports...),
+		wasmSection(0x0a, code...),
+		wasmSection(0x0b, data...),
+	)
+}
+
+func TestExecuteReturnsModuleStderr(t *testing.T) {
+	store := &MockPrimitiveStore{WasmModules: []*primitive.WasmModuleListItem{
+		{ID: "ok", Name: "ok"},
+		{ID: "fails", Name: "fails"},
+	}}
+	executor := NewWASMExecutor(nil, store, nil, nil)
+	executor.Modules()["ok"] = stderrModule("waiting for job 42 to complete", 0)
+	executor.Modules()["fails"] = stderrModule("job 42 failed: timeout", 1)
+	ctx := context.Background()
+
+	t.Run("stderr is returned in the result", func(t *testing.T) {
+		result, err := executor.Execute(ctx, "ok", nil, "")
+		require.NoError(t, err)
+		assert.Equal(t, "waiting for job 42 to complete", result["stderr"])
+	})
+
+	t.Run("non-zero exit returns stderr in the error", func(t *testing.T) {
+		_, err := executor.Execute(ctx, "fails", nil, "")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "exited with code 1")
+		assert.Contains(t, err.Error(), "job 42 failed: timeout")
+	})
+
+	t.Run("stderr is truncated to the configured maximum", func(t *testing.T) {
+		executor.SetMaxStderrBytes(8)
+		defer executor.SetMaxStderrBytes(0)
+
+		result, err := executor.Execute(ctx, "ok", nil, "")
+		require.NoError(t, err)
+		stderr := result["stderr"].(string)
+		assert.True(t, strings.HasSuffix(stderr, "complete"), stderr)
+		assert.Contains(t, stderr, "[22 bytes truncated]")
+	})
+}
@@ -0,0 +1,39 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// defaultMaxExecutionTime bounds a single WASM module execution unless changed
+// with SetMaxExecutionTime
+const defaultMaxExecutionTime = 5 * time.Minute
+
+// errModuleTimeout is the cause of an execution context whose deadline was set
+// by the executor rather than by the caller
+var errModuleTimeout = errors.New("module execution timed out")
+
+// SetMaxExecutionTime sets how long a module may run before it is stopped. A
+// zero or negative duration restores the 5 minute default.
+func (e *WASMExecutor) SetMaxExecutionTime(d time.Duration) {
+	if d <= 0 {
+		d = defaultMaxExecutionTime
+	}
+	e.maxExecutionTime = d
+}
+
+// withExecutionDeadline bounds ctx by the executor's maximum execution time
+func (e *WASMExecutor) withExecutionDeadline(ctx context.Context) (context.Context, context.CancelFunc) {
+	return context.WithTimeoutCause(ctx, e.maxExecutionTime, errModuleTimeout)
+}
+
+// executionStoppedError describes why a module stopped early, distinguishing
+// the executor's own deadline from cancellation by the caller
+func (e *WASMExecutor) executionStoppedError(ctx context.Context) error {
+	if errors.Is(context.Cause(ctx), errModuleTimeout) {
+		return fmt.Errorf("WASM module execution timed out after %s", e.maxExecutionTime)
+	}
+	return fmt.Errorf("WASM execution cancelled: %w", ctx.Err())
+}
@@ -0,0 +1,50 @@
+package engine
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mule-ai/mule/internal/primitive"
+)
+
+// loopModule assembles a WASM module whose _start never returns
+func loopModule() []byte {
+	// loop; br 0; end
+	body := []byte{0x00, 0x03, 0x40, 0x0c, 0x00, 0x0b, 0x0b}
+	return wasmModule(
+		wasmSection(0x01, 0x01, 0x60, 0x00, 0x00),
+		wasmSection(0x03, 0x01, 0x00),
+		wasmSection(0x07, concatBytes([]byte{0x01}, wasmName("_start"), []byte{0x00, 0x00})...),
+		wasmSection(0x0a, concatBytes([]byte{0x01}, wasmULEB(len(body)), body)...),
+	)
+}
+
+func TestExecuteStopsModuleAfterMaxExecutionTime(t *testing.T) {
+	store := &MockPrimitiveStore{WasmModules: []*primitive.WasmModuleListItem{{ID: "loop", Name: "loop"}}}
+	executor := NewWASMExecutor(nil, store, nil, nil)
+	executor.Modules()["loop"] = loopModule()
+
+	t.Run("times out", func(t *testing.T) {
+		executor.SetMaxExecutionTime(100 * time.Millisecond)
+		defer executor.SetMaxExecutionTime(0)
+
+		start := time.Now()
+		_, err := executor.Execute(context.Background(), "loop", nil, "")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "module execution timed out after 100ms")
+		assert.Less(t, time.Since(start), 5*time.Second)
+	})
+
+	t.Run("caller cancellation is reported as such", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+		defer cancel()
+
+		_, err := executor.Execute(ctx, "loop", nil, "")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "WASM execution cancelled")
+	})
+}
@@ -0,0 +1,49 @@
+package engine
+
+import (
+	"context"
+	"log"
+
+	"github.com/tetratelabs/wazero/api"
+)
+
+// setOperationError records a failed operation for a module instance: the
+// status returned by get_last_operation_status and the message returned by
+// get_last_operation_error
+func (e *WASMExecutor) setOperationError(key string, status int, err error) {
+	e.lastOperationStatus[key] = status
+	e.lastOperationError[key] = err.Error()
+}
+
+// getLastOperationError implements the get_last_operation_error host function.
+// It copies the error message of the module's last failed operation into its
+// buffer and returns the message length, which is 0 if the last operation
+// succeeded; a bufferSize of 0 returns the required size.
+func (e *WASMExecutor) getLastOperationError(ctx context.Context, module api.Module, bufferPtr, bufferSize uint32) uint32 {
+	message := e.lastOperationError[moduleKey(module)]
+	if message == "" {
+		return 0
+	}
+
+	// If buffer size is 0, return the required size without writing data
+	if bufferSize == 0 {
+		return uint32(len(message))
+	}
+
+	// Check if buffer is large enough
+	if bufferSize < uint32(len(message)) {
+		log.Printf("Buffer too small for operation error: %d < %d", bufferSize, len(message))
+		// Return error code (0xFFFFFFF1)
+		return 0xFFFFFFF1
+	}
+
+	// Write message to WASM memory
+	if !module.Memory().Write(bufferPtr, []byte(message)) {
+		log.Printf("Failed to write operation error to WASM memory")
+		// Return error code (0xFFFFFFF2)
+		return 0xFFFFFFF2
+	}
+
+	// Return the size of the message
+	return uint32(len(message))
+}
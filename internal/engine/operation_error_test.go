@@ -0,0 +1,51 @@
+package engine
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetLastOperationError(t *testing.T) {
+	store := &MockPrimitiveStore{}
+	executor := NewWASMExecutor(nil, store, nil, &Engine{})
+
+	mem := newFakeMemory(1024)
+	module := &fakeModule{mem: mem}
+	ctx := context.Background()
+
+	executeWorkflow := func(id string) uint32 {
+		typePtr, typeSize := mem.put(0, "workflow")
+		idPtr, idSize := mem.put(32, id)
+		paramsPtr, paramsSize := mem.put(96, `{"prompt": "hello"}`)
+		return executor.executeTarget(ctx, module, typePtr, typeSize, idPtr, idSize, paramsPtr, paramsSize)
+	}
+
+	t.Run("no error before any operation", func(t *testing.T) {
+		assert.Equal(t, uint32(0), executor.getLastOperationError(ctx, module, 512, 256))
+	})
+
+	t.Run("nonexistent workflow", func(t *testing.T) {
+		require.Equal(t, uint32(0xFFFFFFF5), executeWorkflow("missing-workflow"))
+		assert.Equal(t, 0xFFFFFFF5, executor.lastOperationStatus[moduleKey(module)])
+
+		want := "workflow not found: missing-workflow"
+		size := executor.getLastOperationError(ctx, module, 0, 0)
+		require.Equal(t, uint32(len(want)), size, "a bufferSize of 0 returns the required size")
+
+		n := executor.getLastOperationError(ctx, module, 512, size)
+		require.Equal(t, size, n)
+		assert.Equal(t, want, string(mem.buf[512:512+n]))
+	})
+
+	t.Run("buffer too small", func(t *testing.T) {
+		assert.Equal(t, uint32(0xFFFFFFF1), executor.getLastOperationError(ctx, module, 512, 4))
+	})
+
+	t.Run("kept per module instance", func(t *testing.T) {
+		other := &fakeModule{mem: newFakeMemory(1024)}
+		assert.Equal(t, uint32(0), executor.getLastOperationError(ctx, other, 512, 256))
+	})
+}
@@ -0,0 +1,78 @@
+package engine
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/mule-ai/mule/internal/primitive"
+)
+
+// outputMapping renames fields of a WASM module's JSON output to the
+// workflow's canonical result keys. Each key lists the fields to take it from,
+// in order of preference.
+type outputMapping map[string][]string
+
+// loadOutputMapping reads the output_mapping step config, which maps each
+// canonical key to a source field or a list of source fields, for example
+//
+//	{"summary": ["message", "result", "Message"], "url": "html_url"}
+//
+// Source fields may be dotted paths into nested output.
+func loadOutputMapping(step *primitive.WorkflowStep) (outputMapping, error) {
+	if step.Config == nil || step.Config["output_mapping"] == nil {
+		return nil, nil
+	}
+
+	raw, ok := step.Config["output_mapping"].(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("output_mapping must be an object")
+	}
+
+	mapping := make(outputMapping, len(raw))
+	for key, value := range raw {
+		switch v := value.(type) {
+		case string:
+			mapping[key] = []string{v}
+		case []interface{}:
+			for _, source := range v {
+				s, ok := source.(string)
+				if !ok {
+					return nil, fmt.Errorf("output_mapping.%s must list field names", key)
+				}
+				mapping[key] = append(mapping[key], s)
+			}
+		default:
+			return nil, fmt.Errorf("output_mapping.%s must be a field name or a list of field names", key)
+		}
+	}
+	return mapping, nil
+}
+
+// apply sets each canonical key in result from the first source field present
+// in the module's stdout, parsed as a JSON object. Keys with no matching field
+// are left as they are.
+func (m outputMapping) apply(stdout string, result map[string]interface{}) {
+	if len(m) == 0 {
+		return
+	}
+
+	var fields map[string]interface{}
+	if err := json.Unmarshal([]byte(stdout), &fields); err != nil {
+		return
+	}
+	m.applyFields(fields, result)
+}
+
+// applyFields sets each canonical key in result from the first source field
+// present in fields
+func (m outputMapping) applyFields(fields, result map[string]interface{}) {
+	for key, sources := range m {
+		for _, source := range sources {
+			if value, ok := lookupPath(fields, strings.Split(source, ".")); ok {
+				result[key] = value
+				break
+			}
+		}
+	}
+}
@@ -0,0 +1,73 @@
+package engine
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mule-ai/mule/internal/primitive"
+)
+
+func TestLoadOutputMapping(t *testing.T) {
+	mapping, err := loadOutputMapping(&primitive.WorkflowStep{})
+	require.NoError(t, err)
+	assert.Nil(t, mapping)
+
+	mapping, err = loadOutputMapping(&primitive.WorkflowStep{Config: map[string]interface{}{
+		"output_mapping": map[string]interface{}{
+			"summary": []interface{}{"message", "result"},
+			"url":     "html_url",
+		},
+	}})
+	require.NoError(t, err)
+	assert.Equal(t, outputMapping{"summary": {"message", "result"}, "url": {"html_url"}}, mapping)
+
+	for _, invalid := range []interface{}{
+		"summary=message",
+		map[string]interface{}{"summary": 1},
+		map[string]interface{}{"summary": []interface{}{"message", 2}},
+	} {
+		_, err := loadOutputMapping(&primitive.WorkflowStep{Config: map[string]interface{}{"output_mapping": invalid}})
+		assert.Error(t, err, "output_mapping %v", invalid)
+	}
+}
+
+func TestOutputMappingApply(t *testing.T) {
+	mapping := outputMapping{"summary": {"message", "result", "Message"}, "url": {"data.html_url"}}
+
+	tests := []struct {
+		name   string
+		stdout string
+		want   map[string]interface{}
+	}{
+		{
+			name:   "module emitting message",
+			stdout: `{"message": "3 issues found"}`,
+			want:   map[string]interface{}{"prompt": "original", "summary": "3 issues found"},
+		},
+		{
+			name:   "module emitting result",
+			stdout: `{"result": "3 issues found", "data": {"html_url": "https://example.com/1"}}`,
+			want:   map[string]interface{}{"prompt": "original", "summary": "3 issues found", "url": "https://example.com/1"},
+		},
+		{
+			name:   "first listed field wins",
+			stdout: `{"Message": "second", "message": "first"}`,
+			want:   map[string]interface{}{"prompt": "original", "summary": "first"},
+		},
+		{
+			name:   "non-JSON output is left alone",
+			stdout: "plain text",
+			want:   map[string]interface{}{"prompt": "original"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := map[string]interface{}{"prompt": "original"}
+			mapping.apply(tt.stdout, result)
+			assert.Equal(t, tt.want, result)
+		})
+	}
+}
@@ -0,0 +1,53 @@
+package engine
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+
+	"github.com/tetratelabs/wazero/api"
+)
+
+// getLastResponseHeaders implements the get_last_response_headers host
+// function. It writes all headers of the module's last HTTP response as a JSON
+// object mapping each canonical header name to its list of values. Like
+// get_last_response_body, a bufferSize of 0 returns the required size.
+func (e *WASMExecutor) getLastResponseHeaders(ctx context.Context, module api.Module, bufferPtr, bufferSize uint32) uint32 {
+	// Get the response for this module instance
+	key := moduleKey(module)
+	resp, ok := e.lastResponse[key]
+	if !ok {
+		log.Printf("No response available for module %s", key)
+		// Return error code (0xFFFFFFF4)
+		return 0xFFFFFFF4
+	}
+
+	headersJSON, err := json.Marshal(resp.Header)
+	if err != nil {
+		log.Printf("Failed to encode response headers: %v", err)
+		// Return error code (0xFFFFFFF3)
+		return 0xFFFFFFF3
+	}
+
+	// If buffer size is 0, return the required size without writing data
+	if bufferSize == 0 {
+		return uint32(len(headersJSON))
+	}
+
+	// Check if buffer is large enough
+	if bufferSize < uint32(len(headersJSON)) {
+		log.Printf("Buffer too small for response headers: %d < %d", bufferSize, len(headersJSON))
+		// Return error code (0xFFFFFFF5)
+		return 0xFFFFFFF5
+	}
+
+	// Write headers to WASM memory
+	if !module.Memory().Write(bufferPtr, headersJSON) {
+		log.Printf("Failed to write response headers to WASM memory")
+		// Return error code (0xFFFFFFF6)
+		return 0xFFFFFFF6
+	}
+
+	// Return the size of the headers JSON
+	return uint32(len(headersJSON))
+}
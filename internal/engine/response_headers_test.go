@@ -0,0 +1,48 @@
+package engine
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetLastResponseHeaders(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Location", "https://api.github.com/repos/mule-ai/mule/issues/comments/1")
+		w.Header().Set("X-RateLimit-Remaining", "4999")
+		w.Header().Add("X-Multi", "a")
+		w.Header().Add("X-Multi", "b")
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer server.Close()
+
+	executor := NewWASMExecutor(nil, &MockPrimitiveStore{}, nil, nil)
+	mem := newFakeMemory(2048)
+	module := &fakeModule{mem: mem}
+
+	// No request made yet
+	assert.Equal(t, uint32(0xFFFFFFF4), executor.getLastResponseHeaders(context.Background(), module, 0, 0))
+
+	methodPtr, methodSize := mem.put(0, "POST")
+	urlPtr, urlSize := mem.put(16, server.URL)
+	require.Equal(t, uint32(0), executor.httpRequestWithHeaders(context.Background(), module, methodPtr, methodSize, urlPtr, urlSize, 0, 0, 0, 0, 0))
+
+	// First call reports the size, second reads the headers
+	size := executor.getLastResponseHeaders(context.Background(), module, 0, 0)
+	require.Less(t, size, uint32(0xFFFFFFF0))
+	assert.Equal(t, uint32(0xFFFFFFF5), executor.getLastResponseHeaders(context.Background(), module, 512, size-1))
+	require.Equal(t, size, executor.getLastResponseHeaders(context.Background(), module, 512, size))
+
+	raw, ok := mem.Read(512, size)
+	require.True(t, ok)
+	var headers http.Header
+	require.NoError(t, json.Unmarshal(raw, &headers))
+	assert.Equal(t, "https://api.github.com/repos/mule-ai/mule/issues/comments/1", headers.Get("Location"))
+	assert.Equal(t, "4999", headers.Get("X-RateLimit-Remaining"))
+	assert.Equal(t, []string{"a", "b"}, headers.Values("X-Multi"))
+}
@@ -0,0 +1,105 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"log"
+	"strconv"
+	"time"
+
+	"github.com/mule-ai/mule/internal/agent"
+	"github.com/mule-ai/mule/internal/primitive"
+)
+
+// defaultRetryBackoff is the wait before the first retry of a failed step
+// when the step sets max_retries but no retry_backoff
+const defaultRetryBackoff = time.Second
+
+// maxRetryBackoff caps the wait between retries however many have been made
+const maxRetryBackoff = 5 * time.Minute
+
+// errStepReportedFailure marks a step that ran and reported its own failure,
+// such as a WASM module printing {"success": false}. Running it again would
+// give the same answer, so it is not retried.
+var errStepReportedFailure = errors.New("step reported failure")
+
+// stepRetryPolicy is how a failed step is retried
+type stepRetryPolicy struct {
+	MaxRetries int
+	Backoff    time.Duration
+}
+
+// loadStepRetryPolicy reads max_retries and retry_backoff from the step
+// config. retry_backoff is a duration such as "500ms" or "2s", or a number of
+// seconds. Without max_retries a failed step is not retried.
+func loadStepRetryPolicy(step *primitive.WorkflowStep) stepRetryPolicy {
+	policy := stepRetryPolicy{Backoff: defaultRetryBackoff}
+	if step.Config == nil {
+		return policy
+	}
+
+	switch v := step.Config["max_retries"].(type) {
+	case float64:
+		policy.MaxRetries = int(v)
+	case int:
+		policy.MaxRetries = v
+	case string:
+		if val, err := strconv.Atoi(v); err == nil {
+			policy.MaxRetries = val
+		}
+	}
+	if policy.MaxRetries < 0 {
+		policy.MaxRetries = 0
+	}
+
+	switch v := step.Config["retry_backoff"].(type) {
+	case float64:
+		policy.Backoff = time.Duration(v * float64(time.Second))
+	case int:
+		policy.Backoff = time.Duration(v) * time.Second
+	case string:
+		if d, err := time.ParseDuration(v); err == nil {
+			policy.Backoff = d
+		}
+	}
+	if policy.Backoff < 0 {
+		policy.Backoff = 0
+	}
+
+	return policy
+}
+
+// delay returns the wait before the given retry (1 for the first), doubling
+// the base backoff each time up to maxRetryBackoff
+func (p stepRetryPolicy) delay(retry int) time.Duration {
+	d := p.Backoff
+	for i := 1; i < retry && d < maxRetryBackoff; i++ {
+		d *= 2
+	}
+	return min(d, maxRetryBackoff)
+}
+
+// runWithRetries calls run until it succeeds or the policy's retries are used
+// up, waiting with exponential backoff between attempts. Only errors are
+// retried; a result reporting failure in its own fields counts as success. It
+// returns the last result and error and the number of attempts made. An
+// exhausted budget and a failure the step reported itself are not retried.
+func runWithRetries(ctx context.Context, policy stepRetryPolicy, run func() (map[string]interface{}, error)) (map[string]interface{}, int, error) {
+	for attempt := 1; ; attempt++ {
+		result, err := run()
+		if err == nil || attempt > policy.MaxRetries || ctx.Err() != nil || errors.Is(err, agent.ErrBudgetExceeded) || errors.Is(err, errStepReportedFailure) {
+			return result, attempt, err
+		}
+
+		wait := policy.delay(attempt)
+		log.Printf("Step attempt %d failed, retrying in %s: %v", attempt, wait, err)
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return result, attempt, err
+		case <-timer.C:
+		}
+	}
+}
@@ -0,0 +1,139 @@
+package engine
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mule-ai/mule/internal/primitive"
+	"github.com/mule-ai/mule/pkg/job"
+)
+
+func TestLoadStepRetryPolicy(t *testing.T) {
+	policy := loadStepRetryPolicy(&primitive.WorkflowStep{})
+	assert.Equal(t, stepRetryPolicy{Backoff: defaultRetryBackoff}, policy)
+
+	policy = loadStepRetryPolicy(&primitive.WorkflowStep{Config: map[string]interface{}{"max_retries": float64(3), "retry_backoff": "250ms"}})
+	assert.Equal(t, stepRetryPolicy{MaxRetries: 3, Backoff: 250 * time.Millisecond}, policy)
+
+	policy = loadStepRetryPolicy(&primitive.WorkflowStep{Config: map[string]interface{}{"max_retries": "2", "retry_backoff": float64(1.5)}})
+	assert.Equal(t, stepRetryPolicy{MaxRetries: 2, Backoff: 1500 * time.Millisecond}, policy)
+
+	policy = loadStepRetryPolicy(&primitive.WorkflowStep{Config: map[string]interface{}{"max_retries": float64(-1), "retry_backoff": "soon"}})
+	assert.Equal(t, stepRetryPolicy{Backoff: defaultRetryBackoff}, policy)
+}
+
+func TestStepRetryPolicyDelay(t *testing.T) {
+	policy := stepRetryPolicy{MaxRetries: 20, Backoff: time.Second}
+	assert.Equal(t, time.Second, policy.delay(1))
+	assert.Equal(t, 2*time.Second, policy.delay(2))
+	assert.Equal(t, 4*time.Second, policy.delay(3))
+	assert.Equal(t, maxRetryBackoff, policy.delay(20))
+}
+
+func TestRunWithRetries(t *testing.T) {
+	errTransient := errors.New("502 Bad Gateway")
+
+	t.Run("succeeds after two failures", func(t *testing.T) {
+		calls := 0
+		result, attempts, err := runWithRetries(context.Background(), stepRetryPolicy{MaxRetries: 3, Backoff: time.Millisecond}, func() (map[string]interface{}, error) {
+			calls++
+			if calls <= 2 {
+				return nil, errTransient
+			}
+			return map[string]interface{}{"success": true}, nil
+		})
+		require.NoError(t, err)
+		assert.Equal(t, 3, attempts)
+		assert.Equal(t, true, result["success"])
+	})
+
+	t.Run("gives up after max retries", func(t *testing.T) {
+		calls := 0
+		_, attempts, err := runWithRetries(context.Background(), stepRetryPolicy{MaxRetries: 2, Backoff: time.Millisecond}, func() (map[string]interface{}, error) {
+			calls++
+			return nil, errTransient
+		})
+		assert.ErrorIs(t, err, errTransient)
+		assert.Equal(t, 3, attempts)
+		assert.Equal(t, 3, calls)
+	})
+
+	t.Run("does not retry a result reporting failure", func(t *testing.T) {
+		calls := 0
+		result, attempts, err := runWithRetries(context.Background(), stepRetryPolicy{MaxRetries: 3, Backoff: time.Millisecond}, func() (map[string]interface{}, error) {
+			calls++
+			return map[string]interface{}{"success": false}, nil
+		})
+		require.NoError(t, err)
+		assert.Equal(t, 1, attempts)
+		assert.Equal(t, 1, calls)
+		assert.Equal(t, false, result["success"])
+	})
+
+	t.Run("stops waiting when the context is cancelled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		_, attempts, err := runWithRetries(ctx, stepRetryPolicy{MaxRetries: 5, Backoff: time.Hour}, func() (map[string]interface{}, error) {
+			cancel()
+			return nil, errTransient
+		})
+		assert.ErrorIs(t, err, errTransient)
+		assert.Equal(t, 1, attempts)
+	})
+}
+
+// stdoutModule assembles a WASM module whose _start prints stdout
+func stdoutModule(stdout string) []byte {
+	types := []byte{
+		0x02,
+		0x60, 0x04, 0x7f, 0x7f, 0x7f, 0x7f, 0x01, 0x7f, // fd_write
+		0x60, 0x00, 0x00, // _start
+	}
+	imports := concatBytes([]byte{0x01},
+		wasmName("wasi_snapshot_preview1"), wasmName("fd_write"), []byte{0x00, 0x00})
+	exports := concatBytes([]byte{0x02},
+		wasmName("_start"), []byte{0x00, 0x01},
+		wasmName("memory"), []byte{0x02, 0x00})
+
+	// Memory holds an iovec for stdout at 0 and stdout at 16
+	segment := make([]byte, 16)
+	binary.LittleEndian.PutUint32(segment[0:], 16)
+	binary.LittleEndian.PutUint32(segment[4:], uint32(len(stdout)))
+	segment = append(segment, stdout...)
+
+	// fd_write(1, 0, 1, 8); drop
+	body := []byte{0x00, 0x41, 0x01, 0x41, 0x00, 0x41, 0x01, 0x41, 0x08, 0x10, 0x00, 0x1a, 0x0b}
+
+	return wasmModule(
+		wasmSection(0x01, types...),
+		wasmSection(0x02, imports...),
+		wasmSection(0x03, 0x01, 0x01),
+		wasmSection(0x05, 0x01, 0x00, 0x01),
+		wasmSection(0x07, exports...),
+		wasmSection(0x0a, concatBytes([]byte{0x01}, wasmULEB(len(body)), body)...),
+		wasmSection(0x0b, concatBytes([]byte{0x01, 0x00, 0x41, 0x00, 0x0b}, wasmULEB(len(segment)), segment)...),
+	)
+}
+
+func TestWASMStepReportingFailureIsNotRetried(t *testing.T) {
+	moduleID := "module-fails"
+	store := &MockPrimitiveStore{WasmModules: []*primitive.WasmModuleListItem{{ID: moduleID, Name: "fails"}}}
+	engine := newSubworkflowTestEngine(store, &MockJobStore{Jobs: map[string]*job.Job{}})
+	engine.wasmExecutor.Modules()[moduleID] = stdoutModule(`{"success":false,"error":"issue not found"}`)
+	step := &primitive.WorkflowStep{ID: "step-wasm", StepType: "wasm_module", WasmModuleID: &moduleID}
+
+	calls := 0
+	_, attempts, err := runWithRetries(context.Background(), stepRetryPolicy{MaxRetries: 3, Backoff: time.Millisecond}, func() (map[string]interface{}, error) {
+		calls++
+		return engine.processStepWithWorkingDir(context.Background(), step, map[string]interface{}{"prompt": "go"}, "")
+	})
+	require.Error(t, err)
+	assert.ErrorIs(t, err, errStepReportedFailure)
+	assert.Equal(t, 1, attempts)
+	assert.Equal(t, 1, calls)
+}
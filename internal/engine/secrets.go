@@ -0,0 +1,200 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/tetratelabs/wazero/api"
+)
+
+// SecretStore holds named secrets that WASM modules can read with the
+// get_secret host function. Secret values are never logged.
+type SecretStore struct {
+	mu      sync.RWMutex
+	secrets map[string]string
+}
+
+// NewSecretStore creates an empty secret store
+func NewSecretStore() *SecretStore {
+	return &SecretStore{secrets: make(map[string]string)}
+}
+
+// Set stores a secret under the given name, replacing any existing value
+func (s *SecretStore) Set(name, value string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.secrets[name] = value
+}
+
+// Get returns the secret with the given name
+func (s *SecretStore) Get(name string) (string, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	value, ok := s.secrets[name]
+	return value, ok
+}
+
+// Names returns the names of all stored secrets
+func (s *SecretStore) Names() []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	names := make([]string, 0, len(s.secrets))
+	for name := range s.secrets {
+		names = append(names, name)
+	}
+	return names
+}
+
+// LoadFromEnv loads every environment variable starting with prefix as a
+// secret named by the rest of the variable name, e.g. MULE_SECRET_GITHUB_TOKEN
+// becomes GITHUB_TOKEN. It returns the number of secrets loaded.
+func (s *SecretStore) LoadFromEnv(prefix string) int {
+	count := 0
+	for _, env := range os.Environ() {
+		key, value, ok := strings.Cut(env, "=")
+		if !ok || !strings.HasPrefix(key, prefix) || len(key) == len(prefix) {
+			continue
+		}
+		s.Set(strings.TrimPrefix(key, prefix), value)
+		count++
+	}
+	return count
+}
+
+// LoadFromDir loads each regular file in dir as a secret named by the file
+// name, in the style of mounted container secrets. A single trailing newline
+// is stripped from the value. It returns the number of secrets loaded.
+func (s *SecretStore) LoadFromDir(dir string) (int, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read secrets directory: %w", err)
+	}
+
+	count := 0
+	for _, entry := range entries {
+		if !entry.Type().IsRegular() || strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			return count, fmt.Errorf("failed to read secret %s: %w", entry.Name(), err)
+		}
+		value := strings.TrimSuffix(strings.TrimSuffix(string(data), "\n"), "\r")
+		s.Set(entry.Name(), value)
+		count++
+	}
+	return count, nil
+}
+
+// allowedSecretsKey is the context key holding the secret names the
+// executing module may read
+type allowedSecretsKey struct{}
+
+// withAllowedSecrets restricts get_secret to the names in the module's
+// allowed_secrets config, given as a list or a comma-separated string, so one
+// module cannot read credentials meant for another. Without that setting the
+// module can read no secrets. Like allowed_urls, it is read from the stored
+// module config so callers cannot lift it.
+func withAllowedSecrets(ctx context.Context, config map[string]interface{}) context.Context {
+	var names []string
+	switch v := config["allowed_secrets"].(type) {
+	case []interface{}:
+		for _, n := range v {
+			if s, ok := n.(string); ok {
+				names = append(names, s)
+			}
+		}
+	case []string:
+		names = v
+	case string:
+		names = strings.Split(v, ",")
+	}
+
+	allowed := make(map[string]bool, len(names))
+	for _, name := range names {
+		if name = strings.TrimSpace(name); name != "" {
+			allowed[name] = true
+		}
+	}
+	return context.WithValue(ctx, allowedSecretsKey{}, allowed)
+}
+
+// secretAllowed reports whether the executing module may read the named
+// secret. Secrets are denied unless listed in allowed_secrets.
+func secretAllowed(ctx context.Context, name string) bool {
+	allowed, _ := ctx.Value(allowedSecretsKey{}).(map[string]bool)
+	return allowed[name]
+}
+
+// SetSecretStore sets the store used by the get_secret host function
+func (e *WASMExecutor) SetSecretStore(secrets *SecretStore) {
+	e.secrets = secrets
+}
+
+// getSecret implements the get_secret host function. It copies the named
+// secret into the module's buffer using the two-call pattern: a bufferSize of
+// 0 returns the required size. The secret value is never logged.
+func (e *WASMExecutor) getSecret(ctx context.Context, module api.Module, namePtr, nameSize, bufferPtr, bufferSize uint32) uint32 {
+	// Check for context cancellation before processing
+	select {
+	case <-ctx.Done():
+		// Return error code for cancellation
+		return 0xFFFFFFFA
+	default:
+	}
+
+	// Get memory from the module
+	mem := module.Memory()
+
+	// Read secret name from WASM memory
+	name, err := readStringFromMemory(ctx, mem, namePtr, nameSize)
+	if err != nil || name == "" {
+		log.Printf("Failed to read secret name from WASM memory: %v", err)
+		// Return error code (0xFFFFFFF0)
+		return 0xFFFFFFF0
+	}
+
+	if !secretAllowed(ctx, name) {
+		log.Printf("Secret %q is not in the WASM module's allowed_secrets", name)
+		// Return error code (0xFFFFFFF2)
+		return 0xFFFFFFF2
+	}
+
+	var value string
+	var ok bool
+	if e.secrets != nil {
+		value, ok = e.secrets.Get(name)
+	}
+	if !ok {
+		log.Printf("Secret %q requested by WASM module not found", name)
+		// Return error code (0xFFFFFFF1)
+		return 0xFFFFFFF1
+	}
+
+	// If buffer size is 0, return the required size without writing data
+	if bufferSize == 0 {
+		return uint32(len(value))
+	}
+
+	// Check if buffer is large enough
+	if bufferSize < uint32(len(value)) {
+		log.Printf("Buffer too small for secret %q: %d < %d", name, bufferSize, len(value))
+		// Return error code (0xFFFFFFF3)
+		return 0xFFFFFFF3
+	}
+
+	// Write secret value to WASM memory
+	if !mem.Write(bufferPtr, []byte(value)) {
+		log.Printf("Failed to write secret %q to WASM memory", name)
+		// Return error code (0xFFFFFFF4)
+		return 0xFFFFFFF4
+	}
+
+	// Return the size of the secret value
+	return uint32(len(value))
+}
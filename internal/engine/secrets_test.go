@@ -0,0 +1,129 @@
+package engine
+
+import (
+	"bytes"
+	"context"
+	"log"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetSecret(t *testing.T) {
+	const secretValue = "ghp_super_secret_token"
+
+	executor := NewWASMExecutor(nil, &MockPrimitiveStore{}, nil, nil)
+	executor.secrets.Set("GITHUB_TOKEN", secretValue)
+
+	mem := newFakeMemory(256)
+	module := &fakeModule{mem: mem}
+	namePtr, nameSize := mem.put(0, "GITHUB_TOKEN")
+	ctx := withAllowedSecrets(context.Background(), map[string]interface{}{"allowed_secrets": "GITHUB_TOKEN, MISSING"})
+
+	var logBuf bytes.Buffer
+	oldOutput := log.Writer()
+	log.SetOutput(&logBuf)
+	defer log.SetOutput(oldOutput)
+
+	t.Run("returns configured secret", func(t *testing.T) {
+		size := executor.getSecret(ctx, module, namePtr, nameSize, 0, 0)
+		require.Equal(t, uint32(len(secretValue)), size)
+
+		result := executor.getSecret(ctx, module, namePtr, nameSize, 64, size)
+		require.Equal(t, size, result)
+		assert.Equal(t, secretValue, string(mem.buf[64:64+size]))
+	})
+
+	t.Run("buffer too small", func(t *testing.T) {
+		result := executor.getSecret(ctx, module, namePtr, nameSize, 64, 4)
+		assert.Equal(t, uint32(0xFFFFFFF3), result)
+	})
+
+	t.Run("unknown secret returns error code", func(t *testing.T) {
+		ptr, size := mem.put(128, "MISSING")
+		result := executor.getSecret(ctx, module, ptr, size, 0, 0)
+		assert.Equal(t, uint32(0xFFFFFFF1), result)
+	})
+
+	t.Run("cancelled context", func(t *testing.T) {
+		cancelled, cancel := context.WithCancel(ctx)
+		cancel()
+		assert.Equal(t, uint32(0xFFFFFFFA), executor.getSecret(cancelled, module, namePtr, nameSize, 0, 0))
+	})
+
+	assert.NotContains(t, logBuf.String(), secretValue, "secret value must never be logged")
+}
+
+func TestSecretStoreLoading(t *testing.T) {
+	t.Run("from environment", func(t *testing.T) {
+		t.Setenv("MULE_TEST_SECRET_API_KEY", "from-env")
+		store := NewSecretStore()
+		assert.Equal(t, 1, store.LoadFromEnv("MULE_TEST_SECRET_"))
+
+		value, ok := store.Get("API_KEY")
+		assert.True(t, ok)
+		assert.Equal(t, "from-env", value)
+	})
+
+	t.Run("from directory", func(t *testing.T) {
+		dir := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "db_password"), []byte("hunter2\n"), 0600))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, ".hidden"), []byte("ignored"), 0600))
+		require.NoError(t, os.Mkdir(filepath.Join(dir, "subdir"), 0700))
+
+		store := NewSecretStore()
+		count, err := store.LoadFromDir(dir)
+		require.NoError(t, err)
+		assert.Equal(t, 1, count)
+
+		value, ok := store.Get("db_password")
+		assert.True(t, ok)
+		assert.Equal(t, "hunter2", value)
+	})
+
+	t.Run("missing directory", func(t *testing.T) {
+		_, err := NewSecretStore().LoadFromDir(filepath.Join(t.TempDir(), "nope"))
+		assert.Error(t, err)
+	})
+}
+
+func TestGetSecretScopedToModule(t *testing.T) {
+	executor := NewWASMExecutor(nil, &MockPrimitiveStore{}, nil, nil)
+	executor.secrets.Set("GITHUB_TOKEN", "ghp_token")
+	executor.secrets.Set("SLACK_TOKEN", "xoxb_token")
+
+	mem := newFakeMemory(256)
+	module := &fakeModule{mem: mem}
+	ctx := withAllowedSecrets(context.Background(), map[string]interface{}{
+		"allowed_secrets": []interface{}{"GITHUB_TOKEN"},
+	})
+
+	t.Run("reads its own secret", func(t *testing.T) {
+		ptr, size := mem.put(0, "GITHUB_TOKEN")
+		assert.Equal(t, uint32(len("ghp_token")), executor.getSecret(ctx, module, ptr, size, 0, 0))
+	})
+
+	t.Run("denied an unscoped secret", func(t *testing.T) {
+		ptr, size := mem.put(0, "SLACK_TOKEN")
+		assert.Equal(t, uint32(0xFFFFFFF2), executor.getSecret(ctx, module, ptr, size, 64, 64))
+		assert.NotContains(t, string(mem.buf[64:128]), "xoxb_token")
+	})
+
+	t.Run("comma-separated config", func(t *testing.T) {
+		scoped := withAllowedSecrets(context.Background(), map[string]interface{}{"allowed_secrets": "SLACK_TOKEN, OTHER"})
+		assert.True(t, secretAllowed(scoped, "SLACK_TOKEN"))
+		assert.False(t, secretAllowed(scoped, "GITHUB_TOKEN"))
+	})
+
+	t.Run("without allowed_secrets no secret is readable", func(t *testing.T) {
+		unscoped := withAllowedSecrets(context.Background(), map[string]interface{}{})
+		assert.False(t, secretAllowed(unscoped, "SLACK_TOKEN"))
+
+		ptr, size := mem.put(0, "GITHUB_TOKEN")
+		assert.Equal(t, uint32(0xFFFFFFF2), executor.getSecret(unscoped, module, ptr, size, 0, 0))
+		assert.False(t, secretAllowed(context.Background(), "GITHUB_TOKEN"))
+	})
+}
@@ -0,0 +1,135 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"sync/atomic"
+
+	"github.com/mule-ai/mule/internal/primitive"
+)
+
+// defaultMaxWorkflowSteps is the default cap on the number of steps a single
+// job may execute, counting steps run inside subworkflows
+const defaultMaxWorkflowSteps = 1000
+
+// stepBudget counts the steps executed by a job so that recursive or fanned
+// out workflows abort instead of running forever. Jobs submitted from a run,
+// such as with trigger_workflow, share the budget of its root job.
+type stepBudget struct {
+	max    int64
+	used   atomic.Int64
+	rootID string
+}
+
+// sharedStepBudget is a root job's step budget and the IDs of the queued or
+// running jobs that still hold it
+type sharedStepBudget struct {
+	budget  *stepBudget
+	holders map[string]bool
+}
+
+// stepBudgetKey is the context key holding the job's step budget
+type stepBudgetKey struct{}
+
+// loadMaxWorkflowSteps reads the max_workflow_steps setting. 0 disables the cap.
+func loadMaxWorkflowSteps(settings []*primitive.Setting) int {
+	for _, setting := range settings {
+		if setting.Key == "max_workflow_steps" {
+			if val, err := strconv.Atoi(setting.Value); err == nil && val >= 0 {
+				return val
+			}
+			break
+		}
+	}
+	return defaultMaxWorkflowSteps
+}
+
+// rootJobID returns the ID of the job whose run submitted jobID, or jobID
+// itself
+func (e *Engine) rootJobID(jobID string) string {
+	e.budgetsMu.Lock()
+	defer e.budgetsMu.Unlock()
+	if rootID, ok := e.jobRoots[jobID]; ok {
+		return rootID
+	}
+	return jobID
+}
+
+// acquireStepBudget attaches the step budget of rootID to the context for
+// jobID, creating it with max steps if no job holds it yet. Nested execution
+// such as subworkflows runs in this context and so shares the job's count.
+// The returned function releases the budget once the job has finished.
+func (e *Engine) acquireStepBudget(ctx context.Context, rootID, jobID string, max int) (context.Context, func()) {
+	if max <= 0 {
+		return ctx, func() {}
+	}
+
+	e.budgetsMu.Lock()
+	if e.stepBudgets == nil {
+		e.stepBudgets = make(map[string]*sharedStepBudget)
+	}
+	shared, ok := e.stepBudgets[rootID]
+	if !ok {
+		shared = &sharedStepBudget{
+			budget:  &stepBudget{max: int64(max), rootID: rootID},
+			holders: make(map[string]bool),
+		}
+		e.stepBudgets[rootID] = shared
+	}
+	shared.holders[jobID] = true
+	e.budgetsMu.Unlock()
+
+	return context.WithValue(ctx, stepBudgetKey{}, shared.budget), func() {
+		e.releaseStepBudget(rootID, jobID)
+	}
+}
+
+// retainStepBudget keeps the step budget of the run in ctx alive for a job
+// submitted from it, returning the root job ID
+func (e *Engine) retainStepBudget(ctx context.Context, jobID string) (string, bool) {
+	budget, ok := ctx.Value(stepBudgetKey{}).(*stepBudget)
+	if !ok || budget.rootID == "" {
+		return "", false
+	}
+
+	e.budgetsMu.Lock()
+	defer e.budgetsMu.Unlock()
+	if shared, ok := e.stepBudgets[budget.rootID]; ok {
+		shared.holders[jobID] = true
+	}
+	if e.jobRoots == nil {
+		e.jobRoots = make(map[string]string)
+	}
+	e.jobRoots[jobID] = budget.rootID
+	return budget.rootID, true
+}
+
+// releaseStepBudget drops jobID's hold on the step budget of rootID,
+// forgetting the budget once no job holds it. Releasing twice is harmless.
+func (e *Engine) releaseStepBudget(rootID, jobID string) {
+	e.budgetsMu.Lock()
+	defer e.budgetsMu.Unlock()
+	delete(e.jobRoots, jobID)
+	shared, ok := e.stepBudgets[rootID]
+	if !ok {
+		return
+	}
+	delete(shared.holders, jobID)
+	if len(shared.holders) == 0 {
+		delete(e.stepBudgets, rootID)
+	}
+}
+
+// consumeStep counts one step against the context's budget and returns an
+// error once the budget is exceeded
+func consumeStep(ctx context.Context) error {
+	budget, ok := ctx.Value(stepBudgetKey{}).(*stepBudget)
+	if !ok {
+		return nil
+	}
+	if budget.used.Add(1) > budget.max {
+		return fmt.Errorf("workflow exceeded the maximum of %d steps", budget.max)
+	}
+	return nil
+}
@@ -0,0 +1,169 @@
+package engine
+
+import (
+	"context"
+	"encoding/binary"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mule-ai/mule/internal/primitive"
+	"github.com/mule-ai/mule/pkg/job"
+)
+
+func TestLoadMaxWorkflowSteps(t *testing.T) {
+	assert.Equal(t, defaultMaxWorkflowSteps, loadMaxWorkflowSteps(nil))
+	assert.Equal(t, 25, loadMaxWorkflowSteps([]*primitive.Setting{{Key: "max_workflow_steps", Value: "25"}}))
+	assert.Equal(t, 0, loadMaxWorkflowSteps([]*primitive.Setting{{Key: "max_workflow_steps", Value: "0"}}))
+	assert.Equal(t, defaultMaxWorkflowSteps, loadMaxWorkflowSteps([]*primitive.Setting{{Key: "max_workflow_steps", Value: "-3"}}))
+}
+
+func TestConsumeStep(t *testing.T) {
+	engine := &Engine{}
+	ctx, release := engine.acquireStepBudget(context.Background(), "job-root", "job-root", 2)
+	require.NoError(t, consumeStep(ctx))
+
+	// A job submitted from the run shares the root's budget instead of starting a new one
+	spawned, releaseSpawned := engine.acquireStepBudget(context.Background(), "job-root", "job-spawned", 100)
+	require.NoError(t, consumeStep(spawned))
+	assert.EqualError(t, consumeStep(spawned), "workflow exceeded the maximum of 2 steps")
+
+	// The budget is forgotten once no job holds it
+	release()
+	assert.Len(t, engine.stepBudgets, 1)
+	releaseSpawned()
+	releaseSpawned()
+	assert.Empty(t, engine.stepBudgets)
+
+	// Without a budget, or with the cap disabled, steps are not limited
+	assert.NoError(t, consumeStep(context.Background()))
+	disabled, _ := engine.acquireStepBudget(context.Background(), "job-other", "job-other", 0)
+	assert.NoError(t, consumeStep(disabled))
+}
+
+func TestProcessJobAbortsAtStepCap(t *testing.T) {
+	// Each run of the workflow runs itself twice; the step cap is set below
+	// the nesting depth limit so the cap is what stops it
+	mockStore := &MockPrimitiveStore{
+		Workflows: []*primitive.Workflow{{ID: "workflow-fork", Name: "Fork"}},
+		WorkflowSteps: []*primitive.WorkflowStep{
+			{ID: "step-left", WorkflowID: "workflow-fork", StepOrder: 1, StepType: "subworkflow", Config: map[string]interface{}{"workflow": "Fork"}},
+			{ID: "step-right", WorkflowID: "workflow-fork", StepOrder: 2, StepType: "subworkflow", Config: map[string]interface{}{"workflow": "Fork"}},
+		},
+		Settings: []*primitive.Setting{{Key: "max_workflow_steps", Value: "4"}},
+	}
+	mockJobStore := &MockJobStore{
+		Jobs: map[string]*job.Job{
+			"job-fork": {
+				ID:         "job-fork",
+				WorkflowID: "workflow-fork",
+				Status:     job.StatusQueued,
+				InputData:  map[string]interface{}{"prompt": "go"},
+				CreatedAt:  time.Now(),
+			},
+		},
+	}
+	engine := newSubworkflowTestEngine(mockStore, mockJobStore)
+
+	err := engine.processJob(context.Background(), "job-fork")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "workflow exceeded the maximum of 4 steps")
+	assert.Equal(t, job.StatusFailed, mockJobStore.Jobs["job-fork"].Status)
+}
+
+// spawnModule assembles a WASM module whose _start starts workflowID with
+// execute_target and then prints an empty JSON object
+func spawnModule(workflowID string) []byte {
+	types := []byte{
+		0x03,
+		0x60, 0x06, 0x7f, 0x7f, 0x7f, 0x7f, 0x7f, 0x7f, 0x01, 0x7f, // execute_target
+		0x60, 0x04, 0x7f, 0x7f, 0x7f, 0x7f, 0x01, 0x7f, // fd_write
+		0x60, 0x00, 0x00, // _start
+	}
+	imports := concatBytes([]byte{0x02},
+		wasmName("env"), wasmName("execute_target"), []byte{0x00, 0x00},
+		wasmName("wasi_snapshot_preview1"), wasmName("fd_write"), []byte{0x00, 0x01})
+	exports := concatBytes([]byte{0x02},
+		wasmName("_start"), []byte{0x00, 0x02},
+		wasmName("memory"), []byte{0x02, 0x00})
+
+	// Memory holds an iovec for stdout at 0, then stdout, the target type,
+	// the workflow ID and the params
+	stdout, targetType, params := "{}", "workflow", "{}"
+	segment := make([]byte, 16)
+	binary.LittleEndian.PutUint32(segment[0:], 16)
+	binary.LittleEndian.PutUint32(segment[4:], uint32(len(stdout)))
+	segment = append(segment, stdout+targetType+workflowID+params...)
+	typePtr := 16 + len(stdout)
+	idPtr := typePtr + len(targetType)
+	paramsPtr := idPtr + len(workflowID)
+
+	i32 := func(n int) []byte { return append([]byte{0x41}, wasmSLEB(n)...) }
+
+	// execute_target(...); drop; fd_write(1, 0, 1, 8); drop
+	body := concatBytes([]byte{0x00},
+		i32(typePtr), i32(len(targetType)), i32(idPtr), i32(len(workflowID)), i32(paramsPtr), i32(len(params)),
+		[]byte{0x10, 0x00, 0x1a},
+		[]byte{0x41, 0x01, 0x41, 0x00, 0x41, 0x01, 0x41, 0x08, 0x10, 0x01, 0x1a, 0x0b})
+
+	return wasmModule(
+		wasmSection(0x01, types...),
+		wasmSection(0x02, imports...),
+		wasmSection(0x03, 0x01, 0x02),
+		wasmSection(0x05, 0x01, 0x00, 0x01),
+		wasmSection(0x07, exports...),
+		wasmSection(0x0a, concatBytes([]byte{0x01}, wasmULEB(len(body)), body)...),
+		wasmSection(0x0b, concatBytes([]byte{0x01, 0x00, 0x41, 0x00, 0x0b}, wasmULEB(len(segment)), segment)...),
+	)
+}
+
+func TestSpawnedJobsShareRootStepBudget(t *testing.T) {
+	// Each run of the workflow submits another run of itself as a new job
+	moduleID := "module-spawn"
+	mockStore := &MockPrimitiveStore{
+		Workflows: []*primitive.Workflow{{ID: "workflow-spawn", Name: "Spawn"}},
+		WorkflowSteps: []*primitive.WorkflowStep{
+			{ID: "step-spawn", WorkflowID: "workflow-spawn", StepOrder: 1, StepType: "wasm_module", WasmModuleID: &moduleID},
+		},
+		WasmModules: []*primitive.WasmModuleListItem{{ID: moduleID, Name: "spawn"}},
+		Settings:    []*primitive.Setting{{Key: "max_workflow_steps", Value: "3"}},
+	}
+	mockJobStore := &MockJobStore{
+		Jobs: map[string]*job.Job{
+			"job-root": {ID: "job-root", WorkflowID: "workflow-spawn", Status: job.StatusQueued, InputData: map[string]interface{}{"prompt": "go"}, CreatedAt: time.Now()},
+		},
+	}
+	engine := newSubworkflowTestEngine(mockStore, mockJobStore)
+	engine.wasmExecutor.WorkflowEngine = engine
+	engine.wasmExecutor.Modules()[moduleID] = spawnModule("workflow-spawn")
+
+	// Run queued jobs one at a time, as a single worker would, with a bound
+	// in case the budget is not shared
+	var processed []string
+	for i := 0; i < 10; i++ {
+		next, err := mockJobStore.GetNextQueuedJob()
+		if err != nil {
+			break
+		}
+		processed = append(processed, next.ID)
+		_ = engine.processJob(context.Background(), next.ID)
+	}
+
+	require.Len(t, processed, 4, "three steps run and the fourth job is stopped before spawning another")
+	for _, jobID := range processed[:3] {
+		assert.Equal(t, job.StatusCompleted, mockJobStore.Jobs[jobID].Status, jobID)
+	}
+	assert.Equal(t, job.StatusFailed, mockJobStore.Jobs[processed[3]].Status)
+
+	var stepErrors []string
+	for _, step := range mockJobStore.Steps {
+		if step.ErrorMessage != "" {
+			stepErrors = append(stepErrors, step.ErrorMessage)
+		}
+	}
+	assert.Equal(t, []string{"workflow exceeded the maximum of 3 steps"}, stepErrors)
+	assert.Empty(t, engine.stepBudgets, "the budget is released once every job has finished")
+	assert.Empty(t, engine.jobRoots)
+}
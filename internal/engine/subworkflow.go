@@ -0,0 +1,127 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/mule-ai/mule/internal/agent"
+	"github.com/mule-ai/mule/internal/primitive"
+)
+
+// maxSubworkflowDepth limits how deeply subworkflow steps may nest, guarding
+// against workflows that invoke themselves directly or indirectly
+const maxSubworkflowDepth = 5
+
+// subworkflowDepthKey is the context key holding the current subworkflow depth
+type subworkflowDepthKey struct{}
+
+// resolveWorkflow finds a workflow by ID, falling back to a case-insensitive name match
+func (e *Engine) resolveWorkflow(ctx context.Context, ref string) (*primitive.Workflow, error) {
+	workflow, err := e.store.GetWorkflow(ctx, ref)
+	if err == nil {
+		return workflow, nil
+	}
+	if err != primitive.ErrNotFound {
+		return nil, fmt.Errorf("failed to get workflow: %w", err)
+	}
+
+	workflows, err := e.store.ListWorkflows(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list workflows: %w", err)
+	}
+	for _, w := range workflows {
+		if strings.EqualFold(w.Name, ref) {
+			return w, nil
+		}
+	}
+
+	return nil, fmt.Errorf("workflow not found: %s", ref)
+}
+
+// processSubworkflowStep runs another workflow, named by the "workflow" key in
+// the step config, inline as part of the current job. The child steps run
+// synchronously with the parent's context, so they share its timeout and
+// cancellation, and the child's final output becomes this step's result.
+// Child steps are not recorded as separate job steps.
+func (e *Engine) processSubworkflowStep(ctx context.Context, step *primitive.WorkflowStep, inputData map[string]interface{}, workingDir string) (map[string]interface{}, error) {
+	// Check for context cancellation before processing
+	select {
+	case <-ctx.Done():
+		return nil, fmt.Errorf("subworkflow step cancelled: %w", ctx.Err())
+	default:
+	}
+
+	ref, _ := step.Config["workflow"].(string)
+	if strings.TrimSpace(ref) == "" {
+		return nil, fmt.Errorf("workflow not found in subworkflow step config")
+	}
+
+	depth, _ := ctx.Value(subworkflowDepthKey{}).(int)
+	if depth >= maxSubworkflowDepth {
+		return nil, fmt.Errorf("subworkflow nesting exceeds maximum depth of %d", maxSubworkflowDepth)
+	}
+
+	workflow, err := e.resolveWorkflow(ctx, ref)
+	if err != nil {
+		return nil, err
+	}
+
+	steps, err := e.store.ListWorkflowSteps(ctx, workflow.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get steps for subworkflow %s: %w", workflow.Name, err)
+	}
+
+	log.Printf("Running subworkflow %s (%d steps) at depth %d", workflow.Name, len(steps), depth+1)
+
+	childCtx := context.WithValue(ctx, subworkflowDepthKey{}, depth+1)
+	output := inputData
+	currentDir := workingDir
+	var totalUsage agent.ChatCompletionUsage
+	hasUsage := false
+
+	for _, childStep := range steps {
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("subworkflow %s cancelled: %w", workflow.Name, ctx.Err())
+		default:
+		}
+
+		result, err := e.processStepWithWorkingDir(childCtx, childStep, output, currentDir)
+		if err != nil {
+			return nil, fmt.Errorf("subworkflow %s step %d failed: %w", workflow.Name, childStep.StepOrder, err)
+		}
+
+		// Carry working directory changes through the child steps
+		if newDir, ok := result["working_directory"].(string); ok && newDir != "" {
+			currentDir = newDir
+			delete(result, "working_directory")
+		}
+
+		// Total token usage rather than passing it between child steps
+		if usage, ok := extractUsage(result); ok {
+			totalUsage = totalUsage.Add(usage)
+			hasUsage = true
+		}
+
+		output = result
+	}
+
+	finalResult := make(map[string]interface{}, len(output)+2)
+	for k, v := range output {
+		finalResult[k] = v
+	}
+
+	// Let the parent job pick up a working directory set inside the subworkflow
+	if currentDir != workingDir {
+		finalResult["working_directory"] = currentDir
+	}
+
+	// Report the usage of the child steps as the usage of this step
+	if hasUsage {
+		finalResult["usage"] = totalUsage.ToMap()
+	}
+
+	return finalResult, nil
+}
@@ -0,0 +1,102 @@
+package engine
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mule-ai/mule/internal/agent"
+	"github.com/mule-ai/mule/internal/primitive"
+	"github.com/mule-ai/mule/pkg/job"
+)
+
+func newSubworkflowTestEngine(store *MockPrimitiveStore, jobStore *MockJobStore) *Engine {
+	agentRuntime := agent.NewRuntime(store, jobStore)
+	return NewEngine(store, jobStore, agentRuntime, NewWASMExecutor(nil, store, agentRuntime, nil), Config{Workers: 1})
+}
+
+func TestProcessJobChainsSubworkflows(t *testing.T) {
+	// The parent runs workflow A and then workflow B, each of which is a
+	// no-step stub that passes its input through
+	mockStore := &MockPrimitiveStore{
+		Workflows: []*primitive.Workflow{
+			{ID: "workflow-parent", Name: "Parent"},
+			{ID: "workflow-a", Name: "Stage A"},
+			{ID: "workflow-b", Name: "Stage B"},
+		},
+		WorkflowSteps: []*primitive.WorkflowStep{
+			{ID: "step-a", WorkflowID: "workflow-parent", StepOrder: 1, StepType: "subworkflow", Config: map[string]interface{}{"workflow": "stage a"}},
+			{ID: "step-b", WorkflowID: "workflow-parent", StepOrder: 2, StepType: "subworkflow", Config: map[string]interface{}{"workflow": "workflow-b"}},
+		},
+	}
+	mockJobStore := &MockJobStore{
+		Jobs: map[string]*job.Job{
+			"job-chain": {
+				ID:         "job-chain",
+				WorkflowID: "workflow-parent",
+				Status:     job.StatusQueued,
+				InputData:  map[string]interface{}{"prompt": "hello"},
+				CreatedAt:  time.Now(),
+			},
+		},
+	}
+	engine := newSubworkflowTestEngine(mockStore, mockJobStore)
+
+	require.NoError(t, engine.processJob(context.Background(), "job-chain"))
+
+	completed := mockJobStore.Jobs["job-chain"]
+	assert.Equal(t, job.StatusCompleted, completed.Status)
+	assert.Equal(t, "hello", completed.OutputData["prompt"])
+}
+
+func TestProcessSubworkflowStep(t *testing.T) {
+	mockStore := &MockPrimitiveStore{
+		Workflows: []*primitive.Workflow{
+			{ID: "workflow-a", Name: "Stage A"},
+			{ID: "workflow-loop", Name: "Loop"},
+		},
+		WorkflowSteps: []*primitive.WorkflowStep{
+			{ID: "step-loop", WorkflowID: "workflow-loop", StepOrder: 1, StepType: "subworkflow", Config: map[string]interface{}{"workflow": "Loop"}},
+		},
+	}
+	engine := newSubworkflowTestEngine(mockStore, &MockJobStore{Jobs: map[string]*job.Job{}})
+	input := map[string]interface{}{"prompt": "from parent"}
+
+	t.Run("passes input to the child workflow", func(t *testing.T) {
+		step := &primitive.WorkflowStep{StepType: "subworkflow", Config: map[string]interface{}{"workflow": "workflow-a"}}
+		result, err := engine.processSubworkflowStep(context.Background(), step, input, "")
+		require.NoError(t, err)
+		assert.Equal(t, "from parent", result["prompt"])
+	})
+
+	t.Run("fails for an unknown workflow", func(t *testing.T) {
+		step := &primitive.WorkflowStep{StepType: "subworkflow", Config: map[string]interface{}{"workflow": "missing"}}
+		_, err := engine.processSubworkflowStep(context.Background(), step, input, "")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "workflow not found: missing")
+	})
+
+	t.Run("fails without a workflow in config", func(t *testing.T) {
+		_, err := engine.processSubworkflowStep(context.Background(), &primitive.WorkflowStep{StepType: "subworkflow"}, input, "")
+		require.Error(t, err)
+	})
+
+	t.Run("stops self-referencing workflows at the depth limit", func(t *testing.T) {
+		step := &primitive.WorkflowStep{StepType: "subworkflow", Config: map[string]interface{}{"workflow": "Loop"}}
+		_, err := engine.processSubworkflowStep(context.Background(), step, input, "")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "maximum depth")
+	})
+
+	t.Run("respects parent cancellation", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		step := &primitive.WorkflowStep{StepType: "subworkflow", Config: map[string]interface{}{"workflow": "workflow-a"}}
+		_, err := engine.processSubworkflowStep(ctx, step, input, "")
+		require.Error(t, err)
+		assert.ErrorIs(t, err, context.Canceled)
+	})
+}
@@ -0,0 +1,60 @@
+package engine
+
+import (
+	"context"
+	"strings"
+)
+
+// allowedURLsKey is the context key holding the URL prefixes the executing
+// module may request
+type allowedURLsKey struct{}
+
+// withAllowedURLs restricts the HTTP host functions to the URL prefixes in the
+// module's allowed_urls config, given as a list or a comma-separated string.
+// The module list narrows the executor's global allow-list rather than
+// replacing it. Without that setting, or with an empty list, only the global
+// list applies. Like allowed_http_methods, it is read from the stored module
+// config so callers cannot lift it.
+func withAllowedURLs(ctx context.Context, config map[string]interface{}) context.Context {
+	var prefixes []string
+	switch v := config["allowed_urls"].(type) {
+	case []interface{}:
+		for _, p := range v {
+			if s, ok := p.(string); ok {
+				prefixes = append(prefixes, s)
+			}
+		}
+	case []string:
+		prefixes = v
+	case string:
+		prefixes = strings.Split(v, ",")
+	default:
+		return ctx
+	}
+
+	var cleaned []string
+	for _, prefix := range prefixes {
+		if prefix = strings.TrimSpace(prefix); prefix != "" {
+			cleaned = append(cleaned, prefix)
+		}
+	}
+	if len(cleaned) == 0 {
+		return ctx
+	}
+	return context.WithValue(ctx, allowedURLsKey{}, cleaned)
+}
+
+// moduleURLAllowed reports whether urlStr matches the executing module's own
+// allow-list. It is checked in addition to isURLAllowed.
+func moduleURLAllowed(ctx context.Context, urlStr string) bool {
+	prefixes, ok := ctx.Value(allowedURLsKey{}).([]string)
+	if !ok {
+		return true
+	}
+	for _, prefix := range prefixes {
+		if strings.HasPrefix(urlStr, prefix) {
+			return true
+		}
+	}
+	return false
+}
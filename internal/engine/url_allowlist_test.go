@@ -0,0 +1,57 @@
+package engine
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestModuleURLAllowList(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	executor := NewWASMExecutor(nil, &MockPrimitiveStore{}, nil, nil)
+	mem := newFakeMemory(512)
+	module := &fakeModule{mem: mem}
+	methodPtr, methodSize := mem.put(0, "GET")
+
+	request := func(ctx context.Context, url string) uint32 {
+		urlPtr, urlSize := mem.put(16, url)
+		return executor.httpRequestWithHeaders(ctx, module, methodPtr, methodSize, urlPtr, urlSize, 0, 0, 0, 0, 0)
+	}
+
+	githubOnly := withAllowedURLs(context.Background(), map[string]interface{}{
+		"allowed_urls": []interface{}{"https://api.github.com/"},
+	})
+
+	t.Run("restricted module is denied other hosts", func(t *testing.T) {
+		assert.Equal(t, uint32(0xFFFFFFFE), request(githubOnly, "https://example.com/"))
+		assert.True(t, moduleURLAllowed(githubOnly, "https://api.github.com/repos/mule-ai/mule"))
+	})
+
+	t.Run("global list still applies", func(t *testing.T) {
+		executor.SetURLAllowList([]string{"https://example.com/"})
+		defer executor.SetURLAllowList([]string{"http://", "https://"})
+
+		ctx := withAllowedURLs(context.Background(), map[string]interface{}{"allowed_urls": server.URL})
+		assert.Equal(t, uint32(0xFFFFFFFE), request(ctx, server.URL))
+	})
+
+	t.Run("empty module list falls back to global", func(t *testing.T) {
+		ctx := withAllowedURLs(context.Background(), map[string]interface{}{"allowed_urls": []interface{}{}})
+		assert.Equal(t, uint32(0), request(ctx, server.URL))
+
+		ctx = withAllowedURLs(context.Background(), map[string]interface{}{"allowed_urls": " , "})
+		assert.Equal(t, uint32(0), request(ctx, server.URL))
+	})
+
+	t.Run("module list allows its own prefixes", func(t *testing.T) {
+		ctx := withAllowedURLs(context.Background(), map[string]interface{}{"allowed_urls": "https://api.github.com/, " + server.URL})
+		assert.Equal(t, uint32(0), request(ctx, server.URL))
+	})
+}
@@ -0,0 +1,13 @@
+package engine
+
+import "github.com/mule-ai/mule/internal/agent"
+
+// extractUsage removes the token usage reported by a step from its result and
+// returns it. Results without token counts under "usage" are left untouched.
+func extractUsage(result map[string]interface{}) (agent.ChatCompletionUsage, bool) {
+	usage, ok := agent.UsageFromMap(result["usage"])
+	if ok {
+		delete(result, "usage")
+	}
+	return usage, ok
+}
@@ -0,0 +1,74 @@
+package engine
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mule-ai/mule/internal/primitive"
+	"github.com/mule-ai/mule/pkg/job"
+)
+
+func TestExtractUsage(t *testing.T) {
+	result := map[string]interface{}{
+		"prompt": "hello",
+		"usage":  map[string]interface{}{"prompt_tokens": 3, "completion_tokens": 4, "total_tokens": 7},
+	}
+	usage, ok := extractUsage(result)
+	require.True(t, ok)
+	assert.Equal(t, 7, usage.TotalTokens)
+	assert.NotContains(t, result, "usage")
+
+	// A "usage" value without token counts belongs to the step output
+	result = map[string]interface{}{"usage": "see README"}
+	_, ok = extractUsage(result)
+	assert.False(t, ok)
+	assert.Equal(t, "see README", result["usage"])
+}
+
+func TestProcessJobRecordsStepUsage(t *testing.T) {
+	// Each subworkflow step runs a no-step stub, so it reports the usage it
+	// was given as input, standing in for a provider response
+	mockStore := &MockPrimitiveStore{
+		Workflows: []*primitive.Workflow{
+			{ID: "workflow-parent", Name: "Parent"},
+			{ID: "workflow-stub", Name: "Stub"},
+		},
+		WorkflowSteps: []*primitive.WorkflowStep{
+			{ID: "step-1", WorkflowID: "workflow-parent", StepOrder: 1, StepType: "subworkflow", Config: map[string]interface{}{"workflow": "workflow-stub"}},
+		},
+	}
+	mockJobStore := &MockJobStore{
+		Jobs: map[string]*job.Job{
+			"job-usage": {
+				ID:         "job-usage",
+				WorkflowID: "workflow-parent",
+				Status:     job.StatusQueued,
+				InputData: map[string]interface{}{
+					"prompt": "hello",
+					"usage":  map[string]interface{}{"prompt_tokens": 10, "completion_tokens": 5, "total_tokens": 15},
+				},
+				CreatedAt: time.Now(),
+			},
+		},
+	}
+	engine := newSubworkflowTestEngine(mockStore, mockJobStore)
+
+	require.NoError(t, engine.processJob(context.Background(), "job-usage"))
+
+	steps, err := mockJobStore.ListJobSteps("job-usage")
+	require.NoError(t, err)
+	require.Len(t, steps, 1)
+	require.NotNil(t, steps[0].StartedAt)
+	require.NotNil(t, steps[0].CompletedAt)
+	assert.False(t, steps[0].CompletedAt.Before(*steps[0].StartedAt))
+	assert.Equal(t, map[string]interface{}{"prompt_tokens": 10, "completion_tokens": 5, "total_tokens": 15}, steps[0].OutputData["usage"])
+
+	completed := mockJobStore.Jobs["job-usage"]
+	assert.Equal(t, job.StatusCompleted, completed.Status)
+	assert.Equal(t, "hello", completed.OutputData["prompt"])
+	assert.Equal(t, map[string]interface{}{"prompt_tokens": 10, "completion_tokens": 5, "total_tokens": 15}, completed.OutputData["usage"])
+}
@@ -15,6 +15,7 @@ import (
 	"path/filepath"
 	"runtime/debug"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/tetratelabs/wazero"
@@ -75,6 +76,51 @@ func isValidBranchName(name string) bool {
 	return true
 }
 
+// isValidWorktreeName validates that a worktree name is safe to use as a
+// directory name next to the repository and as a git argument. Modules are
+// expected to check this themselves, but the host does not rely on it.
+func isValidWorktreeName(name string) bool {
+	// Check for empty name
+	if name == "" {
+		return false
+	}
+
+	// Check for path separators and traversal
+	invalidChars := []string{"/", "\\", "..", "~"}
+	for _, char := range invalidChars {
+		if strings.Contains(name, char) {
+			return false
+		}
+	}
+
+	// Check for a leading dash, which git would read as an option
+	if strings.HasPrefix(name, "-") {
+		return false
+	}
+
+	// Check for whitespace and shell metacharacters
+	if strings.ContainsAny(name, " \t\n\r;&|$`<>(){}[]*?!'\"#") {
+		return false
+	}
+
+	// Check for reserved names
+	reservedNames := []string{".", "..", "HEAD", "ORIG_HEAD", "FETCH_HEAD", "MERGE_HEAD", "CHERRY_PICK_HEAD"}
+	for _, reserved := range reservedNames {
+		if name == reserved {
+			return false
+		}
+	}
+
+	// Check for control characters
+	for _, r := range name {
+		if r < 32 || r == 127 {
+			return false
+		}
+	}
+
+	return true
+}
+
 // WASMExecutor handles WebAssembly module execution
 type WASMExecutor struct {
 	db             *sql.DB
@@ -83,17 +129,41 @@ type WASMExecutor struct {
 	WorkflowEngine *Engine
 	modules        map[string][]byte // Store compiled module bytes instead of instantiated modules
 	urlAllowed     []string          // List of allowed URL prefixes for HTTP requests
+	httpTimeout    time.Duration     // Default timeout for HTTP requests made by modules
+	maxStderrBytes int               // Maximum stderr returned in results and errors
 	workingDir     string            // Current working directory for this execution context
+	// Maximum time a single module execution may run
+	maxExecutionTime time.Duration
+	// HTTP methods whose request bodies default to a JSON Content-Type
+	bodyMethods map[string]bool
 	// Store the last response for each module instance
 	lastResponse     map[string]*http.Response
 	lastResponseBody map[string][]byte
+	// Open response bodies of streaming requests for each module instance
+	streams *responseStreams
 	// Store the last workflow/agent execution result for each module instance
 	lastOperationResult map[string][]byte
 	lastOperationStatus map[string]int
+	// Error message of the last failed operation for each module instance
+	lastOperationError map[string]string
 	// Track new working directory set by modules
 	newWorkingDir map[string]string
 	// Temporary storage for new working directory from current execution
 	currentNewWorkingDir string
+	// Files written to the working directory by each module instance
+	filesMu      sync.Mutex
+	filesWritten map[string][]string
+	// Structured output set by each module instance with set_job_output
+	jobOutputMu sync.Mutex
+	jobOutput   map[string]map[string]interface{}
+	// Whether per-execution trace logs are written
+	debugLogging bool
+	// Secrets available to modules through get_secret
+	secrets *SecretStore
+	// Scratch data shared by modules within a workflow run
+	kv *runKVStore
+	// Recent executions per module for debugging
+	history *executionHistory
 }
 
 // Modules returns the internal modules map for testing purposes
@@ -110,12 +180,23 @@ func NewWASMExecutor(db *sql.DB, store primitive.PrimitiveStore, agentRuntime *a
 		WorkflowEngine:       workflowEngine,
 		modules:              make(map[string][]byte),
 		urlAllowed:           []string{"https://", "http://"}, // Allow all URLs by default (can be configured)
+		httpTimeout:          defaultHTTPTimeout,
+		maxStderrBytes:       defaultMaxStderrBytes,
+		maxExecutionTime:     defaultMaxExecutionTime,
+		bodyMethods:          newMethodSet(defaultBodyMethods),
 		lastResponse:         make(map[string]*http.Response),
 		lastResponseBody:     make(map[string][]byte),
+		streams:              newResponseStreams(),
 		lastOperationResult:  make(map[string][]byte),
 		lastOperationStatus:  make(map[string]int),
+		lastOperationError:   make(map[string]string),
 		newWorkingDir:        make(map[string]string),
 		currentNewWorkingDir: "",
+		filesWritten:         make(map[string][]string),
+		jobOutput:            make(map[string]map[string]interface{}),
+		secrets:              NewSecretStore(),
+		kv:                   newRunKVStore(),
+		history:              newExecutionHistory(defaultExecutionHistorySize),
 	}
 }
 
@@ -333,11 +414,344 @@ func (e *WASMExecutor) get_current_branch_impl(ctx context.Context, module api.M
 	return 0
 }
 
+// createGitWorktree implements the create_git_worktree and
+// create_git_worktree_from_ref host functions. The worktree is checked out
+// at HEAD unless a base ref is given, in which case it is checked out to that
+// ref on a new branch.
+func (e *WASMExecutor) createGitWorktree(ctx context.Context, module api.Module, namePtr, nameSize, basePathPtr, basePathSize, refPtr, refSize, branchPtr, branchSize uint32) uint32 {
+	// Check for context cancellation before processing
+	select {
+	case <-ctx.Done():
+		// Return error code for cancellation
+		return 0xFFFFFFFA
+	default:
+	}
+
+	// Get memory from the module
+	mem := module.Memory()
+
+	// Read worktree name from WASM memory
+	name, err := readStringFromMemory(ctx, mem, namePtr, nameSize)
+	if err != nil {
+		log.Printf("Failed to read worktree name from WASM memory: %v", err)
+		// Return error code (0xFFFFFFF0)
+		return 0xFFFFFFF0
+	}
+
+	// Reject unsafe names before touching the filesystem or running git
+	if !isValidWorktreeName(name) {
+		log.Printf("Invalid worktree name: %q", name)
+		// Return error code (0xFFFFFFF8)
+		return 0xFFFFFFF8
+	}
+
+	// Read base path from WASM memory (optional, can be empty)
+	var basePath string
+	if basePathSize > 0 {
+		basePath, err = readStringFromMemory(ctx, mem, basePathPtr, basePathSize)
+		if err != nil {
+			log.Printf("Failed to read base path from WASM memory: %v", err)
+			// Return error code (0xFFFFFFF1)
+			return 0xFFFFFFF1
+		}
+	}
+
+	// If no base path provided, use current working directory
+	if basePath == "" {
+		basePath = e.workingDir
+		if basePath == "" {
+			cwd, err := os.Getwd()
+			if err != nil {
+				log.Printf("Failed to get current working directory: %v", err)
+				// Return error code (0xFFFFFFF2)
+				return 0xFFFFFFF2
+			}
+			basePath = cwd
+		}
+	}
+
+	// Validate that base path is a git repository
+	gitPath := filepath.Join(basePath, ".git")
+	if _, err := os.Stat(gitPath); os.IsNotExist(err) {
+		log.Printf("Base path is not a git repository: %s", basePath)
+		// Return error code (0xFFFFFFF3)
+		return 0xFFFFFFF3
+	}
+
+	// Read the optional base ref and branch name from WASM memory
+	var ref, branch string
+	if refSize > 0 {
+		ref, err = readStringFromMemory(ctx, mem, refPtr, refSize)
+		if err != nil {
+			log.Printf("Failed to read base ref from WASM memory: %v", err)
+			// Return error code (0xFFFFFFF7)
+			return 0xFFFFFFF7
+		}
+	}
+	if branchSize > 0 {
+		branch, err = readStringFromMemory(ctx, mem, branchPtr, branchSize)
+		if err != nil {
+			log.Printf("Failed to read branch name from WASM memory: %v", err)
+			// Return error code (0xFFFFFFF7)
+			return 0xFFFFFFF7
+		}
+	}
+
+	// A worktree based on a specific ref gets its own branch, named after the
+	// worktree unless a branch name is given
+	if ref != "" && branch == "" {
+		branch = name
+	}
+	if branch != "" && !isValidBranchName(branch) {
+		log.Printf("Invalid branch name for worktree: %s", branch)
+		// Return error code (0xFFFFFFF6)
+		return 0xFFFFFFF6
+	}
+	if ref == "" {
+		ref = "HEAD"
+	} else if !gitRefExists(ctx, basePath, ref) {
+		log.Printf("Base ref does not exist in %s: %s", basePath, ref)
+		// Return error code (0xFFFFFFF5)
+		return 0xFFFFFFF5
+	}
+
+	// Determine worktree path - this should be a sibling directory to the main repo
+	// or in a location specified by the user
+	worktreePath := filepath.Join(basePath, "..", name)
+
+	// If the above would put it inside the repo, put it as a sibling
+	if strings.HasPrefix(worktreePath, basePath) {
+		worktreePath = filepath.Join(basePath, "..", name)
+	}
+
+	// Check if worktree already exists
+	if _, err := os.Stat(worktreePath); err == nil {
+		// Worktree already exists, use it
+		log.Printf("Git worktree '%s' already exists at: %s", name, worktreePath)
+
+		// Store the worktree path in the module's last operation result
+		// This allows the workflow engine to retrieve it after execution
+		key := fmt.Sprintf("%p", module)
+		e.lastOperationResult[key] = []byte(worktreePath)
+		e.lastOperationStatus[key] = 0      // Success
+		e.newWorkingDir[key] = worktreePath // Store new working directory
+		delete(e.lastOperationError, key)
+
+		// Also store in currentNewWorkingDir for this execution
+		e.currentNewWorkingDir = worktreePath
+
+		// Return 0 for success
+		return 0
+	}
+
+	// Create worktree using git command
+	// We'll use the git worktree add command to create a proper worktree
+	args := []string{"worktree", "add"}
+	if branch != "" {
+		args = append(args, "-b", branch)
+	}
+	args = append(args, worktreePath, ref)
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = basePath
+
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		log.Printf("Failed to create git worktree: %v, output: %s", err, string(output))
+		// Return error code (0xFFFFFFF4)
+		return 0xFFFFFFF4
+	}
+
+	// Store the worktree path in the module's last operation result
+	// This allows the workflow engine to retrieve it after execution
+	key := fmt.Sprintf("%p", module)
+	e.lastOperationResult[key] = []byte(worktreePath)
+	e.lastOperationStatus[key] = 0      // Success
+	e.newWorkingDir[key] = worktreePath // Store new working directory
+	delete(e.lastOperationError, key)
+
+	// Also store in currentNewWorkingDir for this execution
+	e.currentNewWorkingDir = worktreePath
+
+	log.Printf("Created git worktree '%s' at: %s", name, worktreePath)
+	// Return 0 for success
+	return 0
+}
+
+// gitRefExists reports whether ref resolves to a commit in the repository at dir
+func gitRefExists(ctx context.Context, dir, ref string) bool {
+	if strings.HasPrefix(ref, "-") {
+		return false
+	}
+	cmd := exec.CommandContext(ctx, "git", "rev-parse", "--verify", "--quiet", ref+"^{commit}")
+	cmd.Dir = dir
+	return cmd.Run() == nil
+}
+
 // SetURLAllowList sets the list of allowed URL prefixes for HTTP requests
 func (e *WASMExecutor) SetURLAllowList(allowed []string) {
 	e.urlAllowed = allowed
 }
 
+// defaultHTTPTimeout is the timeout for module HTTP requests unless changed
+// with SetHTTPTimeout or overridden per request
+const defaultHTTPTimeout = 30 * time.Second
+
+// SetHTTPTimeout sets the default timeout for HTTP requests made by modules.
+// A zero or negative duration restores the 30 second default.
+func (e *WASMExecutor) SetHTTPTimeout(d time.Duration) {
+	if d <= 0 {
+		d = defaultHTTPTimeout
+	}
+	e.httpTimeout = d
+}
+
+// requestTimeout returns the timeout for an HTTP request, preferring the
+// module's timeout in milliseconds when it is non-zero
+func (e *WASMExecutor) requestTimeout(timeoutMs uint32) time.Duration {
+	if timeoutMs > 0 {
+		return time.Duration(timeoutMs) * time.Millisecond
+	}
+	return e.httpTimeout
+}
+
+// httpRequestWithHeaders implements the http_request_with_headers and
+// http_request_with_headers_timeout host functions. A timeoutMs of 0 uses the
+// executor's default HTTP timeout.
+func (e *WASMExecutor) httpRequestWithHeaders(ctx context.Context, module api.Module, methodPtr, methodSize, urlPtr, urlSize, bodyPtr, bodySize, headersPtr, headersSize, timeoutMs uint32) uint32 {
+	// Check for context cancellation before processing
+	select {
+	case <-ctx.Done():
+		// Return error code for cancellation
+		return 0xFFFFFFFA
+	default:
+	}
+
+	// Get memory from the module
+	mem := module.Memory()
+
+	// Read method from WASM memory
+	method, err := readStringFromMemory(ctx, mem, methodPtr, methodSize)
+	if err != nil {
+		log.Printf("Failed to read HTTP method from WASM memory: %v", err)
+		// Return error code (0xFFFFFFF0)
+		return 0xFFFFFFF0
+	}
+
+	// Validate method before doing any other work
+	method, ok := normalizeHTTPMethod(method)
+	if !ok {
+		log.Printf("Unsupported HTTP method: %q", method)
+		// Return error code (0xFFFFFFF8)
+		return 0xFFFFFFF8
+	}
+	if !httpMethodAllowed(ctx, method) {
+		log.Printf("HTTP method %s not allowed for this module", method)
+		// Return error code (0xFFFFFFF9)
+		return 0xFFFFFFF9
+	}
+
+	// Read URL from WASM memory
+	urlStr, err := readStringFromMemory(ctx, mem, urlPtr, urlSize)
+	if err != nil {
+		log.Printf("Failed to read URL from WASM memory: %v", err)
+		// Return error code (0xFFFFFFFF)
+		return 0xFFFFFFFF
+	}
+
+	// Validate URL
+	if !e.isURLAllowed(urlStr) || !moduleURLAllowed(ctx, urlStr) {
+		log.Printf("URL not allowed: %s", urlStr)
+		// Return error code (0xFFFFFFFE)
+		return 0xFFFFFFFE
+	}
+
+	// Read body from WASM memory (can be empty for GET requests)
+	var bodyReader io.Reader
+	if bodySize > 0 {
+		bodyStr, err := readStringFromMemory(ctx, mem, bodyPtr, bodySize)
+		if err != nil {
+			log.Printf("Failed to read HTTP body from WASM memory: %v", err)
+			// Return error code (0xFFFFFFF1)
+			return 0xFFFFFFF1
+		}
+		bodyReader = strings.NewReader(bodyStr)
+	}
+
+	// Read headers from WASM memory (can be empty)
+	var headers map[string]string
+	if headersSize > 0 {
+		headersStr, err := readStringFromMemory(ctx, mem, headersPtr, headersSize)
+		if err != nil {
+			log.Printf("Failed to read HTTP headers from WASM memory: %v", err)
+			// Return error code (0xFFFFFFF2)
+			return 0xFFFFFFF2
+		}
+
+		// Parse headers JSON
+		if err := json.Unmarshal([]byte(headersStr), &headers); err != nil {
+			log.Printf("Failed to parse HTTP headers JSON: %v", err)
+			// Return error code (0xFFFFFFF3)
+			return 0xFFFFFFF3
+		}
+	}
+
+	// Make HTTP request with the module's timeout, or the executor default
+	client := &http.Client{
+		Timeout: e.requestTimeout(timeoutMs),
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, urlStr, bodyReader)
+	if err != nil {
+		log.Printf("Failed to create HTTP request for URL %s: %v", urlStr, err)
+		// Return error code (0xFFFFFFFD)
+		return 0xFFFFFFFD
+	}
+
+	// Set headers
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+
+	// Set Content-Type header for requests with a body if not already set
+	if bodyReader != nil && e.defaultsToJSON(method) {
+		if req.Header.Get("Content-Type") == "" {
+			req.Header.Set("Content-Type", "application/json")
+		}
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		log.Printf("Failed to make HTTP request to %s: %v", urlStr, err)
+		// Return error code (0xFFFFFFFC)
+		return 0xFFFFFFFC
+	}
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			log.Printf("Failed to close response body: %v", err)
+		}
+	}()
+
+	// Read response body
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		log.Printf("Failed to read response body from %s: %v", urlStr, err)
+		// Return error code (0xFFFFFFFB)
+		return 0xFFFFFFFB
+	}
+
+	// Store response data for retrieval by the module
+	// Use a unique key for this execution context
+	key := fmt.Sprintf("%p", module)
+	e.lastResponse[key] = resp
+	e.lastResponseBody[key] = respBody
+
+	// For this simplified interface, we'll just log that the request was successful
+	e.debugf("HTTP %s request to %s completed successfully with status %d", method, urlStr, resp.StatusCode)
+
+	// Return 0 for success
+	return 0
+}
+
 // Execute executes a WASM module with the given input data and working directory.
 // It handles the complete lifecycle of WASM module execution:
 //
@@ -355,6 +769,7 @@ func (e *WASMExecutor) SetURLAllowList(allowed []string) {
 //   - workflow_trigger: Trigger another workflow and get results
 //   - agent_call: Call an agent and get response
 //   - http_request: Make HTTP requests with configurable allowlist
+//   - http_request_streaming: Make HTTP requests whose body is read in chunks
 //   - network_check: Check network connectivity
 //   - git_operation: Execute git commands
 //
@@ -365,10 +780,18 @@ func (e *WASMExecutor) SetURLAllowList(allowed []string) {
 // Error Handling:
 //   - Recoverable panics are caught and logged
 //   - Detailed error messages for common failure modes
-func (e *WASMExecutor) Execute(ctx context.Context, moduleID string, inputData map[string]interface{}, workingDir string) (map[string]interface{}, error) {
+func (e *WASMExecutor) Execute(ctx context.Context, moduleID string, inputData map[string]interface{}, workingDir string) (execResult map[string]interface{}, execErr error) {
 	// Store the working directory for use by triggerWorkflow
 	e.workingDir = workingDir
 
+	// Record the execution I/O for the debug endpoint, whatever the outcome
+	startedAt := time.Now()
+	var stdinData []byte
+	var stdoutBuf, stderrBuf bytes.Buffer
+	defer func() {
+		e.recordExecution(moduleID, startedAt, stdinData, stdoutBuf.String(), stderrBuf.String(), execResult, execErr)
+	}()
+
 	// Get module data from cache or load it
 	moduleData, err := e.getModuleData(ctx, moduleID)
 	if err != nil {
@@ -381,13 +804,24 @@ func (e *WASMExecutor) Execute(ctx context.Context, moduleID string, inputData m
 		return nil, fmt.Errorf("failed to get WASM module: %w", err)
 	}
 
+	// Apply the module's HTTP method, URL and secret restrictions to its host function calls
+	ctx = withAllowedHTTPMethods(ctx, module.Config)
+	ctx = withAllowedURLs(ctx, module.Config)
+	ctx = withAllowedSecrets(ctx, module.Config)
+
+	// Host functions that touch files find the working directory in ctx
+	ctx = withWorkingDir(ctx, workingDir)
+
 	// Merge configuration with input data
 	mergedInputData := make(map[string]interface{})
 
 	// Add configuration data if present
 	if len(module.Config) > 0 {
-		// Add all config fields to merged input
+		// Add all config fields to merged input, except the input schema
 		for k, v := range module.Config {
+			if k == inputSchemaConfigKey {
+				continue
+			}
 			mergedInputData[k] = v
 		}
 	}
@@ -397,7 +831,13 @@ func (e *WASMExecutor) Execute(ctx context.Context, moduleID string, inputData m
 		mergedInputData[k] = v
 	}
 
-	log.Printf("Executing WASM module %s (size: %d bytes) with merged input data: %+v", moduleID, len(moduleData), mergedInputData)
+	// Reject input that does not match the module's declared schema
+	if err := validateInputSchema(module.Config, mergedInputData); err != nil {
+		return nil, err
+	}
+
+	log.Printf("Executing WASM module %s (size: %d bytes)", moduleID, len(moduleData))
+	e.debugf("WASM module %s merged input data: %+v", moduleID, mergedInputData)
 
 	// Add panic recovery for WASI-related issues
 	defer func() {
@@ -409,24 +849,27 @@ func (e *WASMExecutor) Execute(ctx context.Context, moduleID string, inputData m
 	}()
 
 	// Serialize merged input data to JSON for passing to WASM module via stdin
-	var stdinData []byte
 	if len(mergedInputData) > 0 {
 		stdinData, err = json.Marshal(mergedInputData)
 		if err != nil {
 			return nil, fmt.Errorf("failed to serialize input data: %w", err)
 		}
-		log.Printf("Passing %d bytes of input data to WASM module via stdin: %s", len(stdinData), string(stdinData))
+		e.debugf("Passing %d bytes of input data to WASM module via stdin: %s", len(stdinData), string(stdinData))
 	} else {
-		log.Printf("No input data provided to WASM module (mergedInputData: %+v)", mergedInputData)
+		e.debugf("No input data provided to WASM module (mergedInputData: %+v)", mergedInputData)
 	}
 
 	// Create buffers for stdin, stdout, and stderr
 	stdinBuf := bytes.NewReader(stdinData)
-	var stdoutBuf, stderrBuf bytes.Buffer
+
+	// Stop modules that run too long, e.g. stuck in an infinite loop
+	ctx, cancelExecution := e.withExecutionDeadline(ctx)
+	defer cancelExecution()
 
 	// Create a fresh runtime for each execution to avoid "randinit twice" error
 	// This is necessary for Go-compiled WASM modules which have single-execution lifecycle
-	runtime := wazero.NewRuntime(ctx)
+	// Closing on context done lets the deadline interrupt a running module
+	runtime := wazero.NewRuntimeWithConfig(ctx, wazero.NewRuntimeConfig().WithCloseOnContextDone(true))
 
 	// Instantiate WASI - provides system functions for Go WASM
 	// This sets up clock_time_get, random_get, and other system functions
@@ -467,6 +910,19 @@ func (e *WASMExecutor) Execute(ctx context.Context, moduleID string, inputData m
 				return 0xFFFFFFF0
 			}
 
+			// Validate method before doing any other work
+			method, ok := normalizeHTTPMethod(method)
+			if !ok {
+				log.Printf("Unsupported HTTP method: %q", method)
+				// Return error code (0xFFFFFFF8)
+				return 0xFFFFFFF8
+			}
+			if !httpMethodAllowed(ctx, method) {
+				log.Printf("HTTP method %s not allowed for this module", method)
+				// Return error code (0xFFFFFFF9)
+				return 0xFFFFFFF9
+			}
+
 			// Read URL from WASM memory
 			urlStr, err := readStringFromMemory(ctx, mem, urlPtr, urlSize)
 			if err != nil {
@@ -476,7 +932,7 @@ func (e *WASMExecutor) Execute(ctx context.Context, moduleID string, inputData m
 			}
 
 			// Validate URL
-			if !e.isURLAllowed(urlStr) {
+			if !e.isURLAllowed(urlStr) || !moduleURLAllowed(ctx, urlStr) {
 				log.Printf("URL not allowed: %s", urlStr)
 				// Return error code (0xFFFFFFFE)
 				return 0xFFFFFFFE
@@ -496,7 +952,7 @@ func (e *WASMExecutor) Execute(ctx context.Context, moduleID string, inputData m
 
 			// Make HTTP request with timeout
 			client := &http.Client{
-				Timeout: 30 * time.Second,
+				Timeout: e.httpTimeout,
 			}
 
 			req, err := http.NewRequestWithContext(ctx, method, urlStr, bodyReader)
@@ -506,8 +962,8 @@ func (e *WASMExecutor) Execute(ctx context.Context, moduleID string, inputData m
 				return 0xFFFFFFFD
 			}
 
-			// Set Content-Type header for POST/PUT requests with body
-			if bodyReader != nil && (method == "POST" || method == "PUT") {
+			// Set Content-Type header for requests with a body
+			if bodyReader != nil && e.defaultsToJSON(method) {
 				req.Header.Set("Content-Type", "application/json")
 			}
 
@@ -538,7 +994,7 @@ func (e *WASMExecutor) Execute(ctx context.Context, moduleID string, inputData m
 			e.lastResponseBody[key] = respBody
 
 			// For this simplified interface, we'll just log that the request was successful
-			log.Printf("HTTP %s request to %s completed successfully with status %d", method, urlStr, resp.StatusCode)
+			e.debugf("HTTP %s request to %s completed successfully with status %d", method, urlStr, resp.StatusCode)
 
 			// Return 0 for success
 			return 0
@@ -548,207 +1004,34 @@ func (e *WASMExecutor) Execute(ctx context.Context, moduleID string, inputData m
 	// Enhanced HTTP function that supports headers
 	hostModule.NewFunctionBuilder().
 		WithFunc(func(ctx context.Context, module api.Module, methodPtr, methodSize, urlPtr, urlSize, bodyPtr, bodySize, headersPtr, headersSize uint32) uint32 {
-			// Check for context cancellation before processing
-			select {
-			case <-ctx.Done():
-				// Return error code for cancellation
-				return 0xFFFFFFFA
-			default:
-			}
-
-			// Get memory from the module
-			mem := module.Memory()
-
-			// Read method from WASM memory
-			method, err := readStringFromMemory(ctx, mem, methodPtr, methodSize)
-			if err != nil {
-				log.Printf("Failed to read HTTP method from WASM memory: %v", err)
-				// Return error code (0xFFFFFFF0)
-				return 0xFFFFFFF0
-			}
-
-			// Read URL from WASM memory
-			urlStr, err := readStringFromMemory(ctx, mem, urlPtr, urlSize)
-			if err != nil {
-				log.Printf("Failed to read URL from WASM memory: %v", err)
-				// Return error code (0xFFFFFFFF)
-				return 0xFFFFFFFF
-			}
-
-			// Validate URL
-			if !e.isURLAllowed(urlStr) {
-				log.Printf("URL not allowed: %s", urlStr)
-				// Return error code (0xFFFFFFFE)
-				return 0xFFFFFFFE
-			}
-
-			// Read body from WASM memory (can be empty for GET requests)
-			var bodyReader io.Reader
-			if bodySize > 0 {
-				bodyStr, err := readStringFromMemory(ctx, mem, bodyPtr, bodySize)
-				if err != nil {
-					log.Printf("Failed to read HTTP body from WASM memory: %v", err)
-					// Return error code (0xFFFFFFF1)
-					return 0xFFFFFFF1
-				}
-				bodyReader = strings.NewReader(bodyStr)
-			}
-
-			// Read headers from WASM memory (can be empty)
-			var headers map[string]string
-			if headersSize > 0 {
-				headersStr, err := readStringFromMemory(ctx, mem, headersPtr, headersSize)
-				if err != nil {
-					log.Printf("Failed to read HTTP headers from WASM memory: %v", err)
-					// Return error code (0xFFFFFFF2)
-					return 0xFFFFFFF2
-				}
-
-				// Parse headers JSON
-				if err := json.Unmarshal([]byte(headersStr), &headers); err != nil {
-					log.Printf("Failed to parse HTTP headers JSON: %v", err)
-					// Return error code (0xFFFFFFF3)
-					return 0xFFFFFFF3
-				}
-			}
-
-			// Make HTTP request with timeout
-			client := &http.Client{
-				Timeout: 30 * time.Second,
-			}
-
-			req, err := http.NewRequestWithContext(ctx, method, urlStr, bodyReader)
-			if err != nil {
-				log.Printf("Failed to create HTTP request for URL %s: %v", urlStr, err)
-				// Return error code (0xFFFFFFFD)
-				return 0xFFFFFFFD
-			}
-
-			// Set headers
-			for key, value := range headers {
-				req.Header.Set(key, value)
-			}
-
-			// Set Content-Type header for POST/PUT requests with body if not already set
-			if bodyReader != nil && (method == "POST" || method == "PUT") {
-				if req.Header.Get("Content-Type") == "" {
-					req.Header.Set("Content-Type", "application/json")
-				}
-			}
-
-			resp, err := client.Do(req)
-			if err != nil {
-				log.Printf("Failed to make HTTP request to %s: %v", urlStr, err)
-				// Return error code (0xFFFFFFFC)
-				return 0xFFFFFFFC
-			}
-			defer func() {
-				if err := resp.Body.Close(); err != nil {
-					log.Printf("Failed to close response body: %v", err)
-				}
-			}()
-
-			// Read response body
-			respBody, err := io.ReadAll(resp.Body)
-			if err != nil {
-				log.Printf("Failed to read response body from %s: %v", urlStr, err)
-				// Return error code (0xFFFFFFFB)
-				return 0xFFFFFFFB
-			}
+			return e.httpRequestWithHeaders(ctx, module, methodPtr, methodSize, urlPtr, urlSize, bodyPtr, bodySize, headersPtr, headersSize, 0)
+		}).
+		Export("http_request_with_headers")
 
-			// Store response data for retrieval by the module
-			// Use a unique key for this execution context
-			key := fmt.Sprintf("%p", module)
-			e.lastResponse[key] = resp
-			e.lastResponseBody[key] = respBody
+	// Same as http_request_with_headers with a per-request timeout in milliseconds
+	hostModule.NewFunctionBuilder().
+		WithFunc(e.httpRequestWithHeaders).
+		Export("http_request_with_headers_timeout")
 
-			// For this simplified interface, we'll just log that the request was successful
-			log.Printf("HTTP %s request to %s completed successfully with status %d", method, urlStr, resp.StatusCode)
+	// Streaming HTTP functions for responses too large to buffer
+	hostModule.NewFunctionBuilder().
+		WithFunc(e.httpRequestStreaming).
+		Export("http_request_streaming")
+	hostModule.NewFunctionBuilder().
+		WithFunc(e.readResponseChunk).
+		Export("read_response_chunk")
+	hostModule.NewFunctionBuilder().
+		WithFunc(e.closeResponse).
+		Export("close_response")
 
-			// Return 0 for success
-			return 0
-		}).
-		Export("http_request_with_headers")
 	// Add host function for triggering workflows or calling agents
 	// This function can handle both workflows and agents based on the target type
 	hostModule.NewFunctionBuilder().
-		WithFunc(func(ctx context.Context, module api.Module, targetTypePtr, targetTypeSize, targetIDPtr, targetIDSize, paramsPtr, paramsSize uint32) uint32 {
-			// Check for context cancellation before processing
-			select {
-			case <-ctx.Done():
-				// Return error code for cancellation
-				return 0xFFFFFFFA
-			default:
-			}
-
-			// Get memory from the module
-			mem := module.Memory()
-
-			// Read target type from WASM memory
-			targetType, err := readStringFromMemory(ctx, mem, targetTypePtr, targetTypeSize)
-			if err != nil {
-				log.Printf("Failed to read target type from WASM memory: %v", err)
-				// Return error code (0xFFFFFFF0)
-				return 0xFFFFFFF0
-			}
-
-			// Read target ID from WASM memory
-			targetID, err := readStringFromMemory(ctx, mem, targetIDPtr, targetIDSize)
-			if err != nil {
-				log.Printf("Failed to read target ID from WASM memory: %v", err)
-				// Return error code (0xFFFFFFF1)
-				return 0xFFFFFFF1
-			}
-
-			// Read params from WASM memory
-			paramsJSON, err := readStringFromMemory(ctx, mem, paramsPtr, paramsSize)
-			if err != nil {
-				log.Printf("Failed to read params from WASM memory: %v", err)
-				// Return error code (0xFFFFFFF2)
-				return 0xFFFFFFF2
-			}
-
-			// Parse params JSON
-			var params map[string]interface{}
-			if paramsJSON != "" {
-				if err := json.Unmarshal([]byte(paramsJSON), &params); err != nil {
-					log.Printf("Failed to parse params JSON: %v", err)
-					// Return error code (0xFFFFFFF3)
-					return 0xFFFFFFF3
-				}
-			} else {
-				params = make(map[string]interface{})
-			}
-
-			// Execute based on target type
-			var result []byte
-			switch strings.ToLower(targetType) {
-			case "workflow":
-				result, err = e.triggerWorkflow(ctx, targetID, params)
-			case "agent":
-				result, err = e.callAgent(ctx, targetID, params)
-			default:
-				log.Printf("Invalid target type: %s", targetType)
-				// Return error code (0xFFFFFFF4)
-				return 0xFFFFFFF4
-			}
-
-			if err != nil {
-				log.Printf("Failed to execute %s %s: %v", targetType, targetID, err)
-				// Return error code (0xFFFFFFF5)
-				return 0xFFFFFFF5
-			}
-
-			// Store result for retrieval by the module
-			// Use a unique key for this execution context
-			key := fmt.Sprintf("%p", module)
-			e.lastOperationResult[key] = result
-			e.lastOperationStatus[key] = 0 // Success
-
-			// Return 0 for success
-			return 0
-		}).
+		WithFunc(e.executeTarget).
 		Export("execute_target")
+	hostModule.NewFunctionBuilder().
+		WithFunc(e.executeAgent).
+		Export("execute_agent")
 
 	// Add host function for retrieving the last operation result
 	// Function to execute bash commands
@@ -830,7 +1113,7 @@ func (e *WASMExecutor) Execute(ctx context.Context, moduleID string, inputData m
 				if exitErr, ok := err.(*exec.ExitError); ok {
 					exitCode = exitErr.ExitCode()
 				}
-				e.lastOperationStatus[key] = exitCode
+				e.setOperationError(key, exitCode, fmt.Errorf("command failed: %w", err))
 
 				log.Printf("Command failed: %v, output: %s", err, string(output))
 				// Return error code (0xFFFFFFF5) for command failure
@@ -841,6 +1124,7 @@ func (e *WASMExecutor) Execute(ctx context.Context, moduleID string, inputData m
 			key := fmt.Sprintf("%p", module)
 			e.lastOperationResult[key] = output
 			e.lastOperationStatus[key] = 0 // Success status
+			delete(e.lastOperationError, key)
 
 			log.Printf("Command executed successfully: %s", command)
 			// Return 0 for success
@@ -1137,113 +1421,24 @@ func (e *WASMExecutor) Execute(ctx context.Context, moduleID string, inputData m
 	// Function to create a git worktree
 	hostModule.NewFunctionBuilder().
 		WithFunc(func(ctx context.Context, module api.Module, namePtr, nameSize, basePathPtr, basePathSize uint32) uint32 {
-			// Check for context cancellation before processing
-			select {
-			case <-ctx.Done():
-				// Return error code for cancellation
-				return 0xFFFFFFFA
-			default:
-			}
-
-			// Get memory from the module
-			mem := module.Memory()
-
-			// Read worktree name from WASM memory
-			name, err := readStringFromMemory(ctx, mem, namePtr, nameSize)
-			if err != nil {
-				log.Printf("Failed to read worktree name from WASM memory: %v", err)
-				// Return error code (0xFFFFFFF0)
-				return 0xFFFFFFF0
-			}
-
-			// Read base path from WASM memory (optional, can be empty)
-			var basePath string
-			if basePathSize > 0 {
-				basePath, err = readStringFromMemory(ctx, mem, basePathPtr, basePathSize)
-				if err != nil {
-					log.Printf("Failed to read base path from WASM memory: %v", err)
-					// Return error code (0xFFFFFFF1)
-					return 0xFFFFFFF1
-				}
-			}
-
-			// If no base path provided, use current working directory
-			if basePath == "" {
-				basePath = e.workingDir
-				if basePath == "" {
-					cwd, err := os.Getwd()
-					if err != nil {
-						log.Printf("Failed to get current working directory: %v", err)
-						// Return error code (0xFFFFFFF2)
-						return 0xFFFFFFF2
-					}
-					basePath = cwd
-				}
-			}
-
-			// Validate that base path is a git repository
-			gitPath := filepath.Join(basePath, ".git")
-			if _, err := os.Stat(gitPath); os.IsNotExist(err) {
-				log.Printf("Base path is not a git repository: %s", basePath)
-				// Return error code (0xFFFFFFF3)
-				return 0xFFFFFFF3
-			}
-
-			// Determine worktree path - this should be a sibling directory to the main repo
-			// or in a location specified by the user
-			worktreePath := filepath.Join(basePath, "..", name)
-
-			// If the above would put it inside the repo, put it as a sibling
-			if strings.HasPrefix(worktreePath, basePath) {
-				worktreePath = filepath.Join(basePath, "..", name)
-			}
-
-			// Check if worktree already exists
-			if _, err := os.Stat(worktreePath); err == nil {
-				// Worktree already exists, use it
-				log.Printf("Git worktree '%s' already exists at: %s", name, worktreePath)
-
-				// Store the worktree path in the module's last operation result
-				// This allows the workflow engine to retrieve it after execution
-				key := fmt.Sprintf("%p", module)
-				e.lastOperationResult[key] = []byte(worktreePath)
-				e.lastOperationStatus[key] = 0      // Success
-				e.newWorkingDir[key] = worktreePath // Store new working directory
-
-				// Also store in currentNewWorkingDir for this execution
-				e.currentNewWorkingDir = worktreePath
-
-				// Return 0 for success
-				return 0
-			}
-
-			// Create worktree using git command
-			// We'll use the git worktree add command to create a proper worktree
-			cmd := exec.CommandContext(ctx, "git", "worktree", "add", worktreePath, "HEAD")
-			cmd.Dir = basePath
-
-			output, err := cmd.CombinedOutput()
-			if err != nil {
-				log.Printf("Failed to create git worktree: %v, output: %s", err, string(output))
-				// Return error code (0xFFFFFFF4)
-				return 0xFFFFFFF4
-			}
+			return e.createGitWorktree(ctx, module, namePtr, nameSize, basePathPtr, basePathSize, 0, 0, 0, 0)
+		}).
+		Export("create_git_worktree")
 
-			// Store the worktree path in the module's last operation result
-			// This allows the workflow engine to retrieve it after execution
-			key := fmt.Sprintf("%p", module)
-			e.lastOperationResult[key] = []byte(worktreePath)
-			e.lastOperationStatus[key] = 0      // Success
-			e.newWorkingDir[key] = worktreePath // Store new working directory
+	// Function to create a git worktree from a base ref on a new branch
+	hostModule.NewFunctionBuilder().
+		WithFunc(e.createGitWorktree).
+		Export("create_git_worktree_from_ref")
 
-			// Also store in currentNewWorkingDir for this execution
-			e.currentNewWorkingDir = worktreePath
+	// Function to send a HEAD request without downloading the body
+	hostModule.NewFunctionBuilder().
+		WithFunc(e.httpHead).
+		Export("http_head")
 
-			log.Printf("Created git worktree '%s' at: %s", name, worktreePath)
-			// Return 0 for success
-			return 0
-		}).
-		Export("create_git_worktree")
+	// Function to get all headers of the last response as JSON
+	hostModule.NewFunctionBuilder().
+		WithFunc(e.getLastResponseHeaders).
+		Export("get_last_response_headers")
 
 	// Function to get the last response header value
 	hostModule.NewFunctionBuilder().
@@ -1323,7 +1518,7 @@ func (e *WASMExecutor) Execute(ctx context.Context, moduleID string, inputData m
 			}
 
 			// Get job from database
-			job, err := e.WorkflowEngine.jobStore.GetJob(jobID)
+			jobItem, err := e.WorkflowEngine.jobStore.GetJob(jobID)
 			if err != nil {
 				log.Printf("Failed to get job %s: %v", jobID, err)
 				// Return error code (0xFFFFFFF1)
@@ -1331,9 +1526,9 @@ func (e *WASMExecutor) Execute(ctx context.Context, moduleID string, inputData m
 			}
 
 			// Create a response that includes both status and output data
-			response := map[string]interface{}{
-				"status": string(job.Status),
-				"output": job.OutputData,
+			response := job.JobOutput{
+				Status: jobItem.Status,
+				Output: jobItem.OutputData,
 			}
 
 			// Marshal response to JSON
@@ -1440,9 +1635,9 @@ func (e *WASMExecutor) Execute(ctx context.Context, moduleID string, inputData m
 			}
 
 			// Job completed successfully, create response with output data
-			response := map[string]interface{}{
-				"status": string(jobItem.Status),
-				"output": jobItem.OutputData,
+			response := job.JobOutput{
+				Status: jobItem.Status,
+				Output: jobItem.OutputData,
 			}
 
 			// Marshal response to JSON
@@ -1478,6 +1673,11 @@ func (e *WASMExecutor) Execute(ctx context.Context, moduleID string, inputData m
 		}).
 		Export("wait_for_job_and_get_output")
 
+	// Function to cancel a job started by the module
+	hostModule.NewFunctionBuilder().
+		WithFunc(e.cancelJob).
+		Export("cancel_job")
+
 	// Function to trigger workflows or call agents
 	hostModule.NewFunctionBuilder().
 		WithFunc(func(ctx context.Context, module api.Module, operationTypePtr, operationTypeSize, idPtr, idSize, paramsPtr, paramsSize uint32) uint32 {
@@ -1534,11 +1734,12 @@ func (e *WASMExecutor) Execute(ctx context.Context, moduleID string, inputData m
 				result, err := e.triggerWorkflow(ctx, id, params)
 				if err != nil {
 					log.Printf("Failed to trigger workflow %s: %v", id, err)
-					e.lastOperationStatus[key] = 0xFFFFFFFC // Internal error
+					e.setOperationError(key, 0xFFFFFFFC, err) // Internal error
 					return 0xFFFFFFFC
 				}
 				e.lastOperationResult[key] = result
 				e.lastOperationStatus[key] = 200
+				delete(e.lastOperationError, key)
 				return 0
 
 			case "agent":
@@ -1546,11 +1747,12 @@ func (e *WASMExecutor) Execute(ctx context.Context, moduleID string, inputData m
 				result, err := e.callAgent(ctx, id, params)
 				if err != nil {
 					log.Printf("Failed to call agent %s: %v", id, err)
-					e.lastOperationStatus[key] = 0xFFFFFFFC // Internal error
+					e.setOperationError(key, 0xFFFFFFFC, err) // Internal error
 					return 0xFFFFFFFC
 				}
 				e.lastOperationResult[key] = result
 				e.lastOperationStatus[key] = 200
+				delete(e.lastOperationError, key)
 				return 0
 
 			default:
@@ -1618,6 +1820,11 @@ func (e *WASMExecutor) Execute(ctx context.Context, moduleID string, inputData m
 		}).
 		Export("get_last_operation_status")
 
+	// Function to get the error message of the last failed operation
+	hostModule.NewFunctionBuilder().
+		WithFunc(e.getLastOperationError).
+		Export("get_last_operation_error")
+
 	// Function to get the current working directory
 	hostModule.NewFunctionBuilder().
 		WithFunc(func(ctx context.Context, module api.Module, bufferPtr uint32, bufferSize uint32) uint32 {
@@ -1938,6 +2145,7 @@ func (e *WASMExecutor) Execute(ctx context.Context, moduleID string, inputData m
 			e.lastOperationResult[key] = []byte(fullPath)
 			e.lastOperationStatus[key] = 0  // Success
 			e.newWorkingDir[key] = fullPath // Store new working directory
+			delete(e.lastOperationError, key)
 
 			// Also store in currentNewWorkingDir for this execution
 			e.currentNewWorkingDir = fullPath
@@ -1948,6 +2156,34 @@ func (e *WASMExecutor) Execute(ctx context.Context, moduleID string, inputData m
 		}).
 		Export("set_working_directory")
 
+	// Function to read a file from the execution's working directory
+	hostModule.NewFunctionBuilder().
+		WithFunc(e.readWorkingDirFile).
+		Export("read_working_dir_file")
+
+	// Function to write a file into the execution's working directory
+	hostModule.NewFunctionBuilder().
+		WithFunc(e.writeWorkingDirFile).
+		Export("write_working_dir_file")
+
+	// Function to read a named secret from the engine-side secret store
+	hostModule.NewFunctionBuilder().
+		WithFunc(e.getSecret).
+		Export("get_secret")
+
+	// Function to set the module's structured output explicitly
+	hostModule.NewFunctionBuilder().
+		WithFunc(e.setJobOutput).
+		Export("set_job_output")
+
+	// Functions to share scratch data with other modules in the workflow run
+	hostModule.NewFunctionBuilder().
+		WithFunc(e.kvSet).
+		Export("kv_set")
+	hostModule.NewFunctionBuilder().
+		WithFunc(e.kvGet).
+		Export("kv_get")
+
 	// Instantiate the host module
 	hostModuleInstance, err := hostModule.Instantiate(ctx)
 	if err != nil {
@@ -1984,13 +2220,13 @@ func (e *WASMExecutor) Execute(ctx context.Context, moduleID string, inputData m
 			if mkdirErr := os.MkdirAll(workingDir, 0755); mkdirErr != nil {
 				log.Printf("Warning: failed to create working directory %s: %v", workingDir, mkdirErr)
 			} else {
-				log.Printf("Created working directory: %s", workingDir)
+				e.debugf("Created working directory: %s", workingDir)
 			}
 		}
 
 		// Configure the module with filesystem access
 		config = config.WithFS(os.DirFS(workingDir))
-		log.Printf("Configured WASM module with filesystem access to directory: %s", workingDir)
+		e.debugf("Configured WASM module with filesystem access to directory: %s", workingDir)
 	}
 
 	// Compile the module first
@@ -2004,7 +2240,7 @@ func (e *WASMExecutor) Execute(ctx context.Context, moduleID string, inputData m
 		return nil, fmt.Errorf("failed to compile WASM module: %w", err)
 	}
 
-	log.Printf("WASM module compiled successfully, instantiating...")
+	e.debugf("WASM module compiled successfully, instantiating...")
 
 	// Instantiate the module WITHOUT auto-starting
 	instance, err := runtime.InstantiateModule(ctx, compiledModule, config)
@@ -2017,11 +2253,18 @@ func (e *WASMExecutor) Execute(ctx context.Context, moduleID string, inputData m
 		return nil, fmt.Errorf("failed to instantiate WASM module: %w", err)
 	}
 
-	log.Printf("WASM module instantiated successfully")
+	e.debugf("WASM module instantiated successfully")
+
+	// Close any streaming response the module left open, and forget the
+	// files it wrote and the output it set if the execution fails before
+	// reporting them
+	defer e.streams.close(moduleKey(instance))
+	defer e.takeFilesWritten(moduleKey(instance))
+	defer e.takeJobOutput(moduleKey(instance))
 
 	// Call _initialize to set up Go runtime
 	if initFunc := instance.ExportedFunction("_initialize"); initFunc != nil {
-		log.Printf("Calling _initialize...")
+		e.debugf("Calling _initialize...")
 		_, err = initFunc.Call(ctx)
 		if err != nil {
 			func() {
@@ -2031,11 +2274,11 @@ func (e *WASMExecutor) Execute(ctx context.Context, moduleID string, inputData m
 			}()
 			return nil, fmt.Errorf("error calling _initialize: %w", err)
 		}
-		log.Printf("_initialize executed successfully")
+		e.debugf("_initialize executed successfully")
 	}
 
 	// Call _start to run main() - capture output during this call
-	log.Printf("Calling _start to run main()...")
+	e.debugf("Calling _start to run main()...")
 	if startFunc := instance.ExportedFunction("_start"); startFunc != nil {
 		// Create a channel to receive the result of the WASM execution
 		done := make(chan error, 1)
@@ -2049,10 +2292,29 @@ func (e *WASMExecutor) Execute(ctx context.Context, moduleID string, inputData m
 		// Wait for either the WASM execution to complete or the context to be cancelled
 		select {
 		case err = <-done:
+			if ctx.Err() != nil {
+				// The module was interrupted by the deadline or cancellation
+				func() {
+					if closeErr := runtime.Close(ctx); closeErr != nil {
+						log.Printf("Failed to close runtime: %v", closeErr)
+					}
+				}()
+				return nil, e.executionStoppedError(ctx)
+			}
+
 			// Check if we got a sys.ExitError (which is normal for Go-compiled WASM)
-			if exitErr, ok := err.(*sys.ExitError); ok {
+			if exitErr, ok := err.(*sys.ExitError); ok && exitErr.ExitCode() != 0 {
+				// The module failed; return what it wrote so the caller can see why
+				func() {
+					if closeErr := runtime.Close(ctx); closeErr != nil {
+						log.Printf("Failed to close runtime: %v", closeErr)
+					}
+				}()
+				return nil, fmt.Errorf("WASM module exited with code %d: stdout='%s', stderr='%s'",
+					exitErr.ExitCode(), stdoutBuf.String(), e.truncateStderr(stderrBuf.String()))
+			} else if ok {
 				// This is expected for Go-compiled WASM modules - they call proc_exit after main()
-				log.Printf("WASM module exited with code: %d (normal for Go WASM)", exitErr.ExitCode())
+				e.debugf("WASM module exited with code: %d (normal for Go WASM)", exitErr.ExitCode())
 			} else if err != nil {
 				func() {
 					if closeErr := runtime.Close(ctx); closeErr != nil {
@@ -2061,7 +2323,7 @@ func (e *WASMExecutor) Execute(ctx context.Context, moduleID string, inputData m
 				}()
 				return nil, fmt.Errorf("error calling _start: %w", err)
 			}
-			log.Printf("_start executed successfully")
+			e.debugf("_start executed successfully")
 		case <-ctx.Done():
 			// Context was cancelled, clean up and return error
 			func() {
@@ -2069,7 +2331,7 @@ func (e *WASMExecutor) Execute(ctx context.Context, moduleID string, inputData m
 					log.Printf("Failed to close runtime: %v", closeErr)
 				}
 			}()
-			return nil, fmt.Errorf("WASM execution cancelled: %w", ctx.Err())
+			return nil, e.executionStoppedError(ctx)
 		}
 	} else {
 		func() {
@@ -2099,7 +2361,7 @@ func (e *WASMExecutor) Execute(ctx context.Context, moduleID string, inputData m
 	// Log the captured output for debugging
 	stdoutStr := stdoutBuf.String()
 	stderrStr := stderrBuf.String()
-	log.Printf("WASM module execution - stdout: '%s', stderr: '%s'", stdoutStr, stderrStr)
+	e.debugf("WASM module execution - stdout: '%s', stderr: '%s'", stdoutStr, stderrStr)
 
 	// Try to parse stdout as JSON and extract the message field
 	// If it's valid JSON with a "message" field, return just that value
@@ -2140,7 +2402,7 @@ func (e *WASMExecutor) Execute(ctx context.Context, moduleID string, inputData m
 	result := map[string]interface{}{
 		"output":  output,
 		"stdout":  stdoutStr,
-		"stderr":  stderrStr,
+		"stderr":  e.truncateStderr(stderrStr),
 		"message": "WASM module executed successfully",
 		"success": success,
 	}
@@ -2152,6 +2414,17 @@ func (e *WASMExecutor) Execute(ctx context.Context, moduleID string, inputData m
 		e.currentNewWorkingDir = ""
 	}
 
+	// Report files the module wrote to the working directory
+	if files := e.takeFilesWritten(moduleKey(instance)); len(files) > 0 {
+		result["files_written"] = files
+	}
+
+	// Output set explicitly with set_job_output takes precedence over stdout
+	if jobOutput, ok := e.takeJobOutput(moduleKey(instance)); ok {
+		result["output"] = jobOutput
+		result["job_output"] = jobOutput
+	}
+
 	return result, nil
 }
 
@@ -2337,16 +2610,9 @@ func (e *WASMExecutor) triggerWorkflow(ctx context.Context, workflowID string, p
 	return json.Marshal(result)
 }
 
-// callAgent calls an agent with the provided parameters
-func (e *WASMExecutor) callAgent(ctx context.Context, agentID string, params map[string]interface{}) ([]byte, error) {
-	// Check for context cancellation before processing
-	select {
-	case <-ctx.Done():
-		return nil, fmt.Errorf("agent call cancelled: %w", ctx.Err())
-	default:
-	}
-
-	// Check if the agent exists
+// resolveAgent looks up an agent by ID, falling back to a case-insensitive
+// match on its name
+func (e *WASMExecutor) resolveAgent(ctx context.Context, agentID string) (*primitive.Agent, error) {
 	agentModel, err := e.store.GetAgent(ctx, agentID)
 	if err != nil {
 		if err == primitive.ErrNotFound {
@@ -2360,7 +2626,6 @@ func (e *WASMExecutor) callAgent(ctx context.Context, agentID string, params map
 			for _, a := range agents {
 				if strings.EqualFold(a.Name, agentID) {
 					agentModel = a
-					agentID = a.ID
 					found = true
 					break
 				}
@@ -2374,6 +2639,23 @@ func (e *WASMExecutor) callAgent(ctx context.Context, agentID string, params map
 		}
 	}
 
+	return agentModel, nil
+}
+
+// callAgent calls an agent with the provided parameters
+func (e *WASMExecutor) callAgent(ctx context.Context, agentID string, params map[string]interface{}) ([]byte, error) {
+	// Check for context cancellation before processing
+	select {
+	case <-ctx.Done():
+		return nil, fmt.Errorf("agent call cancelled: %w", ctx.Err())
+	default:
+	}
+
+	agentModel, err := e.resolveAgent(ctx, agentID)
+	if err != nil {
+		return nil, err
+	}
+
 	// Prepare the chat completion request
 	req := &agent.ChatCompletionRequest{
 		Model: fmt.Sprintf("agent/%s", agentModel.Name),
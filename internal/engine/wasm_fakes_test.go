@@ -0,0 +1,105 @@
+package engine
+
+import (
+	"github.com/tetratelabs/wazero/api"
+)
+
+// fakeMemory is a minimal api.Memory backed by a byte slice, for calling host
+// function implementations directly without instantiating a WASM module
+type fakeMemory struct {
+	api.Memory
+	buf []byte
+}
+
+func newFakeMemory(size int) *fakeMemory {
+	return &fakeMemory{buf: make([]byte, size)}
+}
+
+func (m *fakeMemory) Size() uint32 {
+	return uint32(len(m.buf))
+}
+
+func (m *fakeMemory) Read(offset, byteCount uint32) ([]byte, bool) {
+	if uint64(offset)+uint64(byteCount) > uint64(len(m.buf)) {
+		return nil, false
+	}
+	return m.buf[offset : offset+byteCount], true
+}
+
+func (m *fakeMemory) Write(offset uint32, v []byte) bool {
+	if uint64(offset)+uint64(len(v)) > uint64(len(m.buf)) {
+		return false
+	}
+	copy(m.buf[offset:], v)
+	return true
+}
+
+// put writes s at offset and returns its pointer and size
+func (m *fakeMemory) put(offset uint32, s string) (uint32, uint32) {
+	copy(m.buf[offset:], s)
+	return offset, uint32(len(s))
+}
+
+// fakeModule is a minimal api.Module exposing a fakeMemory
+type fakeModule struct {
+	api.Module
+	mem *fakeMemory
+}
+
+func (m *fakeModule) Memory() api.Memory {
+	return m.mem
+}
+
+// wasmULEB encodes n as an unsigned LEB128 integer
+func wasmULEB(n int) []byte {
+	var out []byte
+	for {
+		b := byte(n & 0x7f)
+		n >>= 7
+		if n == 0 {
+			return append(out, b)
+		}
+		out = append(out, b|0x80)
+	}
+}
+
+// wasmName encodes a length-prefixed WASM name
+func wasmName(s string) []byte {
+	return append(wasmULEB(len(s)), s...)
+}
+
+// wasmSection encodes a WASM section with its id and size
+func wasmSection(id byte, content ...byte) []byte {
+	return append(append([]byte{id}, wasmULEB(len(content))...), content...)
+}
+
+// wasmModule concatenates the WASM header and the given sections
+func wasmModule(sections ...[]byte) []byte {
+	out := []byte{0x00, 0x61, 0x73, 0x6d, 0x01, 0x00, 0x00, 0x00}
+	for _, section := range sections {
+		out = append(out, section...)
+	}
+	return out
+}
+
+// concatBytes joins byte slices
+func concatBytes(parts ...[]byte) []byte {
+	var out []byte
+	for _, p := range parts {
+		out = append(out, p...)
+	}
+	return out
+}
+
+// wasmSLEB encodes n as a signed LEB128 integer, as used by i32.const
+func wasmSLEB(n int) []byte {
+	var out []byte
+	for {
+		b := byte(n & 0x7f)
+		n >>= 7
+		if (n == 0 && b&0x40 == 0) || (n == -1 && b&0x40 != 0) {
+			return append(out, b)
+		}
+		out = append(out, b|0x80)
+	}
+}
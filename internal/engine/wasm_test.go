@@ -1,7 +1,12 @@
 package engine
 
 import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/mule-ai/mule/internal/agent"
 	"github.com/mule-ai/mule/internal/primitive"
@@ -209,3 +214,43 @@ func TestWASMExecutorCacheInvalidation(t *testing.T) {
 	_, ok = executor.modules["test-module"]
 	assert.False(t, ok)
 }
+
+func TestWASMExecutorHTTPTimeout(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(100 * time.Millisecond)
+		_, _ = w.Write([]byte("slow response"))
+	}))
+	defer server.Close()
+
+	executor := NewWASMExecutor(nil, &MockPrimitiveStore{}, nil, nil)
+	mem := newFakeMemory(512)
+	module := &fakeModule{mem: mem}
+	ctx := context.Background()
+	methodPtr, methodSize := mem.put(0, "GET")
+	urlPtr, urlSize := mem.put(16, server.URL)
+
+	request := func(timeoutMs uint32) uint32 {
+		return executor.httpRequestWithHeaders(ctx, module, methodPtr, methodSize, urlPtr, urlSize, 0, 0, 0, 0, timeoutMs)
+	}
+
+	t.Run("module timeout fires", func(t *testing.T) {
+		assert.Equal(t, uint32(0xFFFFFFFC), request(20))
+	})
+
+	t.Run("completes under module timeout", func(t *testing.T) {
+		executor.SetHTTPTimeout(20 * time.Millisecond)
+		defer executor.SetHTTPTimeout(0)
+
+		assert.Equal(t, uint32(0), request(1000))
+		assert.Equal(t, []byte("slow response"), executor.lastResponseBody[fmt.Sprintf("%p", module)])
+	})
+
+	t.Run("zero uses executor default", func(t *testing.T) {
+		executor.SetHTTPTimeout(20 * time.Millisecond)
+		assert.Equal(t, uint32(0xFFFFFFFC), request(0))
+
+		executor.SetHTTPTimeout(0)
+		assert.Equal(t, defaultHTTPTimeout, executor.httpTimeout)
+		assert.Equal(t, uint32(0), request(0))
+	})
+}
@@ -0,0 +1,302 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/tetratelabs/wazero/api"
+)
+
+// errOutsideWorkingDir is returned for paths that would escape the working directory
+var errOutsideWorkingDir = errors.New("path is outside the working directory")
+
+// workingDirKey is the context key holding the working directory of the
+// executing module
+type workingDirKey struct{}
+
+// withWorkingDir records the execution's working directory in ctx, so host
+// functions of concurrent executions each see their own
+func withWorkingDir(ctx context.Context, workingDir string) context.Context {
+	return context.WithValue(ctx, workingDirKey{}, workingDir)
+}
+
+// workingDirFromContext returns the working directory of the executing
+// module, or "" if it has none
+func workingDirFromContext(ctx context.Context) string {
+	workingDir, _ := ctx.Value(workingDirKey{}).(string)
+	return workingDir
+}
+
+// checkRelativePath rejects absolute paths and paths with ".." components
+func checkRelativePath(path string) error {
+	if filepath.IsAbs(path) {
+		return errOutsideWorkingDir
+	}
+	for _, part := range strings.Split(filepath.ToSlash(path), "/") {
+		if part == ".." {
+			return errOutsideWorkingDir
+		}
+	}
+	return nil
+}
+
+// isWithinDir reports whether path is root or inside it. Both must already
+// have symlinks resolved.
+func isWithinDir(root, path string) bool {
+	rel, err := filepath.Rel(root, path)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// resolveWorkingDirPath resolves a module-supplied relative path against the
+// working directory. Absolute paths, ".." components and symlinks leading out
+// of the working directory are rejected.
+func resolveWorkingDirPath(workingDir, path string) (string, error) {
+	if err := checkRelativePath(path); err != nil {
+		return "", err
+	}
+
+	root, err := filepath.EvalSymlinks(workingDir)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve working directory: %w", err)
+	}
+
+	resolved, err := filepath.EvalSymlinks(filepath.Join(root, path))
+	if err != nil {
+		return "", err
+	}
+
+	if !isWithinDir(root, resolved) {
+		return "", errOutsideWorkingDir
+	}
+
+	return resolved, nil
+}
+
+// resolveWorkingDirWritePath is resolveWorkingDirPath for a file that may not
+// exist yet. Missing parent directories are created.
+func resolveWorkingDirWritePath(workingDir, path string) (string, error) {
+	if err := checkRelativePath(path); err != nil {
+		return "", err
+	}
+	clean := filepath.Clean(path)
+	if clean == "." {
+		return "", fmt.Errorf("path does not name a file")
+	}
+
+	root, err := filepath.EvalSymlinks(workingDir)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve working directory: %w", err)
+	}
+
+	full := filepath.Join(root, clean)
+
+	// Check the deepest existing ancestor before creating anything, so a
+	// symlinked directory cannot make us create directories elsewhere
+	existing := filepath.Dir(full)
+	for existing != root {
+		if _, err := os.Lstat(existing); err == nil {
+			break
+		}
+		existing = filepath.Dir(existing)
+	}
+	resolvedExisting, err := filepath.EvalSymlinks(existing)
+	if err != nil || !isWithinDir(root, resolvedExisting) {
+		return "", errOutsideWorkingDir
+	}
+
+	if err := os.MkdirAll(filepath.Dir(full), 0755); err != nil {
+		return "", fmt.Errorf("failed to create parent directories: %w", err)
+	}
+
+	parent, err := filepath.EvalSymlinks(filepath.Dir(full))
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve parent directory: %w", err)
+	}
+	if !isWithinDir(root, parent) {
+		return "", errOutsideWorkingDir
+	}
+
+	target := filepath.Join(parent, filepath.Base(full))
+
+	// Writing through an existing symlink must not leave the working directory
+	if info, err := os.Lstat(target); err == nil && info.Mode()&os.ModeSymlink != 0 {
+		resolved, err := filepath.EvalSymlinks(target)
+		if err != nil || !isWithinDir(root, resolved) {
+			return "", errOutsideWorkingDir
+		}
+	}
+
+	return target, nil
+}
+
+// readWorkingDirFile implements the read_working_dir_file host function. It
+// copies a file from the execution's working directory into the module's
+// buffer using the two-call pattern: a bufferSize of 0 returns the file size.
+func (e *WASMExecutor) readWorkingDirFile(ctx context.Context, module api.Module, pathPtr, pathSize, bufferPtr, bufferSize uint32) uint32 {
+	// Check for context cancellation before processing
+	select {
+	case <-ctx.Done():
+		// Return error code for cancellation
+		return 0xFFFFFFFA
+	default:
+	}
+
+	// Get memory from the module
+	mem := module.Memory()
+
+	// Read path from WASM memory
+	path, err := readStringFromMemory(ctx, mem, pathPtr, pathSize)
+	if err != nil || path == "" {
+		log.Printf("Failed to read file path from WASM memory: %v", err)
+		// Return error code (0xFFFFFFF0)
+		return 0xFFFFFFF0
+	}
+
+	workingDir := workingDirFromContext(ctx)
+	if workingDir == "" {
+		log.Printf("No working directory set for reading %s", path)
+		// Return error code (0xFFFFFFF5)
+		return 0xFFFFFFF5
+	}
+
+	fullPath, err := resolveWorkingDirPath(workingDir, path)
+	if errors.Is(err, errOutsideWorkingDir) {
+		log.Printf("Rejected file path outside working directory: %s", path)
+		// Return error code (0xFFFFFFF2)
+		return 0xFFFFFFF2
+	}
+	if errors.Is(err, fs.ErrNotExist) {
+		log.Printf("File not found in working directory: %s", path)
+		// Return error code (0xFFFFFFF1)
+		return 0xFFFFFFF1
+	}
+	if err != nil {
+		log.Printf("Failed to resolve file path %s: %v", path, err)
+		// Return error code (0xFFFFFFF6)
+		return 0xFFFFFFF6
+	}
+
+	data, err := os.ReadFile(fullPath)
+	if err != nil {
+		log.Printf("Failed to read file %s: %v", path, err)
+		// Return error code (0xFFFFFFF6)
+		return 0xFFFFFFF6
+	}
+
+	// If buffer size is 0, return the required size without writing data
+	if bufferSize == 0 {
+		return uint32(len(data))
+	}
+
+	// Check if buffer is large enough
+	if bufferSize < uint32(len(data)) {
+		log.Printf("Buffer too small for file %s: %d < %d", path, bufferSize, len(data))
+		// Return error code (0xFFFFFFF3)
+		return 0xFFFFFFF3
+	}
+
+	// Write file contents to WASM memory
+	if !mem.Write(bufferPtr, data) {
+		log.Printf("Failed to write file %s to WASM memory", path)
+		// Return error code (0xFFFFFFF4)
+		return 0xFFFFFFF4
+	}
+
+	// Return the size of the file
+	return uint32(len(data))
+}
+
+// writeWorkingDirFile implements the write_working_dir_file host function. It
+// writes the module's data to a file in the execution's working directory,
+// creating parent directories as needed, and records the path so it is
+// reported under files_written in the execution result.
+func (e *WASMExecutor) writeWorkingDirFile(ctx context.Context, module api.Module, pathPtr, pathSize, dataPtr, dataSize uint32) uint32 {
+	// Check for context cancellation before processing
+	select {
+	case <-ctx.Done():
+		// Return error code for cancellation
+		return 0xFFFFFFFA
+	default:
+	}
+
+	// Get memory from the module
+	mem := module.Memory()
+
+	// Read path from WASM memory
+	path, err := readStringFromMemory(ctx, mem, pathPtr, pathSize)
+	if err != nil || path == "" {
+		log.Printf("Failed to read file path from WASM memory: %v", err)
+		// Return error code (0xFFFFFFF0)
+		return 0xFFFFFFF0
+	}
+
+	// Read data from WASM memory (may be empty)
+	var data []byte
+	if dataSize > 0 {
+		var ok bool
+		data, ok = mem.Read(dataPtr, dataSize)
+		if !ok {
+			log.Printf("Failed to read file data from WASM memory")
+			// Return error code (0xFFFFFFF1)
+			return 0xFFFFFFF1
+		}
+	}
+
+	workingDir := workingDirFromContext(ctx)
+	if workingDir == "" {
+		log.Printf("No working directory set for writing %s", path)
+		// Return error code (0xFFFFFFF5)
+		return 0xFFFFFFF5
+	}
+
+	fullPath, err := resolveWorkingDirWritePath(workingDir, path)
+	if errors.Is(err, errOutsideWorkingDir) {
+		log.Printf("Rejected file path outside working directory: %s", path)
+		// Return error code (0xFFFFFFF2)
+		return 0xFFFFFFF2
+	}
+	if err != nil {
+		log.Printf("Failed to prepare file path %s: %v", path, err)
+		// Return error code (0xFFFFFFF6)
+		return 0xFFFFFFF6
+	}
+
+	if err := os.WriteFile(fullPath, data, 0644); err != nil {
+		log.Printf("Failed to write file %s: %v", path, err)
+		// Return error code (0xFFFFFFF6)
+		return 0xFFFFFFF6
+	}
+
+	e.recordFileWritten(moduleKey(module), filepath.Clean(path))
+	log.Printf("WASM module wrote %d bytes to %s", len(data), path)
+
+	// Return 0 for success
+	return 0
+}
+
+// recordFileWritten adds path to the files written by the module instance
+func (e *WASMExecutor) recordFileWritten(key, path string) {
+	e.filesMu.Lock()
+	defer e.filesMu.Unlock()
+	for _, existing := range e.filesWritten[key] {
+		if existing == path {
+			return
+		}
+	}
+	e.filesWritten[key] = append(e.filesWritten[key], path)
+}
+
+// takeFilesWritten returns and forgets the files written by the module instance
+func (e *WASMExecutor) takeFilesWritten(key string) []string {
+	e.filesMu.Lock()
+	defer e.filesMu.Unlock()
+	files := e.filesWritten[key]
+	delete(e.filesWritten, key)
+	return files
+}
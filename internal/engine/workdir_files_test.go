@@ -0,0 +1,123 @@
+package engine
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadWorkingDirFile(t *testing.T) {
+	workingDir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(workingDir, "docs"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(workingDir, "docs", "notes.txt"), []byte("hello from the repo"), 0644))
+
+	outside := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(outside, "secret.txt"), []byte("nope"), 0644))
+	require.NoError(t, os.Symlink(filepath.Join(outside, "secret.txt"), filepath.Join(workingDir, "escape.txt")))
+
+	executor := NewWASMExecutor(nil, &MockPrimitiveStore{}, nil, nil)
+
+	mem := newFakeMemory(512)
+	module := &fakeModule{mem: mem}
+	ctx := withWorkingDir(context.Background(), workingDir)
+
+	read := func(path string, bufferSize uint32) uint32 {
+		ptr, size := mem.put(0, path)
+		return executor.readWorkingDirFile(ctx, module, ptr, size, 256, bufferSize)
+	}
+
+	t.Run("reads file with two calls", func(t *testing.T) {
+		size := read("docs/notes.txt", 0)
+		require.Equal(t, uint32(len("hello from the repo")), size)
+
+		require.Equal(t, size, read("docs/notes.txt", size))
+		assert.Equal(t, "hello from the repo", string(mem.buf[256:256+size]))
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		assert.Equal(t, uint32(0xFFFFFFF1), read("docs/missing.txt", 0))
+	})
+
+	t.Run("rejects paths outside working dir", func(t *testing.T) {
+		assert.Equal(t, uint32(0xFFFFFFF2), read("../secret.txt", 0))
+		assert.Equal(t, uint32(0xFFFFFFF2), read("docs/../../secret.txt", 0))
+		assert.Equal(t, uint32(0xFFFFFFF2), read(filepath.Join(outside, "secret.txt"), 0))
+		assert.Equal(t, uint32(0xFFFFFFF2), read("escape.txt", 0))
+	})
+
+	t.Run("buffer too small", func(t *testing.T) {
+		assert.Equal(t, uint32(0xFFFFFFF3), read("docs/notes.txt", 4))
+	})
+
+	t.Run("reads from its own execution's working directory", func(t *testing.T) {
+		// Another execution starting meanwhile must not redirect the read
+		executor.workingDir = t.TempDir()
+		defer func() { executor.workingDir = "" }()
+		assert.Equal(t, uint32(len("hello from the repo")), read("docs/notes.txt", 0))
+	})
+
+	t.Run("no working directory", func(t *testing.T) {
+		ptr, size := mem.put(0, "docs/notes.txt")
+		assert.Equal(t, uint32(0xFFFFFFF5), executor.readWorkingDirFile(context.Background(), module, ptr, size, 256, 0))
+	})
+}
+
+func TestWriteWorkingDirFile(t *testing.T) {
+	workingDir := t.TempDir()
+	outside := t.TempDir()
+	require.NoError(t, os.Symlink(outside, filepath.Join(workingDir, "elsewhere")))
+
+	executor := NewWASMExecutor(nil, &MockPrimitiveStore{}, nil, nil)
+
+	mem := newFakeMemory(512)
+	module := &fakeModule{mem: mem}
+	ctx := withWorkingDir(context.Background(), workingDir)
+
+	write := func(path, data string) uint32 {
+		pathPtr, pathSize := mem.put(0, path)
+		dataPtr, dataSize := mem.put(256, data)
+		return executor.writeWorkingDirFile(ctx, module, pathPtr, pathSize, dataPtr, dataSize)
+	}
+
+	t.Run("writes nested file", func(t *testing.T) {
+		require.Equal(t, uint32(0), write("reports/2026/summary.md", "# Summary"))
+
+		path := filepath.Join(workingDir, "reports", "2026", "summary.md")
+		data, err := os.ReadFile(path)
+		require.NoError(t, err)
+		assert.Equal(t, "# Summary", string(data))
+
+		info, err := os.Stat(path)
+		require.NoError(t, err)
+		assert.Equal(t, os.FileMode(0644), info.Mode().Perm())
+	})
+
+	t.Run("rejects traversal", func(t *testing.T) {
+		assert.Equal(t, uint32(0xFFFFFFF2), write("../escaped.txt", "nope"))
+		assert.Equal(t, uint32(0xFFFFFFF2), write(filepath.Join(outside, "escaped.txt"), "nope"))
+		assert.Equal(t, uint32(0xFFFFFFF2), write("elsewhere/new/escaped.txt", "nope"))
+
+		entries, err := os.ReadDir(outside)
+		require.NoError(t, err)
+		assert.Empty(t, entries, "nothing may be created outside the working directory")
+	})
+
+	t.Run("no working directory", func(t *testing.T) {
+		pathPtr, pathSize := mem.put(0, "orphan.txt")
+		dataPtr, dataSize := mem.put(256, "nope")
+		assert.Equal(t, uint32(0xFFFFFFF5), executor.writeWorkingDirFile(context.Background(), module, pathPtr, pathSize, dataPtr, dataSize))
+	})
+
+	t.Run("reports files written", func(t *testing.T) {
+		require.Equal(t, uint32(0), write("patch.diff", "diff"))
+		require.Equal(t, uint32(0), write("patch.diff", "diff again"))
+
+		files := executor.takeFilesWritten(moduleKey(module))
+		assert.Equal(t, []string{filepath.Join("reports", "2026", "summary.md"), "patch.diff"}, files)
+		assert.Empty(t, executor.takeFilesWritten(moduleKey(module)))
+	})
+}
@@ -52,12 +52,13 @@ type Skill struct {
 
 // Workflow represents an ordered sequence of steps.
 type Workflow struct {
-	ID          string    `json:"id"`
-	Name        string    `json:"name"`
-	Description string    `json:"description"`
-	IsAsync     bool      `json:"is_async"`
-	CreatedAt   time.Time `json:"created_at"`
-	UpdatedAt   time.Time `json:"updated_at"`
+	ID          string                 `json:"id"`
+	Name        string                 `json:"name"`
+	Description string                 `json:"description"`
+	IsAsync     bool                   `json:"is_async"`
+	Config      map[string]interface{} `json:"config,omitempty"` // Workflow-level execution options
+	CreatedAt   time.Time              `json:"created_at"`
+	UpdatedAt   time.Time              `json:"updated_at"`
 }
 
 // MemoryConfig represents configuration for the genai memory tool.
@@ -304,23 +304,34 @@ func (s *PGStore) CreateWorkflow(ctx context.Context, w *Workflow) error {
 	if w.ID == "" {
 		w.ID = uuid.New().String()
 	}
-	query := `INSERT INTO workflows (id, name, description, is_async, created_at, updated_at) VALUES ($1, $2, $3, $4, NOW(), NOW())`
-	_, err := s.db.ExecContext(ctx, query, w.ID, w.Name, w.Description, w.IsAsync)
+	configJSON, err := marshalWorkflowConfig(w.Config)
+	if err != nil {
+		return err
+	}
+	query := `INSERT INTO workflows (id, name, description, is_async, config, created_at, updated_at) VALUES ($1, $2, $3, $4, $5, NOW(), NOW())`
+	_, err = s.db.ExecContext(ctx, query, w.ID, w.Name, w.Description, w.IsAsync, configJSON)
 	return err
 }
 
 func (s *PGStore) GetWorkflow(ctx context.Context, id string) (*Workflow, error) {
 	w := &Workflow{}
-	query := `SELECT id, name, description, is_async, created_at, updated_at FROM workflows WHERE id = $1`
-	err := s.db.QueryRowContext(ctx, query, id).Scan(&w.ID, &w.Name, &w.Description, &w.IsAsync, &w.CreatedAt, &w.UpdatedAt)
+	var configJSON []byte
+	query := `SELECT id, name, description, is_async, config, created_at, updated_at FROM workflows WHERE id = $1`
+	err := s.db.QueryRowContext(ctx, query, id).Scan(&w.ID, &w.Name, &w.Description, &w.IsAsync, &configJSON, &w.CreatedAt, &w.UpdatedAt)
 	if errors.Is(err, sql.ErrNoRows) {
 		return nil, ErrNotFound
 	}
-	return w, err
+	if err != nil {
+		return nil, err
+	}
+	if err = unmarshalWorkflowConfig(configJSON, w); err != nil {
+		return nil, err
+	}
+	return w, nil
 }
 
 func (s *PGStore) ListWorkflows(ctx context.Context) ([]*Workflow, error) {
-	query := `SELECT id, name, description, is_async, created_at, updated_at FROM workflows ORDER BY name`
+	query := `SELECT id, name, description, is_async, config, created_at, updated_at FROM workflows ORDER BY name`
 	rows, err := s.db.QueryContext(ctx, query)
 	if err != nil {
 		return nil, err
@@ -330,18 +341,26 @@ func (s *PGStore) ListWorkflows(ctx context.Context) ([]*Workflow, error) {
 	var workflows []*Workflow
 	for rows.Next() {
 		w := &Workflow{}
-		err := rows.Scan(&w.ID, &w.Name, &w.Description, &w.IsAsync, &w.CreatedAt, &w.UpdatedAt)
+		var configJSON []byte
+		err := rows.Scan(&w.ID, &w.Name, &w.Description, &w.IsAsync, &configJSON, &w.CreatedAt, &w.UpdatedAt)
 		if err != nil {
 			return nil, err
 		}
+		if err = unmarshalWorkflowConfig(configJSON, w); err != nil {
+			return nil, err
+		}
 		workflows = append(workflows, w)
 	}
 	return workflows, rows.Err()
 }
 
 func (s *PGStore) UpdateWorkflow(ctx context.Context, w *Workflow) error {
-	query := `UPDATE workflows SET name = $1, description = $2, is_async = $3, updated_at = NOW() WHERE id = $4`
-	res, err := s.db.ExecContext(ctx, query, w.Name, w.Description, w.IsAsync, w.ID)
+	configJSON, err := marshalWorkflowConfig(w.Config)
+	if err != nil {
+		return err
+	}
+	query := `UPDATE workflows SET name = $1, description = $2, is_async = $3, config = $4, updated_at = NOW() WHERE id = $5`
+	res, err := s.db.ExecContext(ctx, query, w.Name, w.Description, w.IsAsync, configJSON, w.ID)
 	if err != nil {
 		return err
 	}
@@ -355,6 +374,30 @@ func (s *PGStore) UpdateWorkflow(ctx context.Context, w *Workflow) error {
 	return nil
 }
 
+// marshalWorkflowConfig encodes a workflow config for the JSONB column, storing
+// an empty object when no config is set
+func marshalWorkflowConfig(config map[string]interface{}) ([]byte, error) {
+	if config == nil {
+		return []byte("{}"), nil
+	}
+	configJSON, err := json.Marshal(config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal workflow config: %w", err)
+	}
+	return configJSON, nil
+}
+
+// unmarshalWorkflowConfig decodes the JSONB config column into the workflow
+func unmarshalWorkflowConfig(configJSON []byte, w *Workflow) error {
+	if len(configJSON) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(configJSON, &w.Config); err != nil {
+		return fmt.Errorf("failed to unmarshal workflow config: %w", err)
+	}
+	return nil
+}
+
 func (s *PGStore) DeleteWorkflow(ctx context.Context, id string) error {
 	query := `DELETE FROM workflows WHERE id = $1`
 	res, err := s.db.ExecContext(ctx, query, id)
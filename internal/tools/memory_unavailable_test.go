@@ -0,0 +1,60 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mule-ai/mule/internal/primitive"
+)
+
+// unavailableConfigStore fails every lookup as if the database were down
+type unavailableConfigStore struct{}
+
+func (s *unavailableConfigStore) GetMemoryConfig(ctx context.Context, id string) (*primitive.MemoryConfig, error) {
+	return nil, errors.New("dial tcp 127.0.0.1:5432: connect: connection refused")
+}
+
+func (s *unavailableConfigStore) GetProvider(ctx context.Context, id string) (*primitive.Provider, error) {
+	return nil, primitive.ErrNotFound
+}
+
+func (s *unavailableConfigStore) ListProviders(ctx context.Context) ([]*primitive.Provider, error) {
+	return nil, nil
+}
+
+func TestRegistryDegradesWhenMemoryUnavailable(t *testing.T) {
+	registry, err := NewRegistryWithConfig(&unavailableConfigStore{})
+	require.NoError(t, err, "an unavailable memory store must not fail the registry")
+
+	health := registry.MemoryHealth()
+	require.Error(t, health)
+	assert.Contains(t, health.Error(), "connection refused")
+
+	// Other built-in tools are still registered
+	_, err = registry.Get("bash")
+	assert.NoError(t, err)
+
+	memory, err := registry.Get("memory")
+	require.NoError(t, err)
+
+	t.Run("retrieve returns no results", func(t *testing.T) {
+		result, err := memory.Execute(context.Background(), map[string]interface{}{"operation": "retrieve", "query": "anything"})
+		require.NoError(t, err)
+		assert.Equal(t, 0, result.(map[string]interface{})["count"])
+	})
+
+	t.Run("store is skipped", func(t *testing.T) {
+		result, err := memory.Execute(context.Background(), map[string]interface{}{"operation": "store", "content": "remember this"})
+		require.NoError(t, err)
+		assert.Equal(t, false, result.(map[string]interface{})["success"])
+	})
+
+	t.Run("unknown operation still errors", func(t *testing.T) {
+		_, err := memory.Execute(context.Background(), map[string]interface{}{"operation": "explode"})
+		assert.Error(t, err)
+	})
+}
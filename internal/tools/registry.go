@@ -3,6 +3,7 @@ package tools
 import (
 	"context"
 	"fmt"
+	"log"
 	"strings"
 	"sync"
 	"time"
@@ -22,9 +23,10 @@ type ToolConfigStore interface {
 
 // Registry manages built-in tools and provides them to agents
 type Registry struct {
-	tools map[string]Tool
-	mu    sync.RWMutex
-	store ToolConfigStore
+	tools     map[string]Tool
+	mu        sync.RWMutex
+	store     ToolConfigStore
+	memoryErr error
 }
 
 // Tool defines the interface for built-in tools
@@ -59,9 +61,10 @@ func NewRegistryWithConfig(store ToolConfigStore) (*Registry, error) {
 		store: store,
 	}
 
-	// Initialize memory tool with configuration
+	// Initialize memory tool with configuration. An unavailable memory store
+	// degrades the memory tool rather than failing the whole registry.
 	if err := registry.initializeMemoryTool(); err != nil {
-		return nil, fmt.Errorf("failed to initialize memory tool: %w", err)
+		log.Printf("Warning: memory store unavailable, memory operations will be skipped: %v", err)
 	}
 
 	// Register other built-in tools
@@ -73,8 +76,32 @@ func NewRegistryWithConfig(store ToolConfigStore) (*Registry, error) {
 	return registry, nil
 }
 
-// initializeMemoryTool initializes the genai memory tool with configuration from the store
+// initializeMemoryTool initializes the memory tool and records whether the
+// memory store is available. On failure the unavailable memory tool is
+// registered in its place.
 func (r *Registry) initializeMemoryTool() error {
+	err := r.createMemoryTool()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.memoryErr = err
+	if err != nil {
+		r.tools["memory"] = &unavailableMemoryTool{reason: err}
+	}
+
+	return err
+}
+
+// MemoryHealth returns nil if the memory store is available, or the error
+// that made it unavailable
+func (r *Registry) MemoryHealth() error {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.memoryErr
+}
+
+// createMemoryTool creates the genai memory tool with configuration from the store
+func (r *Registry) createMemoryTool() error {
 	ctx := context.Background()
 
 	// Get memory configuration from store
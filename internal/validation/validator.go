@@ -165,11 +165,11 @@ func (v *Validator) ValidateWorkflowStep(step *primitive.WorkflowStep) Validatio
 			Message: "Step type is required",
 		})
 	} else {
-		validTypes := []string{"agent", "wasm_module"}
+		validTypes := []string{"agent", "wasm_module", "subworkflow", "broadcast"}
 		if !isValidEnum(step.StepType, validTypes) {
 			errors = append(errors, ValidationError{
 				Field:   "type",
-				Message: "Step type must be either agent or wasm_module",
+				Message: "Step type must be one of agent, wasm_module, subworkflow or broadcast",
 			})
 		}
 	}
@@ -188,6 +188,24 @@ func (v *Validator) ValidateWorkflowStep(step *primitive.WorkflowStep) Validatio
 		})
 	}
 
+	if step.StepType == "subworkflow" {
+		if ref, _ := step.Config["workflow"].(string); strings.TrimSpace(ref) == "" {
+			errors = append(errors, ValidationError{
+				Field:   "config.workflow",
+				Message: "Workflow name or ID is required for subworkflow steps",
+			})
+		}
+	}
+
+	if step.StepType == "broadcast" {
+		if destinations, _ := step.Config["destinations"].([]interface{}); len(destinations) == 0 {
+			errors = append(errors, ValidationError{
+				Field:   "config.destinations",
+				Message: "At least one destination is required for broadcast steps",
+			})
+		}
+	}
+
 	return errors
 }
 
@@ -244,6 +244,52 @@ func TestValidateWorkflowStep(t *testing.T) {
 			},
 			expectErrors: 0,
 		},
+		{
+			name: "valid subworkflow step",
+			step: &primitive.WorkflowStep{
+				ID:         "step3",
+				WorkflowID: "workflow1",
+				StepOrder:  3,
+				StepType:   "subworkflow",
+				Config:     map[string]interface{}{"workflow": "summarize"},
+			},
+			expectErrors: 0,
+		},
+		{
+			name: "subworkflow step without workflow",
+			step: &primitive.WorkflowStep{
+				ID:         "step3",
+				WorkflowID: "workflow1",
+				StepOrder:  3,
+				StepType:   "subworkflow",
+				Config:     map[string]interface{}{},
+			},
+			expectErrors: 1,
+		},
+		{
+			name: "valid broadcast step",
+			step: &primitive.WorkflowStep{
+				ID:         "step4",
+				WorkflowID: "workflow1",
+				StepOrder:  4,
+				StepType:   "broadcast",
+				Config: map[string]interface{}{"destinations": []interface{}{
+					map[string]interface{}{"name": "notify", "type": "webhook", "url": "https://example.com/hook"},
+				}},
+			},
+			expectErrors: 0,
+		},
+		{
+			name: "broadcast step without destinations",
+			step: &primitive.WorkflowStep{
+				ID:         "step4",
+				WorkflowID: "workflow1",
+				StepOrder:  4,
+				StepType:   "broadcast",
+				Config:     map[string]interface{}{},
+			},
+			expectErrors: 1,
+		},
 		{
 			name: "missing ID",
 			step: &primitive.WorkflowStep{
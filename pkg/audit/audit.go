@@ -0,0 +1,51 @@
+package audit
+
+import (
+	"time"
+)
+
+// Outcome values recorded for an audit entry
+const (
+	OutcomeSuccess = "success"
+	OutcomeFailure = "failure"
+)
+
+// Entry represents a single audited mutating operation. Actor is the client
+// address the request came from; ClaimedActor is the identity the client sent
+// in the X-Actor header, which is not verified.
+type Entry struct {
+	ID           string    `json:"id" db:"id"`
+	Actor        string    `json:"actor" db:"actor"`
+	ClaimedActor string    `json:"claimed_actor,omitempty" db:"claimed_actor"`
+	Action       string    `json:"action" db:"action"`
+	Target       string    `json:"target" db:"target"`
+	StatusCode   int       `json:"status_code" db:"status_code"`
+	Outcome      string    `json:"outcome" db:"outcome"`
+	CreatedAt    time.Time `json:"created_at" db:"created_at"`
+}
+
+// ListOptions contains options for querying the audit log
+type ListOptions struct {
+	Page     int
+	PageSize int
+	Actor    string
+	Action   string
+	Target   string
+	Outcome  string
+	Since    *time.Time
+	Until    *time.Time
+}
+
+// Store defines interface for audit log persistence
+type Store interface {
+	Record(entry *Entry) error
+	List(opts ListOptions) ([]*Entry, int, error)
+}
+
+// OutcomeForStatus maps an HTTP status code to an audit outcome
+func OutcomeForStatus(statusCode int) string {
+	if statusCode >= 400 {
+		return OutcomeFailure
+	}
+	return OutcomeSuccess
+}
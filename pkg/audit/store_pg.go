@@ -0,0 +1,109 @@
+package audit
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+
+	"github.com/mule-ai/mule/internal/database"
+
+	_ "github.com/lib/pq"
+)
+
+// PGStore implements Store backed by PostgreSQL
+type PGStore struct {
+	db *sql.DB
+}
+
+// NewPGStore creates a new PGStore instance
+func NewPGStore(db *sql.DB) *PGStore {
+	return &PGStore{db: db}
+}
+
+// Record inserts an audit entry, assigning an ID if one is not set
+func (s *PGStore) Record(entry *Entry) error {
+	if entry.ID == "" {
+		entry.ID = uuid.New().String()
+	}
+
+	query := `INSERT INTO audit_log (id, actor, claimed_actor, action, target, status_code, outcome, created_at)
+			  VALUES ($1, $2, $3, $4, $5, $6, $7, NOW())
+			  RETURNING created_at`
+
+	return s.db.QueryRow(query, entry.ID, entry.Actor, entry.ClaimedActor, entry.Action, entry.Target, entry.StatusCode, entry.Outcome).
+		Scan(&entry.CreatedAt)
+}
+
+// List returns audit entries matching the options, newest first, along with
+// the total number of matching entries
+func (s *PGStore) List(opts ListOptions) ([]*Entry, int, error) {
+	if opts.Page <= 0 {
+		opts.Page = 1
+	}
+	if opts.PageSize <= 0 {
+		opts.PageSize = 20
+	}
+
+	var conditions []string
+	var args []interface{}
+
+	addCondition := func(format string, value interface{}) {
+		args = append(args, value)
+		conditions = append(conditions, fmt.Sprintf(format, len(args)))
+	}
+
+	if opts.Actor != "" {
+		addCondition("actor = $%d", opts.Actor)
+	}
+	if opts.Action != "" {
+		addCondition("action ILIKE $%d", "%"+opts.Action+"%")
+	}
+	if opts.Target != "" {
+		addCondition("target ILIKE $%d", "%"+opts.Target+"%")
+	}
+	if opts.Outcome != "" {
+		addCondition("outcome = $%d", opts.Outcome)
+	}
+	if opts.Since != nil {
+		addCondition("created_at >= $%d", *opts.Since)
+	}
+	if opts.Until != nil {
+		addCondition("created_at <= $%d", *opts.Until)
+	}
+
+	whereClause := ""
+	if len(conditions) > 0 {
+		whereClause = " WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	var totalCount int
+	countQuery := `SELECT COUNT(*) FROM audit_log` + whereClause
+	if err := s.db.QueryRow(countQuery, args...).Scan(&totalCount); err != nil {
+		return nil, 0, err
+	}
+
+	query := fmt.Sprintf(`SELECT id, actor, claimed_actor, action, target, status_code, outcome, created_at
+			  FROM audit_log%s ORDER BY created_at DESC LIMIT $%d OFFSET $%d`,
+		whereClause, len(args)+1, len(args)+2)
+	args = append(args, opts.PageSize, (opts.Page-1)*opts.PageSize)
+
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer database.CloseRows(rows)
+
+	var entries []*Entry
+	for rows.Next() {
+		entry := &Entry{}
+		if err := rows.Scan(&entry.ID, &entry.Actor, &entry.ClaimedActor, &entry.Action, &entry.Target,
+			&entry.StatusCode, &entry.Outcome, &entry.CreatedAt); err != nil {
+			return nil, 0, err
+		}
+		entries = append(entries, entry)
+	}
+
+	return entries, totalCount, rows.Err()
+}
@@ -2,6 +2,8 @@ package job
 
 import (
 	"errors"
+	"fmt"
+	"strings"
 	"time"
 )
 
@@ -20,6 +22,8 @@ const (
 	StatusCompleted Status = "completed"
 	StatusFailed    Status = "failed"
 	StatusCancelled Status = "cancelled"
+	// StatusSkipped is only used for job steps whose condition was not met
+	StatusSkipped Status = "skipped"
 )
 
 // String returns string representation of status
@@ -27,6 +31,21 @@ func (s Status) String() string {
 	return string(s)
 }
 
+// IsTerminal reports whether a job in this status has finished
+func (s Status) IsTerminal() bool {
+	return s == StatusCompleted || s == StatusFailed || s == StatusCancelled
+}
+
+// ParseStatus returns the job status named by s, matched case-insensitively
+func ParseStatus(s string) (Status, error) {
+	switch status := Status(strings.ToLower(strings.TrimSpace(s))); status {
+	case StatusQueued, StatusRunning, StatusCompleted, StatusFailed, StatusCancelled:
+		return status, nil
+	default:
+		return "", fmt.Errorf("unknown job status %q", s)
+	}
+}
+
 // CanTransitionTo checks if status can transition to target status
 func (s Status) CanTransitionTo(target Status) bool {
 	switch s {
@@ -50,6 +69,7 @@ type Job struct {
 	InputData        map[string]interface{} `json:"input_data" db:"input_data"`
 	OutputData       map[string]interface{} `json:"output_data" db:"output_data"`
 	WorkingDirectory string                 `json:"working_directory" db:"working_directory"`
+	Tags             map[string]string      `json:"tags,omitempty" db:"tags"`
 	CreatedAt        time.Time              `json:"created_at" db:"created_at"`
 	StartedAt        *time.Time             `json:"started_at,omitempty" db:"started_at"`
 	CompletedAt      *time.Time             `json:"completed_at,omitempty" db:"completed_at"`
@@ -76,6 +96,8 @@ type ListJobsOptions struct {
 	Status       *Status
 	Search       string
 	WorkflowName string
+	// Tags restricts results to jobs carrying all of the given tags
+	Tags map[string]string
 }
 
 // JobStore defines interface for job persistence
@@ -1,6 +1,7 @@
 package job
 
 import (
+	"encoding/json"
 	"testing"
 	"time"
 
@@ -184,6 +185,33 @@ func TestJobStatus(t *testing.T) {
 	}
 }
 
+func TestParseStatus(t *testing.T) {
+	status, err := ParseStatus("Completed")
+	require.NoError(t, err)
+	assert.Equal(t, StatusCompleted, status)
+	assert.True(t, status.IsTerminal())
+
+	status, err = ParseStatus("queued")
+	require.NoError(t, err)
+	assert.False(t, status.IsTerminal())
+
+	_, err = ParseStatus("done")
+	assert.Error(t, err)
+}
+
+func TestJobOutputJSON(t *testing.T) {
+	data, err := json.Marshal(JobOutput{
+		Status: StatusCompleted,
+		Output: map[string]interface{}{"prompt": "done"},
+	})
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"status":"completed","output":{"prompt":"done"}}`, string(data))
+
+	var decoded JobOutput
+	require.NoError(t, json.Unmarshal(data, &decoded))
+	assert.Equal(t, StatusCompleted, decoded.Status)
+}
+
 func TestJob(t *testing.T) {
 	job := &Job{
 		ID:          "test-job",
@@ -13,3 +13,10 @@ type EnhancedJobStep struct {
 	AgentName      string `json:"agent_name,omitempty"`
 	WasmModuleName string `json:"wasm_module_name,omitempty"`
 }
+
+// JobOutput is the job status and output returned to WASM modules by the
+// get_job_output and wait_for_job_and_get_output host functions
+type JobOutput struct {
+	Status Status                 `json:"status"`
+	Output map[string]interface{} `json:"output"`
+}
@@ -40,25 +40,34 @@ func (s *PGStore) CreateJob(job *Job) error {
 		workflowID = nil
 	}
 
-	query := `INSERT INTO jobs (id, workflow_id, wasm_module_id, status, input_data, output_data, working_directory, created_at)
-			  VALUES ($1, $2, $3, $4, $5, $6, $7, NOW())`
+	tags := job.Tags
+	if tags == nil {
+		tags = map[string]string{}
+	}
+	tagsJSON, err := json.Marshal(tags)
+	if err != nil {
+		return fmt.Errorf("failed to marshal tags: %w", err)
+	}
+
+	query := `INSERT INTO jobs (id, workflow_id, wasm_module_id, status, input_data, output_data, working_directory, tags, created_at)
+			  VALUES ($1, $2, $3, $4, $5, $6, $7, $8, NOW())`
 
-	_, err = s.db.Exec(query, job.ID, workflowID, job.WasmModuleID, job.Status, inputDataJSON, outputDataJSON, job.WorkingDirectory)
+	_, err = s.db.Exec(query, job.ID, workflowID, job.WasmModuleID, job.Status, inputDataJSON, outputDataJSON, job.WorkingDirectory, tagsJSON)
 	return err
 }
 
 // GetJob retrieves a job by ID
 func (s *PGStore) GetJob(id string) (*Job, error) {
 	job := &Job{}
-	var inputDataJSON, outputDataJSON []byte
+	var inputDataJSON, outputDataJSON, tagsJSON []byte
 	var workflowID sql.NullString
 	var workingDirectory sql.NullString
 
-	query := `SELECT id, workflow_id, wasm_module_id, status, input_data, output_data, working_directory, created_at, started_at, completed_at
+	query := `SELECT id, workflow_id, wasm_module_id, status, input_data, output_data, working_directory, tags, created_at, started_at, completed_at
 			  FROM jobs WHERE id = $1`
 
 	err := s.db.QueryRow(query, id).Scan(
-		&job.ID, &workflowID, &job.WasmModuleID, &job.Status, &inputDataJSON, &outputDataJSON, &workingDirectory,
+		&job.ID, &workflowID, &job.WasmModuleID, &job.Status, &inputDataJSON, &outputDataJSON, &workingDirectory, &tagsJSON,
 		&job.CreatedAt, &job.StartedAt, &job.CompletedAt)
 
 	// Convert NULL workflow_id to empty string
@@ -90,9 +99,24 @@ func (s *PGStore) GetJob(id string) (*Job, error) {
 		return nil, fmt.Errorf("failed to unmarshal output data: %w", err)
 	}
 
+	if err = unmarshalTags(tagsJSON, job); err != nil {
+		return nil, err
+	}
+
 	return job, nil
 }
 
+// unmarshalTags decodes the tags column into the job, tolerating NULL
+func unmarshalTags(data []byte, job *Job) error {
+	if len(data) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(data, &job.Tags); err != nil {
+		return fmt.Errorf("failed to unmarshal tags: %w", err)
+	}
+	return nil
+}
+
 // ListJobs retrieves jobs with pagination and filtering support
 func (s *PGStore) ListJobs(opts ListJobsOptions) ([]*Job, int, error) {
 	// Set default values if not provided
@@ -104,7 +128,7 @@ func (s *PGStore) ListJobs(opts ListJobsOptions) ([]*Job, int, error) {
 	}
 
 	// Base query
-	baseQuery := `SELECT j.id, j.workflow_id, j.wasm_module_id, j.status, j.input_data, j.output_data, j.working_directory, j.created_at, j.started_at, j.completed_at
+	baseQuery := `SELECT j.id, j.workflow_id, j.wasm_module_id, j.status, j.input_data, j.output_data, j.working_directory, j.tags, j.created_at, j.started_at, j.completed_at
 				  FROM jobs j`
 	countQuery := `SELECT COUNT(*) FROM jobs j`
 
@@ -154,6 +178,22 @@ func (s *PGStore) ListJobs(opts ListJobsOptions) ([]*Job, int, error) {
 		argIndex++
 	}
 
+	// Tag filter (jobs must carry every requested tag)
+	if len(opts.Tags) > 0 {
+		tagsJSON, err := json.Marshal(opts.Tags)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to marshal tag filter: %w", err)
+		}
+		if whereClause == "" {
+			whereClause = " WHERE"
+		} else {
+			whereClause += " AND"
+		}
+		whereClause += fmt.Sprintf(" j.tags @> $%d::jsonb", argIndex)
+		args = append(args, string(tagsJSON))
+		argIndex++
+	}
+
 	// Complete queries
 	query := baseQuery + whereClause + " ORDER BY j.created_at DESC LIMIT $%d OFFSET $%d"
 	query = fmt.Sprintf(query, argIndex, argIndex+1)
@@ -181,11 +221,11 @@ func (s *PGStore) ListJobs(opts ListJobsOptions) ([]*Job, int, error) {
 	var jobs []*Job
 	for rows.Next() {
 		job := &Job{}
-		var inputDataJSON, outputDataJSON []byte
+		var inputDataJSON, outputDataJSON, tagsJSON []byte
 		var workflowID sql.NullString
 		var workingDirectory sql.NullString
 
-		err := rows.Scan(&job.ID, &workflowID, &job.WasmModuleID, &job.Status, &inputDataJSON, &outputDataJSON, &workingDirectory,
+		err := rows.Scan(&job.ID, &workflowID, &job.WasmModuleID, &job.Status, &inputDataJSON, &outputDataJSON, &workingDirectory, &tagsJSON,
 			&job.CreatedAt, &job.StartedAt, &job.CompletedAt)
 
 		// Convert NULL workflow_id to empty string
@@ -214,6 +254,10 @@ func (s *PGStore) ListJobs(opts ListJobsOptions) ([]*Job, int, error) {
 			return nil, 0, fmt.Errorf("failed to unmarshal output data: %w", err)
 		}
 
+		if err = unmarshalTags(tagsJSON, job); err != nil {
+			return nil, 0, err
+		}
+
 		jobs = append(jobs, job)
 	}
 
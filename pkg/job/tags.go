@@ -0,0 +1,54 @@
+package job
+
+import (
+	"context"
+)
+
+// Well-known job tag keys
+const (
+	TagWorkflow   = "workflow"
+	TagRepository = "repository"
+	TagTrigger    = "trigger"
+)
+
+// Trigger sources recorded in the trigger tag
+const (
+	TriggerWebhook  = "webhook"
+	TriggerSchedule = "schedule"
+	TriggerManual   = "manual"
+	TriggerAPI      = "api"
+)
+
+// IsValidTrigger reports whether source is a known trigger source
+func IsValidTrigger(source string) bool {
+	switch source {
+	case TriggerWebhook, TriggerSchedule, TriggerManual, TriggerAPI:
+		return true
+	default:
+		return false
+	}
+}
+
+// MatchesTags reports whether the job has every tag in filter
+func (j *Job) MatchesTags(filter map[string]string) bool {
+	for key, value := range filter {
+		if j.Tags[key] != value {
+			return false
+		}
+	}
+	return true
+}
+
+type triggerKey struct{}
+
+// WithTrigger returns a context recording the source that triggered the jobs
+// submitted with it
+func WithTrigger(ctx context.Context, source string) context.Context {
+	return context.WithValue(ctx, triggerKey{}, source)
+}
+
+// TriggerFromContext returns the trigger source recorded by WithTrigger, or ""
+func TriggerFromContext(ctx context.Context) string {
+	source, _ := ctx.Value(triggerKey{}).(string)
+	return source
+}
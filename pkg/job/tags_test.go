@@ -0,0 +1,59 @@
+package job
+
+import (
+	"context"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJobMatchesTags(t *testing.T) {
+	j := &Job{Tags: map[string]string{TagRepository: "org/a", TagTrigger: TriggerWebhook}}
+
+	assert.True(t, j.MatchesTags(nil))
+	assert.True(t, j.MatchesTags(map[string]string{TagTrigger: TriggerWebhook}))
+	assert.True(t, j.MatchesTags(map[string]string{TagRepository: "org/a", TagTrigger: TriggerWebhook}))
+	assert.False(t, j.MatchesTags(map[string]string{TagTrigger: TriggerSchedule}))
+	assert.False(t, j.MatchesTags(map[string]string{TagWorkflow: "review"}))
+	assert.False(t, (&Job{}).MatchesTags(map[string]string{TagTrigger: TriggerAPI}))
+}
+
+func TestTriggerContext(t *testing.T) {
+	assert.Equal(t, "", TriggerFromContext(context.Background()))
+	assert.Equal(t, TriggerSchedule, TriggerFromContext(WithTrigger(context.Background(), TriggerSchedule)))
+
+	assert.True(t, IsValidTrigger(TriggerManual))
+	assert.False(t, IsValidTrigger("email"))
+}
+
+func TestPGStoreListJobsFiltersByTags(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer func() { _ = db.Close() }()
+
+	store := NewPGStore(db)
+	status := StatusFailed
+	tags := map[string]string{TagRepository: "org/a", TagTrigger: TriggerWebhook}
+
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT COUNT(*) FROM jobs j WHERE j.status = $1 AND j.tags @> $2::jsonb`)).
+		WithArgs("failed", `{"repository":"org/a","trigger":"webhook"}`).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+
+	columns := []string{"id", "workflow_id", "wasm_module_id", "status", "input_data", "output_data", "working_directory", "tags", "created_at", "started_at", "completed_at"}
+	mock.ExpectQuery(regexp.QuoteMeta(`WHERE j.status = $1 AND j.tags @> $2::jsonb ORDER BY j.created_at DESC LIMIT $3 OFFSET $4`)).
+		WithArgs("failed", `{"repository":"org/a","trigger":"webhook"}`, 20, 0).
+		WillReturnRows(sqlmock.NewRows(columns).AddRow(
+			"job-1", "workflow-1", nil, "failed", []byte(`{}`), []byte(`{}`), "",
+			[]byte(`{"repository":"org/a","trigger":"webhook","workflow":"review"}`), time.Now(), nil, nil))
+
+	jobs, total, err := store.ListJobs(ListJobsOptions{Status: &status, Tags: tags})
+	require.NoError(t, err)
+	assert.Equal(t, 1, total)
+	require.Len(t, jobs, 1)
+	assert.Equal(t, "review", jobs[0].Tags[TagWorkflow])
+	assert.NoError(t, mock.ExpectationsWereMet())
+}